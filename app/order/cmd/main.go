@@ -9,6 +9,8 @@ import (
 	"orderfc/cmd/order/service"
 	"orderfc/cmd/order/usecase"
 	"orderfc/config"
+	"orderfc/grpc"
+	"orderfc/grpc/client"
 	"orderfc/infrastructure/log"
 	"orderfc/kafka"
 	"orderfc/kafka/consumer"
@@ -28,15 +30,28 @@ func main() {
 	kafkaProducer := kafka.NewKafkaProducer([]string{"localhost:9093"})
 	defer kafkaProducer.Close()
 
+	// grpc client of the product service, e.g. flash sale stock at checkout
+	productClient, err := client.NewProductClient("localhost:50053")
+	if err != nil {
+		log.Logger.Fatalf("Failed init grpc product client: %v", err)
+	}
+
+	// grpc client of the payment service, e.g. payment status of an order
+	paymentClient, err := client.NewPaymentClient("localhost:50054")
+	if err != nil {
+		log.Logger.Fatalf("Failed init grpc payment client: %v", err)
+	}
+
 	orderRepository := repository.NewOrderRepository(db, redis)
-	orderService := service.NewOrderService(*orderRepository)
+	orderService := service.NewOrderService(*orderRepository, productClient, paymentClient)
 	orderUsecase := usecase.NewOrderUsecase(*orderService, *kafkaProducer)
 	orderHandler := handler.NewOrderHandler(*orderUsecase)
 
-	port := cfg.App.Port
-	router := gin.Default()
-	routes.SetupRoutes(router, *orderHandler, cfg.Secret.JWTSecret)
-	router.Run(":" + port)
+	// publishes the order events written to the outbox
+	orderUsecase.StartRelayOrderOutbox()
+
+	// grpc server for the product service, e.g. review eligibility
+	go grpc.StartOrderServer("50052", grpc.NewOrderServer(*orderUsecase))
 
 	// kafka consumer
 	kafkaPaymentSuccessConsumer := consumer.NewPaymentSuccessConsumer(
@@ -46,7 +61,7 @@ func main() {
 		*kafkaProducer,
 	)
 
-	kafkaPaymentSuccessConsumer.StartPaymentSuccessConsumer(context.Background())
+	go kafkaPaymentSuccessConsumer.StartPaymentSuccessConsumer(context.Background())
 
 	kafkaPaymentFailedConsumer := consumer.NewPaymentFailedConsumer(
 		[]string{"localhost:9093"},
@@ -55,7 +70,12 @@ func main() {
 		*kafkaProducer,
 	)
 
-	kafkaPaymentFailedConsumer.Start(context.Background())
+	go kafkaPaymentFailedConsumer.Start(context.Background())
+
+	port := cfg.App.Port
+	router := gin.Default()
+	routes.SetupRoutes(router, *orderHandler, cfg.Secret.JWTSecret)
 
 	log.Logger.Printf("Server running on port: %s", port)
+	router.Run(":" + port)
 }
\ No newline at end of file
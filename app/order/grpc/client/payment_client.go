@@ -0,0 +1,68 @@
+package client
+
+import (
+	// golang package
+	"context"
+	"orderfc/models"
+	"orderfc/proto/paymentpb"
+	"time"
+
+	// external package
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const paymentClientTimeout = 3 * time.Second
+
+type PaymentClient interface {
+	GetPaymentByOrderID(ctx context.Context, orderID int64) (models.PaymentInfo, error)
+}
+
+type paymentClient struct {
+	Client paymentpb.PaymentServiceClient
+}
+
+// NewPaymentClient new payment client by given address.
+//
+// It does not dial yet, the connection is made on the first call.
+// It returns PaymentClient, and nil error when successful.
+// Otherwise, nil PaymentClient, and error will be returned.
+func NewPaymentClient(address string) (PaymentClient, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &paymentClient{
+		Client: paymentpb.NewPaymentServiceClient(conn),
+	}, nil
+}
+
+// GetPaymentByOrderID get payment by order id by given orderID.
+//
+// An order without an invoice yet has no payment, empty models.PaymentInfo is returned for it.
+// It returns models.PaymentInfo, and nil error when successful.
+// Otherwise, empty models.PaymentInfo, and error will be returned.
+func (c *paymentClient) GetPaymentByOrderID(ctx context.Context, orderID int64) (models.PaymentInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, paymentClientTimeout)
+	defer cancel()
+
+	result, err := c.Client.GetPaymentByOrderID(ctx, &paymentpb.GetPaymentByOrderIDRequest{
+		OrderId: orderID,
+	})
+	if status.Code(err) == codes.NotFound {
+		return models.PaymentInfo{}, nil
+	}
+	if err != nil {
+		return models.PaymentInfo{}, err
+	}
+
+	return models.PaymentInfo{
+		Status:      result.GetStatus(),
+		Amount:      result.GetAmount(),
+		InvoiceURL:  result.GetInvoiceUrl(),
+		ExpiredTime: time.Unix(result.GetExpiredTime(), 0),
+	}, nil
+}
@@ -0,0 +1,279 @@
+package client
+
+import (
+	// golang package
+	"context"
+	"orderfc/models"
+	"orderfc/proto/productpb"
+	"time"
+
+	// external package
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const productClientTimeout = 3 * time.Second
+
+type ProductClient interface {
+	GetProducts(ctx context.Context, productIDs []int64) (map[int64]models.ProductInfo, error)
+	CheckAvailability(ctx context.Context, items []models.CheckoutItem) ([]models.ItemAvailability, error)
+	GetBundleItems(ctx context.Context, productIDs []int64) (map[int64][]models.BundleItem, error)
+	ReserveFlashSaleStock(ctx context.Context, userID int64, items []models.CheckoutItem) ([]models.FlashSaleReservation, error)
+	ReleaseFlashSaleStock(ctx context.Context, userID int64, reservations []models.FlashSaleReservation) error
+	GetWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) (models.WishlistItem, error)
+	RemoveWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) error
+}
+
+type productClient struct {
+	Client productpb.ProductServiceClient
+}
+
+// NewProductClient new product client by given address.
+//
+// It does not dial yet, the connection is made on the first call.
+// It returns ProductClient, and nil error when successful.
+// Otherwise, nil ProductClient, and error will be returned.
+func NewProductClient(address string) (ProductClient, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &productClient{
+		Client: productpb.NewProductServiceClient(conn),
+	}, nil
+}
+
+// GetProducts get products by given productIDs.
+//
+// Unknown products are left out of the result.
+// It returns map of product id to models.ProductInfo, and nil error when successful.
+// Otherwise, nil map, and error will be returned.
+func (c *productClient) GetProducts(ctx context.Context, productIDs []int64) (map[int64]models.ProductInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	result, err := c.Client.GetProductsByIDs(ctx, &productpb.GetProductsByIDsRequest{
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]models.ProductInfo, len(result.GetProducts()))
+	for _, product := range result.GetProducts() {
+		productInfo := models.ProductInfo{
+			ID:          product.GetId(),
+			Name:        product.GetName(),
+			Price:       product.GetEffectivePrice(),
+			Purchasable: product.GetPurchasable(),
+		}
+
+		if len(product.GetVariants()) != 0 {
+			productInfo.VariantPrices = make(map[int64]float64, len(product.GetVariants()))
+			for _, variant := range product.GetVariants() {
+				productInfo.VariantPrices[variant.GetId()] = variant.GetPrice()
+			}
+		}
+		products[product.GetId()] = productInfo
+	}
+
+	return products, nil
+}
+
+// CheckAvailability check availability by given items slice of CheckoutItem.
+//
+// The answer is read from the database of the product service and lists the items in the order asked.
+// It returns slice of models.ItemAvailability, and nil error when successful.
+// Otherwise, nil value of models.ItemAvailability slice, and error will be returned.
+func (c *productClient) CheckAvailability(ctx context.Context, items []models.CheckoutItem) ([]models.ItemAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	request := &productpb.CheckAvailabilityRequest{
+		Items: make([]*productpb.AvailabilityItem, len(items)),
+	}
+	for i, item := range items {
+		request.Items[i] = &productpb.AvailabilityItem{
+			ProductId: item.ProductID,
+			VariantId: item.VariantID,
+			Quantity:  int32(item.Quantity),
+		}
+	}
+
+	result, err := c.Client.CheckAvailability(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	availabilities := make([]models.ItemAvailability, len(result.GetItems()))
+	for i, item := range result.GetItems() {
+		availabilities[i] = models.ItemAvailability{
+			ProductID:      item.GetProductId(),
+			VariantID:      item.GetVariantId(),
+			Quantity:       int(item.GetQuantity()),
+			AvailableStock: int(item.GetAvailableStock()),
+			Available:      item.GetAvailable(),
+			Reason:         item.GetReason(),
+			Name:           item.GetName(),
+			UnitPrice:      item.GetUnitPrice(),
+		}
+	}
+
+	return availabilities, nil
+}
+
+// GetBundleItems get bundle items by given productIDs.
+//
+// Only bundles are keys of the result, other and unknown products are left out.
+// It returns map of product id to slice of models.BundleItem, and nil error when successful.
+// Otherwise, nil map, and error will be returned.
+func (c *productClient) GetBundleItems(ctx context.Context, productIDs []int64) (map[int64][]models.BundleItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	result, err := c.Client.GetProductsByIDs(ctx, &productpb.GetProductsByIDsRequest{
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bundleItems := map[int64][]models.BundleItem{}
+	for _, product := range result.GetProducts() {
+		if len(product.GetBundleItems()) == 0 {
+			continue
+		}
+
+		items := make([]models.BundleItem, len(product.GetBundleItems()))
+		for i, bundleItem := range product.GetBundleItems() {
+			items[i] = models.BundleItem{
+				ProductID: bundleItem.GetProductId(),
+				VariantID: bundleItem.GetVariantId(),
+				Quantity:  int(bundleItem.GetQuantity()),
+			}
+		}
+		bundleItems[product.GetId()] = items
+	}
+
+	return bundleItems, nil
+}
+
+// ReserveFlashSaleStock reserve flash sale stock by given userID, and items slice of CheckoutItem.
+//
+// Items that are not on a running flash sale are not reserved and are left out of the result.
+// It returns slice of models.FlashSaleReservation, and nil error when successful.
+// Otherwise, nil value of models.FlashSaleReservation slice, and error will be returned.
+func (c *productClient) ReserveFlashSaleStock(ctx context.Context, userID int64, items []models.CheckoutItem) ([]models.FlashSaleReservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	request := &productpb.ReserveFlashSaleStockRequest{
+		UserId: userID,
+		Items:  make([]*productpb.AvailabilityItem, len(items)),
+	}
+	for i, item := range items {
+		request.Items[i] = &productpb.AvailabilityItem{
+			ProductId: item.ProductID,
+			VariantId: item.VariantID,
+			Quantity:  int32(item.Quantity),
+		}
+	}
+
+	result, err := c.Client.ReserveFlashSaleStock(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := make([]models.FlashSaleReservation, len(result.GetReservations()))
+	for i, reservation := range result.GetReservations() {
+		reservations[i] = models.FlashSaleReservation{
+			ProductID:   reservation.GetProductId(),
+			VariantID:   reservation.GetVariantId(),
+			Quantity:    int(reservation.GetQuantity()),
+			FlashSaleID: reservation.GetFlashSaleId(),
+			Price:       reservation.GetPrice(),
+		}
+	}
+
+	return reservations, nil
+}
+
+// ReleaseFlashSaleStock release flash sale stock by given userID, and reservations slice of FlashSaleReservation.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (c *productClient) ReleaseFlashSaleStock(ctx context.Context, userID int64, reservations []models.FlashSaleReservation) error {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	request := &productpb.ReleaseFlashSaleStockRequest{
+		UserId:       userID,
+		Reservations: make([]*productpb.FlashSaleReservation, len(reservations)),
+	}
+	for i, reservation := range reservations {
+		request.Reservations[i] = &productpb.FlashSaleReservation{
+			ProductId:   reservation.ProductID,
+			VariantId:   reservation.VariantID,
+			Quantity:    int32(reservation.Quantity),
+			FlashSaleId: reservation.FlashSaleID,
+			Price:       reservation.Price,
+		}
+	}
+
+	_, err := c.Client.ReleaseFlashSaleStock(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetWishlistItem get wishlist item by given userID, and wishlistItemID.
+//
+// It returns models.WishlistItem, and nil error when successful.
+// Otherwise, empty models.WishlistItem, and error will be returned. An item the user does not have is empty
+// without an error.
+func (c *productClient) GetWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) (models.WishlistItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	result, err := c.Client.GetWishlistItem(ctx, &productpb.GetWishlistItemRequest{
+		UserId:         userID,
+		WishlistItemId: wishlistItemID,
+	})
+	if status.Code(err) == codes.NotFound {
+		return models.WishlistItem{}, nil
+	}
+	if err != nil {
+		return models.WishlistItem{}, err
+	}
+
+	return models.WishlistItem{
+		ID:        result.GetId(),
+		ProductID: result.GetProductId(),
+		VariantID: result.GetVariantId(),
+	}, nil
+}
+
+// RemoveWishlistItem remove wishlist item by given userID, and wishlistItemID.
+//
+// An item that is already gone is not an error.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (c *productClient) RemoveWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, productClientTimeout)
+	defer cancel()
+
+	_, err := c.Client.RemoveWishlistItem(ctx, &productpb.RemoveWishlistItemRequest{
+		UserId:         userID,
+		WishlistItemId: wishlistItemID,
+	})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	// golang package
+	"context"
+	"net"
+	"orderfc/cmd/order/usecase"
+	"orderfc/infrastructure/log"
+	"orderfc/proto/orderpb"
+
+	// external package
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type orderServer struct {
+	orderpb.UnimplementedOrderServiceServer
+	OrderUsecase usecase.OrderUsecase
+}
+
+// NewOrderServer new order server by given OrderUsecase.
+//
+// It returns orderpb.OrderServiceServer when successful.
+// Otherwise, nil orderpb.OrderServiceServer will be returned.
+func NewOrderServer(orderUsecase usecase.OrderUsecase) orderpb.OrderServiceServer {
+	return &orderServer{
+		OrderUsecase: orderUsecase,
+	}
+}
+
+// StartOrderServer start order server by given port, and server.
+//
+// It blocks serving grpc requests, so it is meant to run in its own goroutine.
+func StartOrderServer(port string, server orderpb.OrderServiceServer) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Logger.Fatalf("Failed listen grpc port %s: %v", port, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	orderpb.RegisterOrderServiceServer(grpcServer, server)
+
+	log.Logger.Printf("gRPC server running on port: %s", port)
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Logger.Fatalf("Failed serving grpc: %v", err)
+	}
+}
+
+// HasCompletedOrderWithProduct has completed order with product by given request pointer of orderpb.HasCompletedOrderWithProductRequest.
+//
+// It returns pointer of orderpb.HasCompletedOrderWithProductResult, and nil error when successful.
+// Otherwise, nil pointer of orderpb.HasCompletedOrderWithProductResult, and error will be returned.
+func (s *orderServer) HasCompletedOrderWithProduct(ctx context.Context, request *orderpb.HasCompletedOrderWithProductRequest) (*orderpb.HasCompletedOrderWithProductResult, error) {
+	if request.GetUserId() == 0 || request.GetProductId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id and product_id are required")
+	}
+
+	hasCompletedOrder, err := s.OrderUsecase.HasCompletedOrderWithProduct(ctx, request.GetUserId(), request.GetProductId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &orderpb.HasCompletedOrderWithProductResult{
+		HasCompletedOrder: hasCompletedOrder,
+	}, nil
+}
+
+// GetCompletedOrderBaskets get completed order baskets by given request pointer of orderpb.GetCompletedOrderBasketsRequest.
+//
+// It returns pointer of orderpb.GetCompletedOrderBasketsResult, and nil error when successful.
+// Otherwise, nil pointer of orderpb.GetCompletedOrderBasketsResult, and error will be returned.
+func (s *orderServer) GetCompletedOrderBaskets(ctx context.Context, request *orderpb.GetCompletedOrderBasketsRequest) (*orderpb.GetCompletedOrderBasketsResult, error) {
+	baskets, err := s.OrderUsecase.GetCompletedOrderBaskets(ctx, request.GetAfterOrderId(), int(request.GetLimit()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result := &orderpb.GetCompletedOrderBasketsResult{
+		Baskets: make([]*orderpb.OrderBasket, 0, len(baskets)),
+	}
+	for _, basket := range baskets {
+		result.Baskets = append(result.Baskets, &orderpb.OrderBasket{
+			OrderId:    basket.OrderID,
+			ProductIds: basket.ProductIDs,
+		})
+	}
+
+	return result, nil
+}
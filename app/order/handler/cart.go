@@ -0,0 +1,271 @@
+package handler
+
+import (
+	// golang package
+	"net/http"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"strconv"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// headerCartID carries the guest cart id, the server hands out a new one on the first add of a guest.
+const headerCartID = "X-Cart-ID"
+
+// GetCart get cart by given c pointer of gin.Context.
+func (h *OrderHandler) GetCart(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.OrderUsecase.GetCart(c.Request.Context(), owner)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"owner": owner,
+		}).Errorf("h.OrderUsecase.GetCart() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart": cart,
+	})
+}
+
+// AddCartItem add cart item by given c pointer of gin.Context.
+func (h *OrderHandler) AddCartItem(c *gin.Context) {
+	var param models.CartItemRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	if owner.UserID == 0 && owner.GuestCartID == "" {
+		owner.GuestCartID = uuid.New().String()
+		c.Header(headerCartID, owner.GuestCartID)
+	}
+
+	cart, err := h.OrderUsecase.AddCartItem(c.Request.Context(), owner, &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"owner": owner,
+			"param": param,
+		}).Errorf("h.OrderUsecase.AddCartItem() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart": cart,
+	})
+}
+
+// UpdateCartItem update cart item by given c pointer of gin.Context.
+func (h *OrderHandler) UpdateCartItem(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil || productID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	var param models.CartQuantityRequest
+	if err = c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.OrderUsecase.UpdateCartItemQuantity(c.Request.Context(), owner, productID, &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"owner":     owner,
+			"productID": productID,
+			"param":     param,
+		}).Errorf("h.OrderUsecase.UpdateCartItemQuantity() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart": cart,
+	})
+}
+
+// RemoveCartItem remove cart item by given c pointer of gin.Context.
+func (h *OrderHandler) RemoveCartItem(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil || productID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	variantID, err := strconv.ParseInt(c.DefaultQuery("variant_id", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant id"})
+		return
+	}
+
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	if err = h.OrderUsecase.RemoveCartItem(c.Request.Context(), owner, productID, variantID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"owner":     owner,
+			"productID": productID,
+			"variantID": variantID,
+		}).Errorf("h.OrderUsecase.RemoveCartItem() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.OrderUsecase.GetCart(c.Request.Context(), owner)
+	if err != nil {
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart": cart,
+	})
+}
+
+// ClearCart clear cart by given c pointer of gin.Context.
+func (h *OrderHandler) ClearCart(c *gin.Context) {
+	owner, ok := cartOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.OrderUsecase.ClearCart(c.Request.Context(), owner); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"owner": owner,
+		}).Errorf("h.OrderUsecase.ClearCart() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "cart cleared",
+	})
+}
+
+// MergeCart merge cart by given c pointer of gin.Context.
+//
+// The client calls it right after login with the guest cart id it held until then.
+func (h *OrderHandler) MergeCart(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Unauthorized",
+		})
+		return
+	}
+
+	guestCartID := c.GetHeader(headerCartID)
+	if _, err := uuid.Parse(guestCartID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cart id"})
+		return
+	}
+
+	cart, err := h.OrderUsecase.MergeGuestCart(c.Request.Context(), guestCartID, userID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID":      userID,
+			"guestCartID": guestCartID,
+		}).Errorf("h.OrderUsecase.MergeGuestCart() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart": cart,
+	})
+}
+
+// MoveWishlistItemToCart move wishlist item to cart by given c pointer of gin.Context.
+func (h *OrderHandler) MoveWishlistItemToCart(c *gin.Context) {
+	var param models.MoveWishlistItemRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Unauthorized",
+		})
+		return
+	}
+
+	cart, err := h.OrderUsecase.MoveWishlistItemToCart(c.Request.Context(), userID, &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"param":  param,
+		}).Errorf("h.OrderUsecase.MoveWishlistItemToCart() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart": cart,
+	})
+}
+
+// cartOwner cart owner by given c pointer of gin.Context.
+//
+// A logged in user owns the user cart, a guest the cart of the X-Cart-ID header, which may be empty.
+// It returns models.CartOwner, and true when successful.
+// Otherwise, it writes a bad request and returns false.
+func cartOwner(c *gin.Context) (models.CartOwner, bool) {
+	if userID, ok := userIDFromContext(c); ok {
+		return models.CartOwner{UserID: userID}, true
+	}
+
+	guestCartID := c.GetHeader(headerCartID)
+	if guestCartID == "" {
+		return models.CartOwner{}, true
+	}
+
+	if _, err := uuid.Parse(guestCartID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cart id"})
+		return models.CartOwner{}, false
+	}
+
+	return models.CartOwner{GuestCartID: guestCartID}, true
+}
+
+// userIDFromContext user id from context by given c pointer of gin.Context.
+//
+// It returns int64, and true when the request carries a valid user.
+// Otherwise, 0, and false will be returned.
+func userIDFromContext(c *gin.Context) (int64, bool) {
+	userID, isExist := c.Get("user_id")
+	if !isExist {
+		return 0, false
+	}
+
+	userIDFloat, ok := userID.(float64)
+	if !ok || userIDFloat == 0 {
+		return 0, false
+	}
+
+	return int64(userIDFloat), true
+}
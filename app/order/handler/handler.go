@@ -2,7 +2,9 @@ package handler
 
 import (
 	// golang package
+	"errors"
 	"net/http"
+	"orderfc/cmd/order/service"
 	"orderfc/cmd/order/usecase"
 	"orderfc/infrastructure/log"
 	"orderfc/models"
@@ -35,11 +37,16 @@ func (h *OrderHandler) Checkout(c *gin.Context) {
 		return
 	}
 
-	if len(param.Items) == 0 {
+	if len(param.Items) == 0 && !param.FromCart {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "items must not be empty"})
 		return
 	}
 
+	if len(param.Items) != 0 && param.FromCart {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "send either items or from_cart"})
+		return
+	}
+
 	userIDStr, isExist := c.Get("user_id")
 	if !isExist {
 		c.JSON(http.StatusUnauthorized, gin.H{
@@ -67,7 +74,7 @@ func (h *OrderHandler) Checkout(c *gin.Context) {
 		log.Logger.WithFields(logrus.Fields{
 			"param": param,
 		}).Errorf("h.OrderUsecase.CheckoutOrder() got error %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -110,4 +117,27 @@ func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"data": histories,
 	})
+}
+
+// orderErrorStatus order error status by given err.
+//
+// It returns the http status of an order, checkout or cart error.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrCartItemNotFound),
+		errors.Is(err, usecase.ErrWishlistItemNotFound),
+		errors.Is(err, service.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidCartItem),
+		errors.Is(err, usecase.ErrEmptyCart),
+		errors.Is(err, usecase.ErrInvalidOrderStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrItemUnavailable),
+		errors.Is(err, usecase.ErrPriceChanged),
+		errors.Is(err, service.ErrInvalidOrderStatusTransition),
+		errors.Is(err, usecase.ErrOrderNotCancellable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
 }
\ No newline at end of file
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	// golang package
+	"net/http"
+	"orderfc/infrastructure/log"
+	"strconv"
+
+	// external package
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// staffRoles are the roles of the token that may see the orders of any user.
+var staffRoles = map[string]bool{
+	models.RoleAdmin: true,
+	models.RoleStaff: true,
+}
+
+// GetOrder get order by given c pointer of gin.Context.
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Unauthorized",
+		})
+		return
+	}
+
+	order, err := h.OrderUsecase.GetOrderDetail(c.Request.Context(), orderID, userID, staffRoles[c.GetString("role")])
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"order_id": orderID,
+			"user_id":  userID,
+		}).Errorf("h.OrderUsecase.GetOrderDetail() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": order,
+	})
+}
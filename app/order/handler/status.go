@@ -0,0 +1,96 @@
+package handler
+
+import (
+	// golang package
+	"errors"
+	"io"
+	"net/http"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"strconv"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// UpdateOrderStatus update order status by given c pointer of gin.Context.
+//
+// It is meant for the staff routes, the caller is recorded as the actor of the change.
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	var param models.OrderStatusRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	staffID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Unauthorized",
+		})
+		return
+	}
+
+	order, err := h.OrderUsecase.ChangeOrderStatus(c.Request.Context(), orderID, staffID, param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"order_id": orderID,
+			"param":    param,
+		}).Errorf("h.OrderUsecase.ChangeOrderStatus() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"order_id": order.ID,
+		"status":   constant.OrderStatusTranslated[order.Status],
+	})
+}
+
+// CancelOrder cancel order by given c pointer of gin.Context.
+//
+// A user cancels an own order with it, the body with a reason is optional.
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	var param models.CancelOrderRequest
+	if err := c.ShouldBindJSON(&param); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Unauthorized",
+		})
+		return
+	}
+
+	order, err := h.OrderUsecase.CancelOrder(c.Request.Context(), orderID, userID, param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"order_id": orderID,
+			"user_id":  userID,
+		}).Errorf("h.OrderUsecase.CancelOrder() got error %v", err)
+		c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"order_id": order.ID,
+		"status":   constant.OrderStatusTranslated[order.Status],
+	})
+}
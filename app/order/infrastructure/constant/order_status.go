@@ -0,0 +1,64 @@
+package constant
+
+// order status stored in orders.status, the values of created, completed and cancelled are the ones
+// orders were saved with before the other statuses existed, 4 is not used
+const (
+	OrderStatusCreated    = 1
+	OrderStatusCompleted  = 2
+	OrderStatusCancelled  = 3
+	OrderStatusPaid       = 5
+	OrderStatusProcessing = 6
+	OrderStatusShipped    = 7
+	OrderStatusDelivered  = 8
+	OrderStatusRefunded   = 9
+)
+
+// OrderStatusTranslated is the name of a status in responses, events and the order history.
+var OrderStatusTranslated = map[int]string{
+	OrderStatusCreated:    "created",
+	OrderStatusPaid:       "paid",
+	OrderStatusProcessing: "processing",
+	OrderStatusShipped:    "shipped",
+	OrderStatusDelivered:  "delivered",
+	OrderStatusCompleted:  "completed",
+	OrderStatusCancelled:  "cancelled",
+	OrderStatusRefunded:   "refunded",
+}
+
+// OrderStatusTransitions is the state machine of an order, the statuses an order may move to from its
+// current status. Cancelled and refunded are final. A paid order is refunded rather than cancelled.
+var OrderStatusTransitions = map[int][]int{
+	OrderStatusCreated:    {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:       {OrderStatusProcessing, OrderStatusRefunded},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusShipped:    {OrderStatusDelivered},
+	OrderStatusDelivered:  {OrderStatusCompleted, OrderStatusRefunded},
+	OrderStatusCompleted:  {OrderStatusRefunded},
+}
+
+// OrderStatusesCompleted are the statuses of an order that reached the customer and was not refunded,
+// the orders review eligibility and co-occurrence baskets count.
+var OrderStatusesCompleted = []int{
+	OrderStatusDelivered,
+	OrderStatusCompleted,
+}
+
+// actor of an order status change
+const (
+	OrderActorUser    = "user"
+	OrderActorStaff   = "staff"
+	OrderActorPayment = "payment"
+)
+
+// CanChangeOrderStatus can change order status by given from, and to.
+//
+// It returns true when the state machine allows an order in from to move to to.
+func CanChangeOrderStatus(from int, to int) bool {
+	for _, next := range OrderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
@@ -9,7 +9,6 @@ import (
 	"orderfc/infrastructure/constant"
 	kafkaFC "orderfc/kafka"
 	"orderfc/models"
-	"time"
 
 	// external package
 	"github.com/segmentio/kafka-go"
@@ -57,41 +56,15 @@ func (c *PaymentFailedConsumer) Start(ctx context.Context) {
 			continue
 		}
 
-		// update DB status order
-		err = c.OrderService.UpdateOrderStatus(ctx, event.OrderID, constant.OrderStatusCancelled)
-		if err != nil {
-			log.Println("[PF] Error Update Order Status: ", err)
-			continue
-		}
-
-		// order info
-		orderInfo, err := c.OrderService.GetOrderInfoByOrderID(ctx, event.OrderID)
-		if err != nil {
-			continue
-		}
-
-		// order detail info
-		orderDetailInfo, err := c.OrderService.GetOrderDetailByOrderDetailID(ctx, orderInfo.OrderDetailID)
-		if err != nil {
-			continue
-		}
-
-		// construct products
-		var products []models.CheckoutItem
-		err = json.Unmarshal([]byte(orderDetailInfo.Products), &products)
-		if err != nil {
-			continue
-		}
-
-		// publish event stock.rollback
-		updateStockEvent := models.ProductStockUpdateEvent{
-			OrderID:   event.OrderID,
-			Products:  convertCheckoutItemToProductItems(products),
-			EventTime: time.Now(),
-		}
-
-		err = c.Producer.PublishProductStockRollback(ctx, updateStockEvent)
+		// cancel the order and give its stock back in one transaction, the rollback goes out through the outbox
+		_, err = c.OrderService.ChangeOrderStatus(ctx, models.OrderStatusChange{
+			OrderID: event.OrderID,
+			Status:  constant.OrderStatusCancelled,
+			Actor:   constant.OrderActorPayment,
+			Reason:  "payment failed",
+		}, service.StockRollbackEvents)
 		if err != nil {
+			log.Println("[PF] Error Change Order Status: ", err)
 			continue
 		}
 	}
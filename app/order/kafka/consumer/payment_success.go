@@ -61,56 +61,15 @@ func (c *PaymentSuccessConsumer) StartPaymentSuccessConsumer(ctx context.Context
 		log.Logger.Printf("[KAFKA] Received payment.success event for Order ID #%d", event.OrderID)
 
 		// update DB
-		err = c.OrderService.UpdateOrderStatus(ctx, event.OrderID, constant.OrderStatusCompleted)
+		_, err = c.OrderService.ChangeOrderStatus(ctx, models.OrderStatusChange{
+			OrderID: event.OrderID,
+			Status:  constant.OrderStatusPaid,
+			Actor:   constant.OrderActorPayment,
+			Reason:  "payment succeeded",
+		}, nil)
 		if err != nil {
-			log.Logger.Println("[KAFKA] Error Update Order Status: ", err)
+			log.Logger.Println("[KAFKA] Error Change Order Status: ", err)
 			continue
 		}
-
-		// // get order info from db
-		// orderInfo, err := c.OrderService.GetOrderInfoByOrderID(ctx, event.OrderID)
-		// if err != nil {
-		// 	log.Logger.Println("[KAFKA] Error Get Order Info: ", err)
-		// 	continue
-		// }
-
-		// // get order detail from db
-		// orderDetail, err := c.OrderService.GetOrderDetailByOrderDetailID(ctx, orderInfo.OrderDetailID)
-		// if err != nil {
-		// 	log.Logger.Println("[KAFKA] Error Get Order Detail: ", err)
-		// 	continue
-		// }
-
-		// // get product list from order detail
-		// var products []models.CheckoutItem
-		// err = json.Unmarshal([]byte(orderDetail.Products), &products)
-		// if err != nil {
-		// 	log.Logger.Println("[KAFKA] Error Get Product List From Order Detail: ", err)
-		// 	continue
-		// }
-
-		// publish event product service
-		// err = c.Producer.PublishProductStockUpdate(ctx, models.ProductStockUpdateEvent{
-		// 	OrderID:   event.OrderID,
-		// 	Products:  convertCheckoutItemToProductItems(products),
-		// 	EventTime: time.Now(),
-		// })
 	}
-}
-
-// convertCheckoutItemToProductItems convert checkout item to product items by given source slice of CheckoutItem.
-//
-// It returns slice of models.ProductItem when successful.
-// Otherwise, nil value of models.ProductItem slice will be returned.
-func convertCheckoutItemToProductItems(source []models.CheckoutItem) []models.ProductItem {
-	result := make([]models.ProductItem, len(source))
-
-	for index, item := range source {
-		result[index] = models.ProductItem{
-			ProductID: item.ProductID,
-			Qty:       item.Quantity,
-		}
-	}
-
-	return result
 }
\ No newline at end of file
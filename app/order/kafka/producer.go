@@ -4,13 +4,25 @@ import (
 	// golang package
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"orderfc/models"
+	"time"
 
 	// external package
 	"github.com/segmentio/kafka-go"
 )
 
+const (
+	TopicOrderCreated  = "order.created"
+	TopicStockUpdate   = "stock.update"
+	TopicStockRollback = "stock.rollback"
+	// TopicOrderStatusChanged carries every status change of an order after checkout
+	TopicOrderStatusChanged = "order.status_changed"
+	// TopicOrderCancelled asks the payment service to expire or refund the invoice of a cancelled order
+	TopicOrderCancelled = "order.cancelled"
+)
+
 type KafkaProducer struct {
 	writer *kafka.Writer
 }
@@ -40,7 +52,7 @@ func (p *KafkaProducer) PublishOrderCreated(ctx context.Context, event models.Or
 	msg := kafka.Message{
 		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
 		Value: value,
-		Topic: "order.created",
+		Topic: TopicOrderCreated,
 	}
 
 	return p.writer.WriteMessages(ctx, msg)
@@ -59,7 +71,7 @@ func (p *KafkaProducer) PublishProductStockUpdate(ctx context.Context, event mod
 	msg := kafka.Message{
 		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
 		Value: value,
-		Topic: "stock.update",
+		Topic: TopicStockUpdate,
 	}
 
 	return p.writer.WriteMessages(ctx, msg)
@@ -78,12 +90,64 @@ func (p *KafkaProducer) PublishProductStockRollback(ctx context.Context, event m
 	msg := kafka.Message{
 		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
 		Value: value,
-		Topic: "stock.rollback",
+		Topic: TopicStockRollback,
 	}
 
 	return p.writer.WriteMessages(ctx, msg)
 }
 
+// NewOrderOutbox new order outbox by given topic, orderID, and event.
+//
+// The key is the one the Publish methods use, so the events of one order land on one partition in order.
+// It returns models.OrderOutbox, and nil error when successful.
+// Otherwise, empty models.OrderOutbox, and error will be returned.
+func NewOrderOutbox(topic string, orderID int64, event interface{}) (models.OrderOutbox, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return models.OrderOutbox{}, err
+	}
+
+	return models.OrderOutbox{
+		Topic:           topic,
+		MessageKey:      fmt.Sprintf("order-%d", orderID),
+		Payload:         string(value),
+		NextAttemptTime: time.Now(),
+	}, nil
+}
+
+// PublishOrderOutbox publish order outbox by given slice of OrderOutbox.
+//
+// The events are written in one call, a failure of one of them does not fail the others.
+// It returns slice of error in the order of orderOutboxes, an entry is nil when that event was written.
+func (p *KafkaProducer) PublishOrderOutbox(ctx context.Context, orderOutboxes []models.OrderOutbox) []error {
+	messages := make([]kafka.Message, len(orderOutboxes))
+	for index, orderOutbox := range orderOutboxes {
+		messages[index] = kafka.Message{
+			Key:   []byte(orderOutbox.MessageKey),
+			Value: []byte(orderOutbox.Payload),
+			Topic: orderOutbox.Topic,
+		}
+	}
+
+	errs := make([]error, len(orderOutboxes))
+	err := p.writer.WriteMessages(ctx, messages...)
+	if err == nil {
+		return errs
+	}
+
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) && len(writeErrors) == len(errs) {
+		copy(errs, writeErrors)
+		return errs
+	}
+
+	for index := range errs {
+		errs[index] = err
+	}
+
+	return errs
+}
+
 // Close close.
 //
 // It returns nil error when successful.
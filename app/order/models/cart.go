@@ -0,0 +1,74 @@
+package models
+
+// CartOwner is the user of a cart, or the guest cart id of a visitor who has not logged in.
+type CartOwner struct {
+	UserID      int64
+	GuestCartID string
+}
+
+// Cart is what a user or guest put aside to check out later.
+type Cart struct {
+	// CartID is set for guest carts only, the client sends it back in the X-Cart-ID header
+	CartID      string     `json:"cart_id,omitempty"`
+	Items       []CartItem `json:"items"`
+	TotalQty    int        `json:"total_qty"`
+	TotalAmount float64    `json:"total_amount"`
+}
+
+// CartItem is one line of a cart. Only the product, variant and quantity are stored, the rest is read
+// from the product service whenever the cart is shown.
+type CartItem struct {
+	ProductID   int64   `json:"product_id"`
+	VariantID   int64   `json:"variant_id,omitempty"`
+	Quantity    int     `json:"quantity"`
+	Name        string  `json:"name,omitempty"`
+	Price       float64 `json:"price"`
+	Purchasable bool    `json:"purchasable"`
+}
+
+type CartItemRequest struct {
+	ProductID int64 `json:"product_id" binding:"required"`
+	VariantID int64 `json:"variant_id"`
+	Quantity  int   `json:"quantity" binding:"required"`
+}
+
+// MoveWishlistItemRequest moves a wishlist item of the user into the cart, quantity defaults to 1.
+type MoveWishlistItemRequest struct {
+	WishlistItemID int64 `json:"wishlist_item_id" binding:"required"`
+	Quantity       int   `json:"quantity"`
+}
+
+// WishlistItem is a product, or one of its variants, the user saved in the product service.
+type WishlistItem struct {
+	ID        int64
+	ProductID int64
+	VariantID int64
+}
+
+type CartQuantityRequest struct {
+	VariantID int64 `json:"variant_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+// ProductInfo is the part of a product the order service needs to price a cart.
+type ProductInfo struct {
+	ID          int64
+	Name        string
+	Price       float64
+	Purchasable bool
+	// VariantPrices holds the price of every variant, a product without variants has none
+	VariantPrices map[int64]float64
+}
+
+// UnitPrice unit price by given variantID.
+//
+// It returns float64, and true when the product is sold as variantID.
+// Otherwise, 0, and false will be returned.
+func (p ProductInfo) UnitPrice(variantID int64) (float64, bool) {
+	if len(p.VariantPrices) == 0 {
+		return p.Price, variantID == 0
+	}
+
+	price, ok := p.VariantPrices[variantID]
+	return price, ok
+}
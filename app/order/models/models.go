@@ -0,0 +1,254 @@
+package models
+
+import (
+	// golang package
+	"time"
+)
+
+type Order struct {
+	ID              int64     `json:"id"`
+	UserID          int64     `json:"user_id"`
+	OrderDetailID   int64     `json:"order_detail_id"`
+	Amount          float64   `json:"amount"`
+	TotalQty        int       `json:"total_qty"`
+	Status          int       `json:"status"`
+	PaymentMethod   string    `json:"payment_method"`
+	ShippingAddress string    `json:"shipping_address"`
+	CreateTime      time.Time `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime      time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+type OrderDetail struct {
+	ID           int64     `json:"id"`
+	Products     string    `json:"products"`
+	OrderHistory string    `json:"order_history"`
+	CreateTime   time.Time `json:"create_time" gorm:"autoCreateTime"`
+	UpdateTime   time.Time `json:"update_time" gorm:"autoUpdateTime"`
+}
+
+type OrderRequestLog struct {
+	ID               int64     `json:"id"`
+	IdempotencyToken string    `json:"idempotency_token"`
+	CreateTime       time.Time `json:"create_time"`
+}
+
+type CheckoutRequest struct {
+	UserID           int64          `json:"user_id"`
+	Items            []CheckoutItem `json:"items"`
+	PaymentMethod    string         `json:"payment_method"`
+	ShippingAddress  string         `json:"shipping_address"`
+	IdempotencyToken string         `json:"idempotency_token"`
+	// FromCart checks out the cart of the user instead of Items, and empties it once the order is saved
+	FromCart bool `json:"from_cart"`
+	// AcceptPriceChanges takes the current price of items whose price changed instead of rejecting the checkout
+	AcceptPriceChanges bool `json:"accept_price_changes"`
+}
+
+type CheckoutItem struct {
+	ProductID int64 `json:"product_id"`
+	// VariantID is 0 for products without variants.
+	VariantID int64   `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+	// Name is set by checkout from the product service, never by the client, so the order keeps the
+	// name the item was bought under.
+	Name string `json:"name,omitempty"`
+	// FlashSaleID is set by checkout when the item was reserved from a flash sale, never by the client.
+	FlashSaleID int64 `json:"flash_sale_id,omitempty"`
+	// BundleItems is set by checkout when the item is a bundle, never by the client. It keeps the
+	// components the bundle had at checkout, so a rollback returns what was taken.
+	BundleItems []BundleItem `json:"bundle_items,omitempty"`
+}
+
+// BundleItem is one component of a bundle, Quantity units of it ship with every unit of the bundle.
+type BundleItem struct {
+	ProductID int64 `json:"product_id"`
+	VariantID int64 `json:"variant_id,omitempty"`
+	Quantity  int   `json:"quantity"`
+}
+
+type OrderCreatedEvent struct {
+	OrderID         int64   `json:"order_id"`
+	UserID          int64   `json:"user_id"`
+	TotalAmount     float64 `json:"total_amount"`
+	PaymentMethod   string  `json:"payment_method"`
+	ShippingAddress string  `json:"shipping_address"`
+}
+
+type ProductStockUpdateEvent struct {
+	OrderID  int64         `json:"order_id"`
+	UserID   int64         `json:"user_id,omitempty"`
+	Products []ProductItem `json:"products"`
+	// ShippingAddress lets the product service source the items from the nearest warehouse
+	ShippingAddress string    `json:"shipping_address,omitempty"`
+	EventTime       time.Time `json:"event_time"`
+}
+
+type ProductItem struct {
+	ProductID   int64 `json:"product_id"`
+	VariantID   int64 `json:"variant_id,omitempty"`
+	Qty         int   `json:"qty"`
+	FlashSaleID int64 `json:"flash_sale_id,omitempty"`
+}
+
+// ItemAvailability is the answer of the product service for one checkout item, read from its database.
+type ItemAvailability struct {
+	ProductID      int64
+	VariantID      int64
+	Quantity       int
+	AvailableStock int
+	Available      bool
+	// Reason explains why the item is not available, empty otherwise
+	Reason    string
+	Name      string
+	UnitPrice float64
+}
+
+// FlashSaleReservation is a checkout item the product service took out of a running flash sale.
+type FlashSaleReservation struct {
+	ProductID   int64
+	VariantID   int64
+	Quantity    int
+	FlashSaleID int64
+	Price       float64
+}
+
+// OrderCancelledEvent tells the payment service to expire the invoice of a cancelled order, or to refund
+// it when Refund is set because the order was paid.
+type OrderCancelledEvent struct {
+	OrderID   int64     `json:"order_id"`
+	UserID    int64     `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	Refund    bool      `json:"refund"`
+	Reason    string    `json:"reason"`
+	EventTime time.Time `json:"event_time"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+type PaymentUpdateStatusEvent struct {
+	OrderID int64  `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+type OrderHistoryParam struct {
+	UserID int64 `json:"user_id"`
+	Status int   `json:"status"`
+}
+
+type OrderJoinResult struct {
+	ID              int64   `json:"id"`
+	Amount          float64 `json:"amount"`
+	TotalQty        int     `json:"total_qty"`
+	Status          int     `json:"status"`
+	PaymentMethod   string  `json:"payment_method"`
+	ShippingAddress string  `json:"shipping_address"`
+	Products        string  `json:"products"`
+	OrderHistory    string  `json:"order_history"`
+}
+
+// PaymentInfo is the payment the payment service keeps for an order, Status is empty while no invoice
+// was created for it.
+type PaymentInfo struct {
+	Status      string    `json:"status"`
+	Amount      float64   `json:"amount"`
+	InvoiceURL  string    `json:"invoice_url,omitempty"`
+	ExpiredTime time.Time `json:"expired_time"`
+}
+
+// OrderDetailResponse is one order with its items, status timeline and payment.
+type OrderDetailResponse struct {
+	OrderID         int64           `json:"order_id"`
+	UserID          int64           `json:"user_id"`
+	TotalAmount     float64         `json:"total_amount"`
+	TotalQty        int             `json:"total_qty"`
+	Status          string          `json:"status"`
+	PaymentMethod   string          `json:"payment_method"`
+	ShippingAddress string          `json:"shipping_address"`
+	Items           []CheckoutItem  `json:"items"`
+	Timeline        []StatusHistory `json:"timeline"`
+	// Payment is nil when the payment service could not be asked, the order is still shown then
+	Payment    *PaymentInfo `json:"payment"`
+	CreateTime time.Time    `json:"create_time"`
+	UpdateTime time.Time    `json:"update_time"`
+}
+
+// OrderBasket is the distinct products bought together in one completed order.
+type OrderBasket struct {
+	OrderID    int64   `json:"order_id"`
+	ProductIDs []int64 `json:"product_ids"`
+}
+
+// StatusHistory is one entry of order_detail.order_history, the entries older than the status machine only
+// have a status and a timestamp.
+type StatusHistory struct {
+	Status    string    `json:"status"`
+	Actor     string    `json:"actor,omitempty"`
+	ActorID   int64     `json:"actor_id,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OrderStatusChange is a request to move an order to Status, Actor and Reason end up in its history.
+type OrderStatusChange struct {
+	OrderID int64
+	Status  int
+	Actor   string
+	ActorID int64
+	Reason  string
+}
+
+type OrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+type OrderStatusChangedEvent struct {
+	OrderID    int64     `json:"order_id"`
+	UserID     int64     `json:"user_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	ActorID    int64     `json:"actor_id,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	EventTime  time.Time `json:"event_time"`
+}
+
+type OrderHistoryResponse struct {
+	OrderID         int64           `json:"order_id"`
+	TotalAmount     float64         `json:"total_amount"`
+	TotalQty        int             `json:"total_qty"`
+	Status          string          `json:"status"`
+	PaymentMethod   string          `json:"payment_method"`
+	ShippingAddress string          `json:"shipping_address"`
+	Products        []CheckoutItem  `json:"products"`
+	History         []StatusHistory `json:"history"`
+}
+
+// StockItems stock items by given CheckoutItem.
+//
+// A bundle moves the stock of its components, any other item moves its own stock.
+// It returns slice of ProductItem.
+func (item CheckoutItem) StockItems() []ProductItem {
+	if len(item.BundleItems) == 0 {
+		return []ProductItem{{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			Qty:         item.Quantity,
+			FlashSaleID: item.FlashSaleID,
+		}}
+	}
+
+	result := make([]ProductItem, len(item.BundleItems))
+	for index, bundleItem := range item.BundleItems {
+		result[index] = ProductItem{
+			ProductID: bundleItem.ProductID,
+			VariantID: bundleItem.VariantID,
+			Qty:       bundleItem.Quantity * item.Quantity,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	// golang package
+	"time"
+)
+
+// OrderOutbox is an event written in the transaction of the order it belongs to, the relay publishes it
+// to kafka once the transaction committed.
+type OrderOutbox struct {
+	ID         int64  `json:"id"`
+	Topic      string `json:"topic"`
+	MessageKey string `json:"message_key"`
+	Payload    string `json:"payload"`
+	Attempts   int    `json:"attempts"`
+	LastError  string `json:"last_error"`
+	// NextAttemptTime holds a failed event back until its retry is due
+	NextAttemptTime time.Time  `json:"next_attempt_time"`
+	PublishTime     *time.Time `json:"publish_time"`
+	CreateTime      time.Time  `json:"create_time" gorm:"autoCreateTime"`
+}
+
+// OrderOutboxBacklog is what is still waiting in the outbox.
+type OrderOutboxBacklog struct {
+	Pending           int64      `json:"pending"`
+	OldestPendingTime *time.Time `json:"oldest_pending_time"`
+}
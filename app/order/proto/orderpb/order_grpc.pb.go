@@ -0,0 +1,163 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/order.proto
+
+package orderpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OrderService_HasCompletedOrderWithProduct_FullMethodName = "/order.OrderService/HasCompletedOrderWithProduct"
+	OrderService_GetCompletedOrderBaskets_FullMethodName     = "/order.OrderService/GetCompletedOrderBaskets"
+)
+
+// OrderServiceClient is the client API for OrderService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OrderServiceClient interface {
+	// HasCompletedOrderWithProduct tells whether the user has a completed order containing the product.
+	HasCompletedOrderWithProduct(ctx context.Context, in *HasCompletedOrderWithProductRequest, opts ...grpc.CallOption) (*HasCompletedOrderWithProductResult, error)
+	// GetCompletedOrderBaskets pages through the products of completed orders by ascending order id.
+	GetCompletedOrderBaskets(ctx context.Context, in *GetCompletedOrderBasketsRequest, opts ...grpc.CallOption) (*GetCompletedOrderBasketsResult, error)
+}
+
+type orderServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderServiceClient(cc grpc.ClientConnInterface) OrderServiceClient {
+	return &orderServiceClient{cc}
+}
+
+func (c *orderServiceClient) HasCompletedOrderWithProduct(ctx context.Context, in *HasCompletedOrderWithProductRequest, opts ...grpc.CallOption) (*HasCompletedOrderWithProductResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HasCompletedOrderWithProductResult)
+	err := c.cc.Invoke(ctx, OrderService_HasCompletedOrderWithProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetCompletedOrderBaskets(ctx context.Context, in *GetCompletedOrderBasketsRequest, opts ...grpc.CallOption) (*GetCompletedOrderBasketsResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCompletedOrderBasketsResult)
+	err := c.cc.Invoke(ctx, OrderService_GetCompletedOrderBaskets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OrderServiceServer is the server API for OrderService service.
+// All implementations must embed UnimplementedOrderServiceServer
+// for forward compatibility.
+type OrderServiceServer interface {
+	// HasCompletedOrderWithProduct tells whether the user has a completed order containing the product.
+	HasCompletedOrderWithProduct(context.Context, *HasCompletedOrderWithProductRequest) (*HasCompletedOrderWithProductResult, error)
+	// GetCompletedOrderBaskets pages through the products of completed orders by ascending order id.
+	GetCompletedOrderBaskets(context.Context, *GetCompletedOrderBasketsRequest) (*GetCompletedOrderBasketsResult, error)
+	mustEmbedUnimplementedOrderServiceServer()
+}
+
+// UnimplementedOrderServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderServiceServer struct{}
+
+func (UnimplementedOrderServiceServer) HasCompletedOrderWithProduct(context.Context, *HasCompletedOrderWithProductRequest) (*HasCompletedOrderWithProductResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasCompletedOrderWithProduct not implemented")
+}
+func (UnimplementedOrderServiceServer) GetCompletedOrderBaskets(context.Context, *GetCompletedOrderBasketsRequest) (*GetCompletedOrderBasketsResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCompletedOrderBaskets not implemented")
+}
+func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
+func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeOrderServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderServiceServer will
+// result in compilation errors.
+type UnsafeOrderServiceServer interface {
+	mustEmbedUnimplementedOrderServiceServer()
+}
+
+func RegisterOrderServiceServer(s grpc.ServiceRegistrar, srv OrderServiceServer) {
+	// If the following call pancis, it indicates UnimplementedOrderServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OrderService_ServiceDesc, srv)
+}
+
+func _OrderService_HasCompletedOrderWithProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HasCompletedOrderWithProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).HasCompletedOrderWithProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_HasCompletedOrderWithProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).HasCompletedOrderWithProduct(ctx, req.(*HasCompletedOrderWithProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetCompletedOrderBaskets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCompletedOrderBasketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetCompletedOrderBaskets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetCompletedOrderBaskets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetCompletedOrderBaskets(ctx, req.(*GetCompletedOrderBasketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OrderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "order.OrderService",
+	HandlerType: (*OrderServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "HasCompletedOrderWithProduct",
+			Handler:    _OrderService_HasCompletedOrderWithProduct_Handler,
+		},
+		{
+			MethodName: "GetCompletedOrderBaskets",
+			Handler:    _OrderService_GetCompletedOrderBaskets_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/order.proto",
+}
@@ -0,0 +1,329 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/product.proto
+
+package productpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ProductService_GetProductsByIDs_FullMethodName      = "/product.ProductService/GetProductsByIDs"
+	ProductService_CheckAvailability_FullMethodName     = "/product.ProductService/CheckAvailability"
+	ProductService_ReserveFlashSaleStock_FullMethodName = "/product.ProductService/ReserveFlashSaleStock"
+	ProductService_ReleaseFlashSaleStock_FullMethodName = "/product.ProductService/ReleaseFlashSaleStock"
+	ProductService_GetWishlistItem_FullMethodName       = "/product.ProductService/GetWishlistItem"
+	ProductService_RemoveWishlistItem_FullMethodName    = "/product.ProductService/RemoveWishlistItem"
+)
+
+// ProductServiceClient is the client API for ProductService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProductServiceClient interface {
+	// GetProductsByIDs returns the products in the order asked, served from the product cache when possible.
+	// Unknown ids are listed in missing_product_ids instead of failing the whole call.
+	GetProductsByIDs(ctx context.Context, in *GetProductsByIDsRequest, opts ...grpc.CallOption) (*GetProductsByIDsResult, error)
+	// CheckAvailability checks every item against the current stock and lifecycle state of the product,
+	// reading the database so the answer is never stale.
+	CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResult, error)
+	// ReserveFlashSaleStock takes the items that are on a running flash sale out of the sale stock, all or nothing.
+	// Items without a running sale are not reserved and are left out of the result.
+	ReserveFlashSaleStock(ctx context.Context, in *ReserveFlashSaleStockRequest, opts ...grpc.CallOption) (*ReserveFlashSaleStockResult, error)
+	// ReleaseFlashSaleStock gives back reservations of a checkout that was not saved.
+	ReleaseFlashSaleStock(ctx context.Context, in *ReleaseFlashSaleStockRequest, opts ...grpc.CallOption) (*ReleaseFlashSaleStockResult, error)
+	// GetWishlistItem returns a wishlist item of the user, NotFound when the user has no such item.
+	GetWishlistItem(ctx context.Context, in *GetWishlistItemRequest, opts ...grpc.CallOption) (*WishlistItem, error)
+	// RemoveWishlistItem removes a wishlist item of the user, NotFound when the user has no such item.
+	RemoveWishlistItem(ctx context.Context, in *RemoveWishlistItemRequest, opts ...grpc.CallOption) (*RemoveWishlistItemResult, error)
+}
+
+type productServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProductServiceClient(cc grpc.ClientConnInterface) ProductServiceClient {
+	return &productServiceClient{cc}
+}
+
+func (c *productServiceClient) GetProductsByIDs(ctx context.Context, in *GetProductsByIDsRequest, opts ...grpc.CallOption) (*GetProductsByIDsResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProductsByIDsResult)
+	err := c.cc.Invoke(ctx, ProductService_GetProductsByIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckAvailabilityResult)
+	err := c.cc.Invoke(ctx, ProductService_CheckAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ReserveFlashSaleStock(ctx context.Context, in *ReserveFlashSaleStockRequest, opts ...grpc.CallOption) (*ReserveFlashSaleStockResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReserveFlashSaleStockResult)
+	err := c.cc.Invoke(ctx, ProductService_ReserveFlashSaleStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ReleaseFlashSaleStock(ctx context.Context, in *ReleaseFlashSaleStockRequest, opts ...grpc.CallOption) (*ReleaseFlashSaleStockResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseFlashSaleStockResult)
+	err := c.cc.Invoke(ctx, ProductService_ReleaseFlashSaleStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetWishlistItem(ctx context.Context, in *GetWishlistItemRequest, opts ...grpc.CallOption) (*WishlistItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WishlistItem)
+	err := c.cc.Invoke(ctx, ProductService_GetWishlistItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) RemoveWishlistItem(ctx context.Context, in *RemoveWishlistItemRequest, opts ...grpc.CallOption) (*RemoveWishlistItemResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveWishlistItemResult)
+	err := c.cc.Invoke(ctx, ProductService_RemoveWishlistItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProductServiceServer is the server API for ProductService service.
+// All implementations must embed UnimplementedProductServiceServer
+// for forward compatibility.
+type ProductServiceServer interface {
+	// GetProductsByIDs returns the products in the order asked, served from the product cache when possible.
+	// Unknown ids are listed in missing_product_ids instead of failing the whole call.
+	GetProductsByIDs(context.Context, *GetProductsByIDsRequest) (*GetProductsByIDsResult, error)
+	// CheckAvailability checks every item against the current stock and lifecycle state of the product,
+	// reading the database so the answer is never stale.
+	CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResult, error)
+	// ReserveFlashSaleStock takes the items that are on a running flash sale out of the sale stock, all or nothing.
+	// Items without a running sale are not reserved and are left out of the result.
+	ReserveFlashSaleStock(context.Context, *ReserveFlashSaleStockRequest) (*ReserveFlashSaleStockResult, error)
+	// ReleaseFlashSaleStock gives back reservations of a checkout that was not saved.
+	ReleaseFlashSaleStock(context.Context, *ReleaseFlashSaleStockRequest) (*ReleaseFlashSaleStockResult, error)
+	// GetWishlistItem returns a wishlist item of the user, NotFound when the user has no such item.
+	GetWishlistItem(context.Context, *GetWishlistItemRequest) (*WishlistItem, error)
+	// RemoveWishlistItem removes a wishlist item of the user, NotFound when the user has no such item.
+	RemoveWishlistItem(context.Context, *RemoveWishlistItemRequest) (*RemoveWishlistItemResult, error)
+	mustEmbedUnimplementedProductServiceServer()
+}
+
+// UnimplementedProductServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProductServiceServer struct{}
+
+func (UnimplementedProductServiceServer) GetProductsByIDs(context.Context, *GetProductsByIDsRequest) (*GetProductsByIDsResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductsByIDs not implemented")
+}
+func (UnimplementedProductServiceServer) CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckAvailability not implemented")
+}
+func (UnimplementedProductServiceServer) ReserveFlashSaleStock(context.Context, *ReserveFlashSaleStockRequest) (*ReserveFlashSaleStockResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveFlashSaleStock not implemented")
+}
+func (UnimplementedProductServiceServer) ReleaseFlashSaleStock(context.Context, *ReleaseFlashSaleStockRequest) (*ReleaseFlashSaleStockResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseFlashSaleStock not implemented")
+}
+func (UnimplementedProductServiceServer) GetWishlistItem(context.Context, *GetWishlistItemRequest) (*WishlistItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWishlistItem not implemented")
+}
+func (UnimplementedProductServiceServer) RemoveWishlistItem(context.Context, *RemoveWishlistItemRequest) (*RemoveWishlistItemResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveWishlistItem not implemented")
+}
+func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
+func (UnimplementedProductServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeProductServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProductServiceServer will
+// result in compilation errors.
+type UnsafeProductServiceServer interface {
+	mustEmbedUnimplementedProductServiceServer()
+}
+
+func RegisterProductServiceServer(s grpc.ServiceRegistrar, srv ProductServiceServer) {
+	// If the following call pancis, it indicates UnimplementedProductServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProductService_ServiceDesc, srv)
+}
+
+func _ProductService_GetProductsByIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductsByIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetProductsByIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetProductsByIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetProductsByIDs(ctx, req.(*GetProductsByIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CheckAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CheckAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_CheckAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CheckAvailability(ctx, req.(*CheckAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ReserveFlashSaleStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveFlashSaleStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ReserveFlashSaleStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ReserveFlashSaleStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ReserveFlashSaleStock(ctx, req.(*ReserveFlashSaleStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ReleaseFlashSaleStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseFlashSaleStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ReleaseFlashSaleStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ReleaseFlashSaleStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ReleaseFlashSaleStock(ctx, req.(*ReleaseFlashSaleStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetWishlistItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWishlistItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetWishlistItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetWishlistItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetWishlistItem(ctx, req.(*GetWishlistItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_RemoveWishlistItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveWishlistItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).RemoveWishlistItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_RemoveWishlistItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).RemoveWishlistItem(ctx, req.(*RemoveWishlistItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProductService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "product.ProductService",
+	HandlerType: (*ProductServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProductsByIDs",
+			Handler:    _ProductService_GetProductsByIDs_Handler,
+		},
+		{
+			MethodName: "CheckAvailability",
+			Handler:    _ProductService_CheckAvailability_Handler,
+		},
+		{
+			MethodName: "ReserveFlashSaleStock",
+			Handler:    _ProductService_ReserveFlashSaleStock_Handler,
+		},
+		{
+			MethodName: "ReleaseFlashSaleStock",
+			Handler:    _ProductService_ReleaseFlashSaleStock_Handler,
+		},
+		{
+			MethodName: "GetWishlistItem",
+			Handler:    _ProductService_GetWishlistItem_Handler,
+		},
+		{
+			MethodName: "RemoveWishlistItem",
+			Handler:    _ProductService_RemoveWishlistItem_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/product.proto",
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	// golang package
+	"context"
+	"fmt"
+	"orderfc/models"
+	"strconv"
+	"strings"
+	"time"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// a cart is a hash of "productID:variantID" to quantity
+	keyUserCart  = "cart:user:%d"
+	keyGuestCart = "cart:guest:%s"
+
+	// userCartTTL and guestCartTTL are counted from the last change of the cart
+	userCartTTL  = 30 * 24 * time.Hour
+	guestCartTTL = 7 * 24 * time.Hour
+)
+
+// mergeCartScript adds every line of the guest cart KEYS[1] to the user cart KEYS[2], capping the quantity
+// at ARGV[1], then deletes the guest cart and renews the user cart for ARGV[2] seconds.
+var mergeCartScript = redis.NewScript(`
+local lines = redis.call('HGETALL', KEYS[1])
+for i = 1, #lines, 2 do
+	local qty = redis.call('HINCRBY', KEYS[2], lines[i], lines[i + 1])
+	if qty > tonumber(ARGV[1]) then
+		redis.call('HSET', KEYS[2], lines[i], ARGV[1])
+	end
+end
+redis.call('DEL', KEYS[1])
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('EXPIRE', KEYS[2], ARGV[2])
+end
+return #lines / 2
+`)
+
+// cartKey cart key by given owner.
+//
+// It returns the redis key of the user cart, or of the guest cart when there is no user.
+func cartKey(owner models.CartOwner) (string, time.Duration) {
+	if owner.UserID != 0 {
+		return fmt.Sprintf(keyUserCart, owner.UserID), userCartTTL
+	}
+
+	return fmt.Sprintf(keyGuestCart, owner.GuestCartID), guestCartTTL
+}
+
+// cartField cart field by given productID, and variantID.
+func cartField(productID int64, variantID int64) string {
+	return fmt.Sprintf("%d:%d", productID, variantID)
+}
+
+// GetCartItems get cart items by given owner.
+//
+// It returns slice of models.CartItem, and nil error when successful.
+// Otherwise, nil value of models.CartItem slice, and error will be returned.
+func (r *OrderRepository) GetCartItems(ctx context.Context, owner models.CartOwner) ([]models.CartItem, error) {
+	key, _ := cartKey(owner)
+	lines, err := r.Redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cartItems := make([]models.CartItem, 0, len(lines))
+	for field, value := range lines {
+		ids := strings.SplitN(field, ":", 2)
+		if len(ids) != 2 {
+			continue
+		}
+
+		productID, err := strconv.ParseInt(ids[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		variantID, err := strconv.ParseInt(ids[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		quantity, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+
+		cartItems = append(cartItems, models.CartItem{
+			ProductID: productID,
+			VariantID: variantID,
+			Quantity:  quantity,
+		})
+	}
+
+	return cartItems, nil
+}
+
+// CountCartItems count cart items by given owner.
+//
+// It returns int64, and nil error when successful.
+// Otherwise, empty int64, and error will be returned.
+func (r *OrderRepository) CountCartItems(ctx context.Context, owner models.CartOwner) (int64, error) {
+	key, _ := cartKey(owner)
+	total, err := r.Redis.HLen(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetCartItemQuantity get cart item quantity by given owner, productID, and variantID.
+//
+// It returns int, and nil error when successful, 0 when the item is not in the cart.
+// Otherwise, empty int, and error will be returned.
+func (r *OrderRepository) GetCartItemQuantity(ctx context.Context, owner models.CartOwner, productID int64, variantID int64) (int, error) {
+	key, _ := cartKey(owner)
+	quantity, err := r.Redis.HGet(ctx, key, cartField(productID, variantID)).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// SetCartItemQuantity set cart item quantity by given owner, productID, variantID, and quantity.
+//
+// Every change renews the TTL of the whole cart.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) SetCartItemQuantity(ctx context.Context, owner models.CartOwner, productID int64, variantID int64, quantity int) error {
+	key, ttl := cartKey(owner)
+
+	pipe := r.Redis.TxPipeline()
+	pipe.HSet(ctx, key, cartField(productID, variantID), quantity)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCartItem delete cart item by given owner, productID, and variantID.
+//
+// It returns true, and nil error when the item was in the cart.
+// Otherwise, false, and error will be returned.
+func (r *OrderRepository) DeleteCartItem(ctx context.Context, owner models.CartOwner, productID int64, variantID int64) (bool, error) {
+	key, ttl := cartKey(owner)
+
+	pipe := r.Redis.TxPipeline()
+	deleted := pipe.HDel(ctx, key, cartField(productID, variantID))
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+
+	return deleted.Val() > 0, nil
+}
+
+// DeleteCart delete cart by given owner.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) DeleteCart(ctx context.Context, owner models.CartOwner) error {
+	key, _ := cartKey(owner)
+	return r.Redis.Del(ctx, key).Err()
+}
+
+// MergeCart merge cart by given guestCartID, userID, and maxQuantity.
+//
+// It returns the number of guest lines merged, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (r *OrderRepository) MergeCart(ctx context.Context, guestCartID string, userID int64, maxQuantity int) (int, error) {
+	guestKey, _ := cartKey(models.CartOwner{GuestCartID: guestCartID})
+	userKey, ttl := cartKey(models.CartOwner{UserID: userID})
+
+	merged, err := mergeCartScript.Run(ctx, r.Redis, []string{guestKey, userKey}, maxQuantity, int(ttl.Seconds())).Int()
+	if err != nil {
+		return 0, err
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	// golang package
+	"context"
+	"orderfc/models"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+// orderOutboxLockKey is the advisory lock of the outbox relay, any constant unique within the database works.
+const orderOutboxLockKey = 7302001
+
+// InsertOrderOutboxTx insert order outbox tx by given tx pointer of gorm.DB, and slice of OrderOutbox.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) InsertOrderOutboxTx(ctx context.Context, tx *gorm.DB, orderOutboxes []models.OrderOutbox) error {
+	if len(orderOutboxes) == 0 {
+		return nil
+	}
+
+	err := tx.WithContext(ctx).Table("order_outbox").Create(&orderOutboxes).Error
+	return err
+}
+
+// TryLockOrderOutboxTx try lock order outbox tx by given tx pointer of gorm.DB.
+//
+// The lock is held until tx ends, so only one instance relays at a time.
+// It returns bool, and nil error when successful.
+// Otherwise, false, and error will be returned.
+func (r *OrderRepository) TryLockOrderOutboxTx(ctx context.Context, tx *gorm.DB) (bool, error) {
+	var locked bool
+	err := tx.WithContext(ctx).Raw("SELECT pg_try_advisory_xact_lock(?)", orderOutboxLockKey).Scan(&locked).Error
+	if err != nil {
+		return false, err
+	}
+
+	return locked, nil
+}
+
+// FindDueOrderOutboxTx find due order outbox tx by given tx pointer of gorm.DB, now, and limit.
+//
+// Only the oldest pending event of every key is returned, and only once its retry is due, so an event
+// never leaves before the events written before it for the same order.
+// It returns slice of models.OrderOutbox, and nil error when successful.
+// Otherwise, nil value of models.OrderOutbox slice, and error will be returned.
+func (r *OrderRepository) FindDueOrderOutboxTx(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]models.OrderOutbox, error) {
+	var orderOutboxes []models.OrderOutbox
+	err := tx.WithContext(ctx).Raw(`SELECT * FROM (
+			SELECT DISTINCT ON (message_key) * FROM order_outbox
+			WHERE publish_time IS NULL
+			ORDER BY message_key, id
+		) AS head
+		WHERE head.next_attempt_time <= ?
+		ORDER BY head.id
+		LIMIT ?`, now, limit).Scan(&orderOutboxes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orderOutboxes, nil
+}
+
+// MarkOrderOutboxPublishedTx mark order outbox published tx by given tx pointer of gorm.DB, orderOutboxIDs, and publishTime.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) MarkOrderOutboxPublishedTx(ctx context.Context, tx *gorm.DB, orderOutboxIDs []int64, publishTime time.Time) error {
+	if len(orderOutboxIDs) == 0 {
+		return nil
+	}
+
+	err := tx.WithContext(ctx).Table("order_outbox").Where("id IN ?", orderOutboxIDs).Update("publish_time", publishTime).Error
+	return err
+}
+
+// MarkOrderOutboxFailedTx mark order outbox failed tx by given tx pointer of gorm.DB, orderOutboxID, lastError, and nextAttemptTime.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) MarkOrderOutboxFailedTx(ctx context.Context, tx *gorm.DB, orderOutboxID int64, lastError string, nextAttemptTime time.Time) error {
+	err := tx.WithContext(ctx).Table("order_outbox").Where("id = ?", orderOutboxID).Updates(map[string]interface{}{
+		"attempts":          gorm.Expr("attempts + 1"),
+		"last_error":        lastError,
+		"next_attempt_time": nextAttemptTime,
+	}).Error
+	return err
+}
+
+// DeletePublishedOrderOutbox delete published order outbox by given before.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) DeletePublishedOrderOutbox(ctx context.Context, before time.Time) error {
+	err := r.Database.WithContext(ctx).Table("order_outbox").Where("publish_time < ?", before).Delete(&models.OrderOutbox{}).Error
+	return err
+}
+
+// GetOrderOutboxBacklog get order outbox backlog.
+//
+// It returns models.OrderOutboxBacklog, and nil error when successful.
+// Otherwise, empty models.OrderOutboxBacklog, and error will be returned.
+func (r *OrderRepository) GetOrderOutboxBacklog(ctx context.Context) (models.OrderOutboxBacklog, error) {
+	var backlog models.OrderOutboxBacklog
+	err := r.Database.WithContext(ctx).Table("order_outbox").
+		Select("COUNT(*) AS pending, MIN(create_time) AS oldest_pending_time").
+		Where("publish_time IS NULL").
+		Scan(&backlog).Error
+	if err != nil {
+		return models.OrderOutboxBacklog{}, err
+	}
+
+	return backlog, nil
+}
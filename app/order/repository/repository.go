@@ -5,12 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"orderfc/infrastructure/constant"
 	"orderfc/models"
 	"time"
 
 	// external package
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InsertOrderDetailTx insert order detail tx by given tx pointer of gorm.DB, and detail pointer of models.OrderDetail.
@@ -78,22 +80,59 @@ func (r *OrderRepository) SaveIdempotencyToken(ctx context.Context, token string
 	return r.Database.WithContext(ctx).Table("order_request_log").Create(&log).Error
 }
 
-// UpdateOrderStatus update order status by given orderID, and status.
+// GetOrderForUpdateTx get order for update tx by given tx pointer of gorm.DB, and orderID.
+//
+// The row stays locked until tx ends, so status changes of one order run one after another.
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (r *OrderRepository) GetOrderForUpdateTx(ctx context.Context, tx *gorm.DB, orderID int64) (models.Order, error) {
+	var result models.Order
+	err := tx.WithContext(ctx).Table("orders").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).Find(&result).Error
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return result, nil
+}
+
+// GetOrderDetailByOrderDetailIDTx get order detail by order detail id tx by given tx pointer of gorm.DB, and orderDetailID.
+//
+// It returns models.OrderDetail, and nil error when successful.
+// Otherwise, empty models.OrderDetail, and error will be returned.
+func (r *OrderRepository) GetOrderDetailByOrderDetailIDTx(ctx context.Context, tx *gorm.DB, orderDetailID int64) (models.OrderDetail, error) {
+	var result models.OrderDetail
+	err := tx.WithContext(ctx).Table("order_detail").Where("id = ?", orderDetailID).Find(&result).Error
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	return result, nil
+}
+
+// UpdateOrderStatusTx update order status tx by given tx pointer of gorm.DB, orderID, and status.
 //
 // It returns nil error when successful.
 // Otherwise, error will be returned.
-func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64, status int) error {
-	err := r.Database.Table("orders").WithContext(ctx).Model(&models.Order{}).
+func (r *OrderRepository) UpdateOrderStatusTx(ctx context.Context, tx *gorm.DB, orderID int64, status int) error {
+	err := tx.WithContext(ctx).Table("orders").Where("id = ?", orderID).
 		Updates(map[string]interface{}{
 			"status":      status,
 			"update_time": time.Now(),
-		}).Where("order_id = ?", orderID).Error
-
-	if err != nil {
-		return err
-	}
+		}).Error
+	return err
+}
 
-	return nil
+// UpdateOrderHistoryTx update order history tx by given tx pointer of gorm.DB, orderDetailID, and orderHistory.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) UpdateOrderHistoryTx(ctx context.Context, tx *gorm.DB, orderDetailID int64, orderHistory string) error {
+	err := tx.WithContext(ctx).Table("order_detail").Where("id = ?", orderDetailID).
+		Updates(map[string]interface{}{
+			"order_history": orderHistory,
+			"update_time":   time.Now(),
+		}).Error
+	return err
 }
 
 // GetOrderInfoByOrderID get order info by order id by given orderID.
@@ -102,7 +141,7 @@ func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64,
 // Otherwise, empty models.Order, and error will be returned.
 func (r *OrderRepository) GetOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error) {
 	var result models.Order
-	err := r.Database.Table("orders").WithContext(ctx).Where("order_id = ?", orderID).Find(&result).Error
+	err := r.Database.Table("orders").WithContext(ctx).Where("id = ?", orderID).Find(&result).Error
 	if err != nil {
 		return models.Order{}, err
 	}
@@ -172,4 +211,66 @@ func (r *OrderRepository) GetOrderHistoriesByUserID(ctx context.Context, param m
 	}
 
 	return response, nil
-}
\ No newline at end of file
+}
+
+// HasCompletedOrderWithProduct has completed order with product by given userID, and productID.
+//
+// It returns bool, and nil error when successful.
+// Otherwise, false, and error will be returned.
+func (r *OrderRepository) HasCompletedOrderWithProduct(ctx context.Context, userID int64, productID int64) (bool, error) {
+	// order_detail.products holds the checkout items as a json array
+	productFilter := fmt.Sprintf(`[{"product_id": %d}]`, productID)
+
+	var total int64
+	err := r.Database.WithContext(ctx).
+		Table("orders AS o").
+		Joins("JOIN order_detail d ON o.order_detail_id = d.id").
+		Where("o.user_id = ? AND o.status IN ?", userID, constant.OrderStatusesCompleted).
+		Where("d.products::jsonb @> ?::jsonb", productFilter).
+		Count(&total).Error
+	if err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// GetCompletedOrderBaskets get completed order baskets by given afterOrderID, and limit.
+//
+// It returns slice of models.OrderBasket, and nil error when successful.
+// Otherwise, nil value of models.OrderBasket slice, and error will be returned.
+func (r *OrderRepository) GetCompletedOrderBaskets(ctx context.Context, afterOrderID int64, limit int) ([]models.OrderBasket, error) {
+	var results []models.OrderJoinResult
+	err := r.Database.WithContext(ctx).
+		Table("orders AS o").
+		Select("o.id, d.products").
+		Joins("JOIN order_detail d ON o.order_detail_id = d.id").
+		Where("o.status IN ? AND o.id > ?", constant.OrderStatusesCompleted, afterOrderID).
+		Order("o.id").
+		Limit(limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	baskets := make([]models.OrderBasket, 0, len(results))
+	for _, row := range results {
+		var products []models.CheckoutItem
+		_ = json.Unmarshal([]byte(row.Products), &products)
+
+		// a product bought in two variants is still one product of the basket
+		seen := make(map[int64]bool, len(products))
+		basket := models.OrderBasket{
+			OrderID: row.ID,
+		}
+		for _, product := range products {
+			if !seen[product.ProductID] {
+				seen[product.ProductID] = true
+				basket.ProductIDs = append(basket.ProductIDs, product.ProductID)
+			}
+		}
+		baskets = append(baskets, basket)
+	}
+
+	return baskets, nil
+}
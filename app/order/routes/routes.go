@@ -0,0 +1,41 @@
+package routes
+
+import (
+	// golang package
+	"expvar"
+	"orderfc/cmd/order/handler"
+
+	// external package
+	"github.com/PorcoGalliard/eCommerce-Microservice/middleware"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes setup routes by given router pointer of gin.Engine, orderHandler, and JWTSecret.
+func SetupRoutes(router *gin.Engine, orderHandler handler.OrderHandler, JWTSecret string) {
+	// Cart API, guests carry their cart in the X-Cart-ID header
+	cart := router.Group("/v1/cart")
+	cart.Use(middleware.OptionalAuthMiddleware(JWTSecret))
+	cart.GET("", orderHandler.GetCart)
+	cart.DELETE("", orderHandler.ClearCart)
+	cart.POST("/items", orderHandler.AddCartItem)
+	cart.PUT("/items/:product_id", orderHandler.UpdateCartItem)
+	cart.DELETE("/items/:product_id", orderHandler.RemoveCartItem)
+
+	// Customer API
+	customer := router.Group("/v1")
+	customer.Use(middleware.AuthMiddleware(JWTSecret))
+	customer.POST("/checkout", orderHandler.Checkout)
+	customer.POST("/cart/merge", orderHandler.MergeCart)
+	customer.POST("/cart/items/from_wishlist", orderHandler.MoveWishlistItemToCart)
+	customer.GET("/order_history", orderHandler.GetOrderHistory)
+	customer.GET("/orders/:id", orderHandler.GetOrder)
+	customer.POST("/orders/:id/cancel", orderHandler.CancelOrder)
+
+	// Staff API
+	staff := router.Group("/v1")
+	staff.Use(middleware.AuthMiddleware(JWTSecret), middleware.RoleMiddleware(models.RoleAdmin, models.RoleStaff))
+	staff.PUT("/orders/:id/status", orderHandler.UpdateOrderStatus)
+	// outbox backlog metrics, expvar also publishes the command line and memstats
+	staff.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+}
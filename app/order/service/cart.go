@@ -0,0 +1,122 @@
+package service
+
+import (
+	// golang package
+	"context"
+	"orderfc/models"
+)
+
+// GetCartItems get cart items by given owner.
+//
+// It returns slice of models.CartItem, and nil error when successful.
+// Otherwise, nil value of models.CartItem slice, and error will be returned.
+func (s *OrderService) GetCartItems(ctx context.Context, owner models.CartOwner) ([]models.CartItem, error) {
+	cartItems, err := s.OrderRepository.GetCartItems(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return cartItems, nil
+}
+
+// CountCartItems count cart items by given owner.
+//
+// It returns int64, and nil error when successful.
+// Otherwise, empty int64, and error will be returned.
+func (s *OrderService) CountCartItems(ctx context.Context, owner models.CartOwner) (int64, error) {
+	total, err := s.OrderRepository.CountCartItems(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetCartItemQuantity get cart item quantity by given owner, productID, and variantID.
+//
+// It returns int, and nil error when successful.
+// Otherwise, empty int, and error will be returned.
+func (s *OrderService) GetCartItemQuantity(ctx context.Context, owner models.CartOwner, productID int64, variantID int64) (int, error) {
+	quantity, err := s.OrderRepository.GetCartItemQuantity(ctx, owner, productID, variantID)
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// SetCartItemQuantity set cart item quantity by given owner, productID, variantID, and quantity.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) SetCartItemQuantity(ctx context.Context, owner models.CartOwner, productID int64, variantID int64, quantity int) error {
+	return s.OrderRepository.SetCartItemQuantity(ctx, owner, productID, variantID, quantity)
+}
+
+// DeleteCartItem delete cart item by given owner, productID, and variantID.
+//
+// It returns bool, and nil error when successful.
+// Otherwise, false, and error will be returned.
+func (s *OrderService) DeleteCartItem(ctx context.Context, owner models.CartOwner, productID int64, variantID int64) (bool, error) {
+	deleted, err := s.OrderRepository.DeleteCartItem(ctx, owner, productID, variantID)
+	if err != nil {
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// DeleteCart delete cart by given owner.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) DeleteCart(ctx context.Context, owner models.CartOwner) error {
+	return s.OrderRepository.DeleteCart(ctx, owner)
+}
+
+// MergeCart merge cart by given guestCartID, userID, and maxQuantity.
+//
+// It returns int, and nil error when successful.
+// Otherwise, empty int, and error will be returned.
+func (s *OrderService) MergeCart(ctx context.Context, guestCartID string, userID int64, maxQuantity int) (int, error) {
+	merged, err := s.OrderRepository.MergeCart(ctx, guestCartID, userID, maxQuantity)
+	if err != nil {
+		return 0, err
+	}
+
+	return merged, nil
+}
+
+// GetProducts get products by given productIDs.
+//
+// It returns map of product id to models.ProductInfo, and nil error when successful.
+// Otherwise, nil map, and error will be returned.
+func (s *OrderService) GetProducts(ctx context.Context, productIDs []int64) (map[int64]models.ProductInfo, error) {
+	products, err := s.ProductClient.GetProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// GetWishlistItem get wishlist item by given userID, and wishlistItemID.
+//
+// It returns models.WishlistItem, and nil error when successful.
+// Otherwise, empty models.WishlistItem, and error will be returned.
+func (s *OrderService) GetWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) (models.WishlistItem, error) {
+	wishlistItem, err := s.ProductClient.GetWishlistItem(ctx, userID, wishlistItemID)
+	if err != nil {
+		return models.WishlistItem{}, err
+	}
+
+	return wishlistItem, nil
+}
+
+// RemoveWishlistItem remove wishlist item by given userID, and wishlistItemID.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) RemoveWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) error {
+	return s.ProductClient.RemoveWishlistItem(ctx, userID, wishlistItemID)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	// golang package
+	"context"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+const (
+	// orderOutboxRetryBase is the wait after the first failure, it doubles with every further failure
+	orderOutboxRetryBase = time.Second
+	orderOutboxRetryMax  = 5 * time.Minute
+)
+
+// OrderOutboxRelayResult is what one relay round did.
+type OrderOutboxRelayResult struct {
+	Published int
+	Failed    int
+}
+
+// RelayOrderOutbox relay order outbox by given limit, and publish.
+//
+// One round publishes the due events of at most limit orders. An event is only marked published once
+// publish reported it written, so a crash in between sends it again and never loses it. A failed event
+// is retried with a growing wait and holds back the later events of its order until it went out.
+// It returns OrderOutboxRelayResult, and nil error when successful.
+// Otherwise, empty OrderOutboxRelayResult, and error will be returned.
+func (s *OrderService) RelayOrderOutbox(ctx context.Context, limit int, publish func(ctx context.Context, orderOutboxes []models.OrderOutbox) []error) (OrderOutboxRelayResult, error) {
+	var result OrderOutboxRelayResult
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		locked, err := s.OrderRepository.TryLockOrderOutboxTx(ctx, tx)
+		if err != nil || !locked {
+			return err
+		}
+
+		now := time.Now()
+		orderOutboxes, err := s.OrderRepository.FindDueOrderOutboxTx(ctx, tx, now, limit)
+		if err != nil || len(orderOutboxes) == 0 {
+			return err
+		}
+
+		errs := publish(ctx, orderOutboxes)
+
+		var publishedIDs []int64
+		for index, orderOutbox := range orderOutboxes {
+			if errs[index] == nil {
+				publishedIDs = append(publishedIDs, orderOutbox.ID)
+				continue
+			}
+
+			log.Logger.Printf("[OUTBOX] Failed publish %s event #%d of %s, attempt %d: %v",
+				orderOutbox.Topic, orderOutbox.ID, orderOutbox.MessageKey, orderOutbox.Attempts+1, errs[index])
+
+			err = s.OrderRepository.MarkOrderOutboxFailedTx(ctx, tx, orderOutbox.ID, errs[index].Error(), now.Add(orderOutboxRetryDelay(orderOutbox.Attempts)))
+			if err != nil {
+				return err
+			}
+			result.Failed++
+		}
+
+		err = s.OrderRepository.MarkOrderOutboxPublishedTx(ctx, tx, publishedIDs, now)
+		if err != nil {
+			return err
+		}
+
+		result.Published = len(publishedIDs)
+		return nil
+	})
+
+	if err != nil {
+		return OrderOutboxRelayResult{}, err
+	}
+
+	return result, nil
+}
+
+// DeletePublishedOrderOutbox delete published order outbox by given before.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) DeletePublishedOrderOutbox(ctx context.Context, before time.Time) error {
+	err := s.OrderRepository.DeletePublishedOrderOutbox(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetOrderOutboxBacklog get order outbox backlog.
+//
+// It returns models.OrderOutboxBacklog, and nil error when successful.
+// Otherwise, empty models.OrderOutboxBacklog, and error will be returned.
+func (s *OrderService) GetOrderOutboxBacklog(ctx context.Context) (models.OrderOutboxBacklog, error) {
+	backlog, err := s.OrderRepository.GetOrderOutboxBacklog(ctx)
+	if err != nil {
+		return models.OrderOutboxBacklog{}, err
+	}
+
+	return backlog, nil
+}
+
+// orderOutboxRetryDelay order outbox retry delay by given attempts.
+//
+// It returns time.Duration.
+func orderOutboxRetryDelay(attempts int) time.Duration {
+	delay := orderOutboxRetryBase
+	for i := 0; i < attempts && delay < orderOutboxRetryMax; i++ {
+		delay *= 2
+	}
+
+	if delay > orderOutboxRetryMax {
+		return orderOutboxRetryMax
+	}
+
+	return delay
+}
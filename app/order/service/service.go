@@ -4,6 +4,7 @@ import (
 	// golang package
 	"context"
 	"orderfc/cmd/order/repository"
+	"orderfc/grpc/client"
 	"orderfc/models"
 
 	// external package
@@ -12,15 +13,19 @@ import (
 
 type OrderService struct {
 	OrderRepository repository.OrderRepository
+	ProductClient   client.ProductClient
+	PaymentClient   client.PaymentClient
 }
 
-// NewOrderService new orderservice by given OrderRepository.
+// NewOrderService new orderservice by given OrderRepository, ProductClient, and PaymentClient.
 //
 // It returns pointer of OrderService when successful.
 // Otherwise, nil pointer of OrderService will be returned.
-func NewOrderService(orderRepository repository.OrderRepository) *OrderService {
+func NewOrderService(orderRepository repository.OrderRepository, productClient client.ProductClient, paymentClient client.PaymentClient) *OrderService {
 	return &OrderService{
 		OrderRepository: orderRepository,
+		ProductClient:   productClient,
+		PaymentClient:   paymentClient,
 	}
 }
 
@@ -76,24 +81,13 @@ func (s *OrderService) GetOrderDetailByOrderDetailID(ctx context.Context, orderD
 	return orderDetail, nil
 }
 
-// UpdateOrderStatus update order status by given orderID, and status.
-//
-// It returns nil error when successful.
-// Otherwise, error will be returned.
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID int64, status int) error {
-	err := s.OrderRepository.UpdateOrderStatus(ctx, orderID, status)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// SaveOrderWithDetail save order with detail by given order pointer of models.Order, and detail pointer of models.OrderDetail.
+// SaveOrderWithDetail save order with detail by given order pointer of models.Order, detail pointer of models.OrderDetail, and buildEvents.
 //
+// The events buildEvents returns for the new order id are written to the outbox in the same transaction,
+// so they are published exactly when the order exists.
 // It returns int64, and nil error when successful.
 // Otherwise, empty int64, and error will be returned.
-func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *models.Order, orderDetail *models.OrderDetail) (int64, error) {
+func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *models.Order, orderDetail *models.OrderDetail, buildEvents func(orderID int64) ([]models.OrderOutbox, error)) (int64, error) {
 	var orderID int64
 
 	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
@@ -109,6 +103,16 @@ func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *model
 			return err
 		}
 
+		orderOutboxes, err := buildEvents(order.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.OrderRepository.InsertOrderOutboxTx(ctx, tx, orderOutboxes)
+		if err != nil {
+			return err
+		}
+
 		orderID = order.ID
 		return nil
 	})
@@ -130,4 +134,95 @@ func (s *OrderService) GetOrderHistoriesByUserID(ctx context.Context, param mode
 		return nil, err
 	}
 	return orderHistories, nil
-}
\ No newline at end of file
+}
+
+// HasCompletedOrderWithProduct has completed order with product by given userID, and productID.
+//
+// It returns bool, and nil error when successful.
+// Otherwise, false, and error will be returned.
+func (s *OrderService) HasCompletedOrderWithProduct(ctx context.Context, userID int64, productID int64) (bool, error) {
+	hasCompletedOrder, err := s.OrderRepository.HasCompletedOrderWithProduct(ctx, userID, productID)
+	if err != nil {
+		return false, err
+	}
+
+	return hasCompletedOrder, nil
+}
+
+// GetCompletedOrderBaskets get completed order baskets by given afterOrderID, and limit.
+//
+// It returns slice of models.OrderBasket, and nil error when successful.
+// Otherwise, nil value of models.OrderBasket slice, and error will be returned.
+func (s *OrderService) GetCompletedOrderBaskets(ctx context.Context, afterOrderID int64, limit int) ([]models.OrderBasket, error) {
+	baskets, err := s.OrderRepository.GetCompletedOrderBaskets(ctx, afterOrderID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return baskets, nil
+}
+
+// CheckAvailability check availability by given items slice of CheckoutItem.
+//
+// It returns slice of models.ItemAvailability, and nil error when successful.
+// Otherwise, nil value of models.ItemAvailability slice, and error will be returned.
+func (s *OrderService) CheckAvailability(ctx context.Context, items []models.CheckoutItem) ([]models.ItemAvailability, error) {
+	availabilities, err := s.ProductClient.CheckAvailability(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return availabilities, nil
+}
+
+// GetPaymentByOrderID get payment by order id by given orderID.
+//
+// It returns models.PaymentInfo, and nil error when successful.
+// Otherwise, empty models.PaymentInfo, and error will be returned.
+func (s *OrderService) GetPaymentByOrderID(ctx context.Context, orderID int64) (models.PaymentInfo, error) {
+	paymentInfo, err := s.PaymentClient.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return models.PaymentInfo{}, err
+	}
+
+	return paymentInfo, nil
+}
+
+// GetBundleItems get bundle items by given productIDs.
+//
+// It returns map of product id to slice of models.BundleItem, and nil error when successful.
+// Otherwise, nil map, and error will be returned.
+func (s *OrderService) GetBundleItems(ctx context.Context, productIDs []int64) (map[int64][]models.BundleItem, error) {
+	bundleItems, err := s.ProductClient.GetBundleItems(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return bundleItems, nil
+}
+
+// ReserveFlashSaleStock reserve flash sale stock by given userID, and items slice of CheckoutItem.
+//
+// It returns slice of models.FlashSaleReservation, and nil error when successful.
+// Otherwise, nil value of models.FlashSaleReservation slice, and error will be returned.
+func (s *OrderService) ReserveFlashSaleStock(ctx context.Context, userID int64, items []models.CheckoutItem) ([]models.FlashSaleReservation, error) {
+	reservations, err := s.ProductClient.ReserveFlashSaleStock(ctx, userID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// ReleaseFlashSaleStock release flash sale stock by given userID, and reservations slice of FlashSaleReservation.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) ReleaseFlashSaleStock(ctx context.Context, userID int64, reservations []models.FlashSaleReservation) error {
+	err := s.ProductClient.ReleaseFlashSaleStock(ctx, userID, reservations)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orderfc/infrastructure/constant"
+	"orderfc/kafka"
+	"orderfc/models"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound                = errors.New("order not found")
+	ErrInvalidOrderStatusTransition = errors.New("invalid order status transition")
+)
+
+// ChangeOrderStatus change order status by given OrderStatusChange, and buildEvents.
+//
+// The order is locked, checked against the state machine, moved to the new status and the change is
+// appended to its history, all in one transaction together with an order.status_changed event and the
+// events buildEvents returns for the order. buildEvents may be nil. Asking for the status the order
+// already has changes nothing, so a redelivered event is harmless.
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (s *OrderService) ChangeOrderStatus(ctx context.Context, change models.OrderStatusChange, buildEvents func(order models.Order, orderDetail models.OrderDetail) ([]models.OrderOutbox, error)) (models.Order, error) {
+	var result models.Order
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		order, err := s.OrderRepository.GetOrderForUpdateTx(ctx, tx, change.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if order.ID == 0 {
+			return ErrOrderNotFound
+		}
+
+		if order.Status == change.Status {
+			result = order
+			return nil
+		}
+
+		if !constant.CanChangeOrderStatus(order.Status, change.Status) {
+			return fmt.Errorf("%w: order %d is %s and cannot become %s", ErrInvalidOrderStatusTransition,
+				order.ID, constant.OrderStatusTranslated[order.Status], constant.OrderStatusTranslated[change.Status])
+		}
+
+		orderDetail, err := s.OrderRepository.GetOrderDetailByOrderDetailIDTx(ctx, tx, order.OrderDetailID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var history []models.StatusHistory
+		_ = json.Unmarshal([]byte(orderDetail.OrderHistory), &history)
+		history = append(history, models.StatusHistory{
+			Status:    constant.OrderStatusTranslated[change.Status],
+			Actor:     change.Actor,
+			ActorID:   change.ActorID,
+			Reason:    change.Reason,
+			Timestamp: now,
+		})
+
+		historyJSON, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+
+		err = s.OrderRepository.UpdateOrderHistoryTx(ctx, tx, orderDetail.ID, string(historyJSON))
+		if err != nil {
+			return err
+		}
+
+		err = s.OrderRepository.UpdateOrderStatusTx(ctx, tx, order.ID, change.Status)
+		if err != nil {
+			return err
+		}
+
+		statusChanged, err := kafka.NewOrderOutbox(kafka.TopicOrderStatusChanged, order.ID, models.OrderStatusChangedEvent{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			FromStatus: constant.OrderStatusTranslated[order.Status],
+			ToStatus:   constant.OrderStatusTranslated[change.Status],
+			Actor:      change.Actor,
+			ActorID:    change.ActorID,
+			Reason:     change.Reason,
+			EventTime:  now,
+		})
+		if err != nil {
+			return err
+		}
+
+		orderOutboxes := []models.OrderOutbox{statusChanged}
+		if buildEvents != nil {
+			events, err := buildEvents(order, orderDetail)
+			if err != nil {
+				return err
+			}
+			orderOutboxes = append(orderOutboxes, events...)
+		}
+
+		err = s.OrderRepository.InsertOrderOutboxTx(ctx, tx, orderOutboxes)
+		if err != nil {
+			return err
+		}
+
+		order.Status = change.Status
+		result = order
+		return nil
+	})
+
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return result, nil
+}
+
+// StockRollbackEvents stock rollback events by given order, and orderDetail.
+//
+// It gives the product service back the stock the checkout of the order took, meant as the buildEvents
+// of ChangeOrderStatus when an order is cancelled.
+// It returns slice of models.OrderOutbox, and nil error when successful.
+// Otherwise, nil value of models.OrderOutbox slice, and error will be returned.
+func StockRollbackEvents(order models.Order, orderDetail models.OrderDetail) ([]models.OrderOutbox, error) {
+	var products []models.CheckoutItem
+	err := json.Unmarshal([]byte(orderDetail.Products), &products)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.ProductItem, 0, len(products))
+	for _, product := range products {
+		items = append(items, product.StockItems()...)
+	}
+
+	stockRollback, err := kafka.NewOrderOutbox(kafka.TopicStockRollback, order.ID, models.ProductStockUpdateEvent{
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		Products:  items,
+		EventTime: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []models.OrderOutbox{stockRollback}, nil
+}
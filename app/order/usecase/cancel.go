@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"orderfc/cmd/order/service"
+	"orderfc/infrastructure/constant"
+	"orderfc/kafka"
+	"orderfc/models"
+	"strings"
+	"time"
+)
+
+var ErrOrderNotCancellable = errors.New("order cannot be cancelled")
+
+// CancelOrder cancel order by given orderID, userID, and CancelOrderRequest.
+//
+// An order that is not paid yet is cancelled. A paid order that has not shipped is refunded, the state
+// machine does not cancel paid orders. Either way the stock goes back to the product service and the
+// payment service is told to expire the invoice or refund it, through the outbox in the transaction of
+// the status change. Orders of other users are reported as not found.
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (uc *OrderUsecase) CancelOrder(ctx context.Context, orderID int64, userID int64, param models.CancelOrderRequest) (models.Order, error) {
+	order, err := uc.OrderService.GetOrderInfoByOrderID(ctx, orderID)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if order.ID == 0 || order.UserID != userID {
+		return models.Order{}, service.ErrOrderNotFound
+	}
+
+	var status int
+	switch order.Status {
+	case constant.OrderStatusCreated:
+		status = constant.OrderStatusCancelled
+	case constant.OrderStatusPaid, constant.OrderStatusProcessing:
+		status = constant.OrderStatusRefunded
+	default:
+		return models.Order{}, fmt.Errorf("%w: order is %s", ErrOrderNotCancellable, constant.OrderStatusTranslated[order.Status])
+	}
+
+	reason := strings.TrimSpace(param.Reason)
+	if reason == "" {
+		reason = "cancelled by customer"
+	}
+
+	order, err = uc.OrderService.ChangeOrderStatus(ctx, models.OrderStatusChange{
+		OrderID: orderID,
+		Status:  status,
+		Actor:   constant.OrderActorUser,
+		ActorID: userID,
+		Reason:  reason,
+	}, orderCancelledEvents(status, reason))
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+// orderCancelledEvents order cancelled events by given status, and reason.
+//
+// It builds the buildEvents of ChangeOrderStatus for an order that becomes cancelled or refunded. The stock
+// goes back to the product service, and the payment service is told to expire the invoice, or to refund
+// the payment when status is refunded.
+func orderCancelledEvents(status int, reason string) func(order models.Order, orderDetail models.OrderDetail) ([]models.OrderOutbox, error) {
+	return func(order models.Order, orderDetail models.OrderDetail) ([]models.OrderOutbox, error) {
+		events, err := service.StockRollbackEvents(order, orderDetail)
+		if err != nil {
+			return nil, err
+		}
+
+		orderCancelled, err := kafka.NewOrderOutbox(kafka.TopicOrderCancelled, order.ID, models.OrderCancelledEvent{
+			OrderID:   order.ID,
+			UserID:    order.UserID,
+			Amount:    order.Amount,
+			Refund:    status == constant.OrderStatusRefunded,
+			Reason:    reason,
+			EventTime: time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return append(events, orderCancelled), nil
+	}
+}
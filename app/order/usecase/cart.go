@@ -0,0 +1,306 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"sort"
+)
+
+const (
+	// maxCartItemQuantity caps the quantity of one cart line
+	maxCartItemQuantity = 99
+	// maxCartItems caps the number of lines in one cart
+	maxCartItems = 50
+)
+
+var (
+	ErrCartItemNotFound     = errors.New("cart item not found")
+	ErrInvalidCartItem      = errors.New("invalid cart item")
+	ErrEmptyCart            = errors.New("cart is empty")
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+)
+
+// GetCart get cart by given owner.
+//
+// Names and prices are the current ones of the product service, lines that can no longer be bought are
+// kept but marked and left out of the totals.
+// It returns pointer of models.Cart, and nil error when successful.
+// Otherwise, nil pointer of models.Cart, and error will be returned.
+func (uc *OrderUsecase) GetCart(ctx context.Context, owner models.CartOwner) (*models.Cart, error) {
+	cart := &models.Cart{
+		Items: []models.CartItem{},
+	}
+	if owner.UserID == 0 {
+		cart.CartID = owner.GuestCartID
+	}
+
+	if owner.UserID == 0 && owner.GuestCartID == "" {
+		return cart, nil
+	}
+
+	cartItems, err := uc.OrderService.GetCartItems(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cartItems) == 0 {
+		return cart, nil
+	}
+
+	productIDs := make([]int64, 0, len(cartItems))
+	for _, cartItem := range cartItems {
+		productIDs = append(productIDs, cartItem.ProductID)
+	}
+
+	products, err := uc.OrderService.GetProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(cartItems, func(i, j int) bool {
+		if cartItems[i].ProductID != cartItems[j].ProductID {
+			return cartItems[i].ProductID < cartItems[j].ProductID
+		}
+		return cartItems[i].VariantID < cartItems[j].VariantID
+	})
+
+	for _, cartItem := range cartItems {
+		product, ok := products[cartItem.ProductID]
+		if ok {
+			cartItem.Name = product.Name
+			cartItem.Price, ok = product.UnitPrice(cartItem.VariantID)
+		}
+
+		cartItem.Purchasable = ok && product.Purchasable
+		if cartItem.Purchasable {
+			cart.TotalQty += cartItem.Quantity
+			cart.TotalAmount += float64(cartItem.Quantity) * cartItem.Price
+		}
+		cart.Items = append(cart.Items, cartItem)
+	}
+
+	return cart, nil
+}
+
+// AddCartItem add cart item by given owner, and param pointer of models.CartItemRequest.
+//
+// Adding an item already in the cart raises its quantity.
+// It returns pointer of models.Cart, and nil error when successful.
+// Otherwise, nil pointer of models.Cart, and error will be returned.
+func (uc *OrderUsecase) AddCartItem(ctx context.Context, owner models.CartOwner, param *models.CartItemRequest) (*models.Cart, error) {
+	if param.Quantity <= 0 || param.Quantity > maxCartItemQuantity {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidCartItem, maxCartItemQuantity)
+	}
+
+	if err := uc.checkCartProduct(ctx, param.ProductID, param.VariantID); err != nil {
+		return nil, err
+	}
+
+	quantity, err := uc.OrderService.GetCartItemQuantity(ctx, owner, param.ProductID, param.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quantity == 0 {
+		total, err := uc.OrderService.CountCartItems(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+
+		if total >= maxCartItems {
+			return nil, fmt.Errorf("%w: a cart holds at most %d items", ErrInvalidCartItem, maxCartItems)
+		}
+	}
+
+	if quantity+param.Quantity > maxCartItemQuantity {
+		return nil, fmt.Errorf("%w: quantity must not exceed %d", ErrInvalidCartItem, maxCartItemQuantity)
+	}
+
+	err = uc.OrderService.SetCartItemQuantity(ctx, owner, param.ProductID, param.VariantID, quantity+param.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.GetCart(ctx, owner)
+}
+
+// UpdateCartItemQuantity update cart item quantity by given owner, productID, and param pointer of models.CartQuantityRequest.
+//
+// A quantity of 0 removes the item.
+// It returns pointer of models.Cart, and nil error when successful.
+// Otherwise, nil pointer of models.Cart, and error will be returned.
+func (uc *OrderUsecase) UpdateCartItemQuantity(ctx context.Context, owner models.CartOwner, productID int64, param *models.CartQuantityRequest) (*models.Cart, error) {
+	if param.Quantity < 0 || param.Quantity > maxCartItemQuantity {
+		return nil, fmt.Errorf("%w: quantity must be between 0 and %d", ErrInvalidCartItem, maxCartItemQuantity)
+	}
+
+	if param.Quantity == 0 {
+		if err := uc.RemoveCartItem(ctx, owner, productID, param.VariantID); err != nil {
+			return nil, err
+		}
+
+		return uc.GetCart(ctx, owner)
+	}
+
+	quantity, err := uc.OrderService.GetCartItemQuantity(ctx, owner, productID, param.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quantity == 0 {
+		return nil, ErrCartItemNotFound
+	}
+
+	err = uc.OrderService.SetCartItemQuantity(ctx, owner, productID, param.VariantID, param.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.GetCart(ctx, owner)
+}
+
+// RemoveCartItem remove cart item by given owner, productID, and variantID.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) RemoveCartItem(ctx context.Context, owner models.CartOwner, productID int64, variantID int64) error {
+	deleted, err := uc.OrderService.DeleteCartItem(ctx, owner, productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrCartItemNotFound
+	}
+
+	return nil
+}
+
+// ClearCart clear cart by given owner.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) ClearCart(ctx context.Context, owner models.CartOwner) error {
+	return uc.OrderService.DeleteCart(ctx, owner)
+}
+
+// MergeGuestCart merge guest cart by given guestCartID, and userID.
+//
+// It is called once the guest logs in, the guest cart is added to the user cart and deleted.
+// It returns pointer of models.Cart, and nil error when successful.
+// Otherwise, nil pointer of models.Cart, and error will be returned.
+func (uc *OrderUsecase) MergeGuestCart(ctx context.Context, guestCartID string, userID int64) (*models.Cart, error) {
+	if guestCartID == "" || userID == 0 {
+		return nil, fmt.Errorf("%w: guest cart id and user id are required", ErrInvalidCartItem)
+	}
+
+	if _, err := uc.OrderService.MergeCart(ctx, guestCartID, userID, maxCartItemQuantity); err != nil {
+		return nil, err
+	}
+
+	return uc.GetCart(ctx, models.CartOwner{UserID: userID})
+}
+
+// MoveWishlistItemToCart move wishlist item to cart by given userID, and param pointer of models.MoveWishlistItemRequest.
+//
+// The item goes into the cart the way AddCartItem adds it and only then leaves the wishlist. When the
+// removal fails the item stays on the wishlist as well, which is logged rather than undoing the add.
+// It returns pointer of models.Cart, and nil error when successful.
+// Otherwise, nil pointer of models.Cart, and error will be returned.
+func (uc *OrderUsecase) MoveWishlistItemToCart(ctx context.Context, userID int64, param *models.MoveWishlistItemRequest) (*models.Cart, error) {
+	wishlistItem, err := uc.OrderService.GetWishlistItem(ctx, userID, param.WishlistItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlistItem.ID == 0 {
+		return nil, ErrWishlistItemNotFound
+	}
+
+	quantity := param.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	cart, err := uc.AddCartItem(ctx, models.CartOwner{UserID: userID}, &models.CartItemRequest{
+		ProductID: wishlistItem.ProductID,
+		VariantID: wishlistItem.VariantID,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uc.OrderService.RemoveWishlistItem(ctx, userID, wishlistItem.ID); err != nil {
+		log.Logger.Printf("Failed remove wishlist item %d of user %d: %v", wishlistItem.ID, userID, err)
+	}
+
+	return cart, nil
+}
+
+// checkCartProduct check cart product by given productID, and variantID.
+//
+// It returns nil error when the product can be bought as variantID.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) checkCartProduct(ctx context.Context, productID int64, variantID int64) error {
+	products, err := uc.OrderService.GetProducts(ctx, []int64{productID})
+	if err != nil {
+		return err
+	}
+
+	product, ok := products[productID]
+	if !ok || !product.Purchasable {
+		return fmt.Errorf("%w: product %d is not available", ErrInvalidCartItem, productID)
+	}
+
+	if _, ok = product.UnitPrice(variantID); !ok {
+		return fmt.Errorf("%w: product %d is not sold as variant %d", ErrInvalidCartItem, productID, variantID)
+	}
+
+	return nil
+}
+
+// cartCheckoutItems cart checkout items by given userID.
+//
+// The items are priced by the product service, whatever the cart showed before.
+// It returns slice of models.CheckoutItem, and nil error when successful.
+// Otherwise, nil value of models.CheckoutItem slice, and error will be returned.
+func (uc *OrderUsecase) cartCheckoutItems(ctx context.Context, userID int64) ([]models.CheckoutItem, error) {
+	cart, err := uc.GetCart(ctx, models.CartOwner{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	items := make([]models.CheckoutItem, 0, len(cart.Items))
+	for _, cartItem := range cart.Items {
+		if !cartItem.Purchasable {
+			return nil, fmt.Errorf("%w: product %d is no longer available, remove it from the cart", ErrInvalidCartItem, cartItem.ProductID)
+		}
+
+		items = append(items, models.CheckoutItem{
+			ProductID: cartItem.ProductID,
+			VariantID: cartItem.VariantID,
+			Quantity:  cartItem.Quantity,
+			Price:     cartItem.Price,
+		})
+	}
+
+	return items, nil
+}
+
+// clearCheckedOutCart clear checked out cart by given userID.
+//
+// It only logs a failure, the order is already saved.
+func (uc *OrderUsecase) clearCheckedOutCart(ctx context.Context, userID int64) {
+	if err := uc.OrderService.DeleteCart(ctx, models.CartOwner{UserID: userID}); err != nil {
+		log.Logger.Printf("Failed clear cart of user %d: %v", userID, err)
+	}
+}
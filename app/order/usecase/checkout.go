@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"orderfc/models"
+	"strings"
+)
+
+// priceTolerance is how far apart two prices may be and still count as the same, against float rounding
+const priceTolerance = 0.005
+
+var (
+	ErrItemUnavailable = errors.New("item is not available")
+	ErrPriceChanged    = errors.New("price changed")
+)
+
+// verifyItems verify items by given CheckoutRequest.
+//
+// Names, prices and stock come from the database of the product service, the client only picks items and
+// quantities. Items reserved from a flash sale keep their sale price, the reservation already checked them
+// against the sale stock. A line whose price changed rejects the checkout unless the client accepts price
+// changes, the line is sold at the current price then.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) verifyItems(ctx context.Context, param *models.CheckoutRequest) error {
+	availabilities, err := uc.OrderService.CheckAvailability(ctx, param.Items)
+	if err != nil {
+		return err
+	}
+
+	if len(availabilities) != len(param.Items) {
+		return fmt.Errorf("product service checked %d of %d items", len(availabilities), len(param.Items))
+	}
+
+	var priceChanges []string
+	for index, item := range param.Items {
+		availability := availabilities[index]
+		param.Items[index].Name = availability.Name
+		if item.FlashSaleID != 0 {
+			continue
+		}
+
+		if !availability.Available {
+			return fmt.Errorf("%w: product %d variant %d: %s", ErrItemUnavailable, item.ProductID, item.VariantID, availability.Reason)
+		}
+
+		if math.Abs(item.Price-availability.UnitPrice) > priceTolerance {
+			priceChanges = append(priceChanges, fmt.Sprintf("product %d variant %d costs %.2f, not %.2f",
+				item.ProductID, item.VariantID, availability.UnitPrice, item.Price))
+			param.Items[index].Price = availability.UnitPrice
+		}
+	}
+
+	if len(priceChanges) != 0 && !param.AcceptPriceChanges {
+		return fmt.Errorf("%w: %s", ErrPriceChanged, strings.Join(priceChanges, "; "))
+	}
+
+	return nil
+}
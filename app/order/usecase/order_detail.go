@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/cmd/order/service"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+)
+
+// GetOrderDetail get order detail by given orderID, userID, and isStaff.
+//
+// Only staff and the user who placed the order may see it, orders of other users are reported as not
+// found. The payment comes from the payment service, when it cannot be reached the order is returned
+// without it rather than failing.
+// It returns models.OrderDetailResponse, and nil error when successful.
+// Otherwise, empty models.OrderDetailResponse, and error will be returned.
+func (uc *OrderUsecase) GetOrderDetail(ctx context.Context, orderID int64, userID int64, isStaff bool) (models.OrderDetailResponse, error) {
+	order, err := uc.OrderService.GetOrderInfoByOrderID(ctx, orderID)
+	if err != nil {
+		return models.OrderDetailResponse{}, err
+	}
+
+	if order.ID == 0 || (!isStaff && order.UserID != userID) {
+		return models.OrderDetailResponse{}, service.ErrOrderNotFound
+	}
+
+	orderDetail, err := uc.OrderService.GetOrderDetailByOrderDetailID(ctx, order.OrderDetailID)
+	if err != nil {
+		return models.OrderDetailResponse{}, err
+	}
+
+	result := models.OrderDetailResponse{
+		OrderID:         order.ID,
+		UserID:          order.UserID,
+		TotalAmount:     order.Amount,
+		TotalQty:        order.TotalQty,
+		Status:          constant.OrderStatusTranslated[order.Status],
+		PaymentMethod:   order.PaymentMethod,
+		ShippingAddress: order.ShippingAddress,
+		Items:           []models.CheckoutItem{},
+		Timeline:        []models.StatusHistory{},
+		CreateTime:      order.CreateTime,
+		UpdateTime:      order.UpdateTime,
+	}
+
+	_ = json.Unmarshal([]byte(orderDetail.Products), &result.Items)
+	_ = json.Unmarshal([]byte(orderDetail.OrderHistory), &result.Timeline)
+
+	paymentInfo, err := uc.OrderService.GetPaymentByOrderID(ctx, order.ID)
+	if err != nil {
+		log.Logger.Printf("Failed get payment of order %d: %v", order.ID, err)
+		return result, nil
+	}
+
+	result.Payment = &paymentInfo
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"expvar"
+	"orderfc/infrastructure/log"
+	"time"
+)
+
+const (
+	orderOutboxInterval  = time.Second
+	orderOutboxBatchSize = 100
+	// orderOutboxRetention keeps published events around for a while to debug consumers
+	orderOutboxRetention = 7 * 24 * time.Hour
+)
+
+// metrics of the outbox relay, served on /debug/vars
+var (
+	orderOutboxPending       = expvar.NewInt("order_outbox_pending")
+	orderOutboxOldestPending = expvar.NewFloat("order_outbox_oldest_pending_seconds")
+	orderOutboxPublished     = expvar.NewInt("order_outbox_published_total")
+	orderOutboxFailed        = expvar.NewInt("order_outbox_failed_total")
+)
+
+// StartRelayOrderOutbox start relay order outbox.
+//
+// It publishes the committed order events to kafka every tick until the outbox holds nothing due,
+// and refreshes the backlog metrics afterwards.
+func (uc *OrderUsecase) StartRelayOrderOutbox() {
+	ticker := time.NewTicker(orderOutboxInterval)
+
+	go func() {
+		var lastCleanup time.Time
+		for range ticker.C {
+			ctx := context.Background()
+			for {
+				result, err := uc.OrderService.RelayOrderOutbox(ctx, orderOutboxBatchSize, uc.Producer.PublishOrderOutbox)
+				if err != nil {
+					log.Logger.Printf("[OUTBOX] Failed relay order outbox: %v", err)
+					break
+				}
+
+				orderOutboxPublished.Add(int64(result.Published))
+				orderOutboxFailed.Add(int64(result.Failed))
+				if result.Published == 0 {
+					break
+				}
+			}
+
+			uc.refreshOrderOutboxMetrics(ctx)
+
+			if time.Since(lastCleanup) < time.Hour {
+				continue
+			}
+
+			if err := uc.OrderService.DeletePublishedOrderOutbox(ctx, time.Now().Add(-orderOutboxRetention)); err != nil {
+				log.Logger.Printf("[OUTBOX] Failed delete published order outbox: %v", err)
+				continue
+			}
+			lastCleanup = time.Now()
+		}
+	}()
+}
+
+// refreshOrderOutboxMetrics refresh order outbox metrics.
+//
+// A failure only leaves the previous values in place.
+func (uc *OrderUsecase) refreshOrderOutboxMetrics(ctx context.Context) {
+	backlog, err := uc.OrderService.GetOrderOutboxBacklog(ctx)
+	if err != nil {
+		log.Logger.Printf("[OUTBOX] Failed get order outbox backlog: %v", err)
+		return
+	}
+
+	orderOutboxPending.Set(backlog.Pending)
+	if backlog.OldestPendingTime == nil {
+		orderOutboxOldestPending.Set(0)
+		return
+	}
+
+	orderOutboxOldestPending.Set(time.Since(*backlog.OldestPendingTime).Seconds())
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"strings"
+)
+
+var ErrInvalidOrderStatus = errors.New("invalid order status")
+
+// ChangeOrderStatus change order status by given orderID, staffID, and OrderStatusRequest.
+//
+// Staff move an order through fulfilment with it, the state machine decides which statuses are allowed.
+// Cancelling or refunding an order gives its stock back and tells the payment service, the same way a
+// cancellation by the customer does.
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (uc *OrderUsecase) ChangeOrderStatus(ctx context.Context, orderID int64, staffID int64, param models.OrderStatusRequest) (models.Order, error) {
+	status, ok := orderStatusByName(param.Status)
+	if !ok {
+		return models.Order{}, fmt.Errorf("%w: %s", ErrInvalidOrderStatus, param.Status)
+	}
+
+	reason := strings.TrimSpace(param.Reason)
+
+	var buildEvents func(order models.Order, orderDetail models.OrderDetail) ([]models.OrderOutbox, error)
+	if status == constant.OrderStatusCancelled || status == constant.OrderStatusRefunded {
+		buildEvents = orderCancelledEvents(status, reason)
+	}
+
+	order, err := uc.OrderService.ChangeOrderStatus(ctx, models.OrderStatusChange{
+		OrderID: orderID,
+		Status:  status,
+		Actor:   constant.OrderActorStaff,
+		ActorID: staffID,
+		Reason:  reason,
+	}, buildEvents)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+// orderStatusByName order status by given name.
+//
+// It returns the status called name, and true when there is one.
+// Otherwise, 0, and false will be returned.
+func orderStatusByName(name string) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for status, translated := range constant.OrderStatusTranslated {
+		if translated == name {
+			return status, true
+		}
+	}
+
+	return 0, false
+}
@@ -8,11 +8,15 @@ import (
 	"fmt"
 	"orderfc/cmd/order/service"
 	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
 	"orderfc/kafka"
 	"orderfc/models"
 	"time"
 )
 
+// maxCompletedOrderBaskets caps one page of GetCompletedOrderBaskets.
+const maxCompletedOrderBaskets = 1000
+
 type OrderUsecase struct {
 	OrderService service.OrderService
 	Producer     kafka.KafkaProducer
@@ -45,13 +49,36 @@ func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.Checkou
 		}
 	}
 
+	if param.FromCart {
+		items, err := uc.cartCheckoutItems(ctx, param.UserID)
+		if err != nil {
+			return 0, err
+		}
+		param.Items = items
+	}
+
 	if err := uc.validateProducts(param.Items); err != nil {
 		return 0, err
 	}
 
+	if err := uc.attachBundleItems(ctx, param); err != nil {
+		return 0, err
+	}
+
+	reservations, err := uc.reserveFlashSaleItems(ctx, param)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = uc.verifyItems(ctx, param); err != nil {
+		uc.releaseFlashSaleItems(ctx, param.UserID, reservations)
+		return 0, err
+	}
+
 	totalQty, totalAmount := uc.calculateOrderSummary(param.Items)
-	productJSON, historyJSON, err := uc.constructOrderDetail(param.Items)
+	productJSON, historyJSON, err := uc.constructOrderDetail(param.UserID, param.Items)
 	if err != nil {
+		uc.releaseFlashSaleItems(ctx, param.UserID, reservations)
 		return 0, err
 	}
 
@@ -69,8 +96,11 @@ func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.Checkou
 		ShippingAddress: param.ShippingAddress,
 	}
 
-	orderID, err := uc.OrderService.SaveOrderAndOrderDetail(ctx, order, orderDetail)
+	orderID, err := uc.OrderService.SaveOrderAndOrderDetail(ctx, order, orderDetail, func(orderID int64) ([]models.OrderOutbox, error) {
+		return checkoutEvents(orderID, order, param.Items)
+	})
 	if err != nil {
+		uc.releaseFlashSaleItems(ctx, param.UserID, reservations)
 		return 0, err
 	}
 
@@ -78,37 +108,43 @@ func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.Checkou
 		_ = uc.OrderService.SaveIdempotencyToken(ctx, param.IdempotencyToken)
 	}
 
-	event := models.OrderCreatedEvent{
-		OrderID:         orderID,
-		UserID:          param.UserID,
-		TotalAmount:     order.Amount,
-		PaymentMethod:   param.PaymentMethod,
-		ShippingAddress: param.ShippingAddress,
+	if param.FromCart {
+		uc.clearCheckedOutCart(ctx, param.UserID)
 	}
 
-	go func() {
-		if err := uc.Producer.PublishOrderCreated(context.Background(), event); err != nil {
-			fmt.Println("Failed to send Kafka event:", err)
-		} else {
-			fmt.Println("OrderCreated event sent to Kafka")
-		}
-	}()
+	return orderID, nil
+}
 
-	updateStockEvent := models.ProductStockUpdateEvent{
-		OrderID:   orderID,
-		Products:  convertCheckoutItemToProductItems(param.Items),
-		EventTime: time.Now(),
+// checkoutEvents checkout events by given orderID, order pointer of models.Order, and items slice of CheckoutItem.
+//
+// The order.created event lets the payment service create the invoice, the stock.update event lets the
+// product service reserve the stock.
+// It returns slice of models.OrderOutbox, and nil error when successful.
+// Otherwise, nil value of models.OrderOutbox slice, and error will be returned.
+func checkoutEvents(orderID int64, order *models.Order, items []models.CheckoutItem) ([]models.OrderOutbox, error) {
+	orderCreated, err := kafka.NewOrderOutbox(kafka.TopicOrderCreated, orderID, models.OrderCreatedEvent{
+		OrderID:         orderID,
+		UserID:          order.UserID,
+		TotalAmount:     order.Amount,
+		PaymentMethod:   order.PaymentMethod,
+		ShippingAddress: order.ShippingAddress,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	go func() {
-		if err := uc.Producer.PublishProductStockUpdate(context.Background(), updateStockEvent); err != nil {
-			fmt.Println("Failed to send kafka event: ", err)
-		} else {
-			fmt.Println("StockUpdate event sent to kafka")
-		}
-	}()
+	stockUpdate, err := kafka.NewOrderOutbox(kafka.TopicStockUpdate, orderID, models.ProductStockUpdateEvent{
+		OrderID:         orderID,
+		UserID:          order.UserID,
+		Products:        convertCheckoutItemToProductItems(items),
+		ShippingAddress: order.ShippingAddress,
+		EventTime:       time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return orderID, nil
+	return []models.OrderOutbox{orderCreated, stockUpdate}, nil
 }
 
 // validateProducts validate products by given items slice of CheckoutItem.
@@ -116,12 +152,13 @@ func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.Checkou
 // It returns nil error when successful.
 // Otherwise, error will be returned.
 func (uc *OrderUsecase) validateProducts(items []models.CheckoutItem) error {
-	seen := map[int64]bool{}
+	seen := map[[2]int64]bool{}
 	for _, item := range items {
-		if seen[item.ProductID] {
-			return fmt.Errorf("duplicate product: %d", item.ProductID)
+		key := [2]int64{item.ProductID, item.VariantID}
+		if seen[key] {
+			return fmt.Errorf("duplicate product: %d variant: %d", item.ProductID, item.VariantID)
 		}
-		seen[item.ProductID] = true
+		seen[key] = true
 
 		if item.Quantity <= 0 || item.Quantity > 10000 {
 			return fmt.Errorf("invalid quantity for %d", item.ProductID)
@@ -134,6 +171,76 @@ func (uc *OrderUsecase) validateProducts(items []models.CheckoutItem) error {
 	return nil
 }
 
+// attachBundleItems attach bundle items by given CheckoutRequest.
+//
+// The components come from the product service, whatever the client sent.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) attachBundleItems(ctx context.Context, param *models.CheckoutRequest) error {
+	productIDs := make([]int64, len(param.Items))
+	for index := range param.Items {
+		param.Items[index].BundleItems = nil
+		productIDs[index] = param.Items[index].ProductID
+	}
+
+	bundleItems, err := uc.OrderService.GetBundleItems(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for index, item := range param.Items {
+		param.Items[index].BundleItems = bundleItems[item.ProductID]
+	}
+
+	return nil
+}
+
+// reserveFlashSaleItems reserve flash sale items by given CheckoutRequest.
+//
+// Reserved items are sold at the flash sale price, whatever price the client sent.
+// It returns slice of models.FlashSaleReservation, and nil error when successful.
+// Otherwise, nil value of models.FlashSaleReservation slice, and error will be returned.
+func (uc *OrderUsecase) reserveFlashSaleItems(ctx context.Context, param *models.CheckoutRequest) ([]models.FlashSaleReservation, error) {
+	for index := range param.Items {
+		param.Items[index].FlashSaleID = 0
+	}
+
+	reservations, err := uc.OrderService.ReserveFlashSaleStock(ctx, param.UserID, param.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	reservationByItem := make(map[[2]int64]models.FlashSaleReservation, len(reservations))
+	for _, reservation := range reservations {
+		reservationByItem[[2]int64{reservation.ProductID, reservation.VariantID}] = reservation
+	}
+
+	for index, item := range param.Items {
+		reservation, ok := reservationByItem[[2]int64{item.ProductID, item.VariantID}]
+		if !ok {
+			continue
+		}
+
+		param.Items[index].Price = reservation.Price
+		param.Items[index].FlashSaleID = reservation.FlashSaleID
+	}
+
+	return reservations, nil
+}
+
+// releaseFlashSaleItems release flash sale items by given userID, and reservations slice of FlashSaleReservation.
+//
+// It only logs a failure, the checkout error is what the caller returns.
+func (uc *OrderUsecase) releaseFlashSaleItems(ctx context.Context, userID int64, reservations []models.FlashSaleReservation) {
+	if len(reservations) == 0 {
+		return
+	}
+
+	if err := uc.OrderService.ReleaseFlashSaleStock(ctx, userID, reservations); err != nil {
+		log.Logger.Printf("Failed release flash sale stock of user %d: %v", userID, err)
+	}
+}
+
 // calculateOrderSummary calculate order summary by given items slice of CheckoutItem.
 //
 // It returns int, and float64 when successful.
@@ -148,15 +255,19 @@ func (uc *OrderUsecase) calculateOrderSummary(items []models.CheckoutItem) (int,
 	return totalQty, totalAmount
 }
 
-// constructOrderDetail construct order detail by given items slice of CheckoutItem.
+// constructOrderDetail construct order detail by given userID, and items slice of CheckoutItem.
 //
 // It returns string, string, and nil error when successful.
 // Otherwise, empty string, empty string, and error will be returned.
-func (uc *OrderUsecase) constructOrderDetail(items []models.CheckoutItem) (string, string, error) {
+func (uc *OrderUsecase) constructOrderDetail(userID int64, items []models.CheckoutItem) (string, string, error) {
 	productsJSON, _ := json.Marshal(items)
-	history := []map[string]interface{}{
-		{"status": "created", "timestamp": time.Now()},
-	}
+	history := []models.StatusHistory{{
+		Status:    constant.OrderStatusTranslated[constant.OrderStatusCreated],
+		Actor:     constant.OrderActorUser,
+		ActorID:   userID,
+		Reason:    "checkout",
+		Timestamp: time.Now(),
+	}}
 	historyJSON, _ := json.Marshal(history)
 
 	return string(productsJSON), string(historyJSON), nil
@@ -175,17 +286,48 @@ func (uc *OrderUsecase) GetOrderHistory(ctx context.Context, param models.OrderH
 	return orderHistory, nil
 }
 
+// HasCompletedOrderWithProduct has completed order with product by given userID, and productID.
+//
+// It returns bool, and nil error when successful.
+// Otherwise, false, and error will be returned.
+func (uc *OrderUsecase) HasCompletedOrderWithProduct(ctx context.Context, userID int64, productID int64) (bool, error) {
+	if userID == 0 || productID == 0 {
+		return false, errors.New("user id and product id are required")
+	}
+
+	hasCompletedOrder, err := uc.OrderService.HasCompletedOrderWithProduct(ctx, userID, productID)
+	if err != nil {
+		return false, err
+	}
+
+	return hasCompletedOrder, nil
+}
+
+// GetCompletedOrderBaskets get completed order baskets by given afterOrderID, and limit.
+//
+// It returns slice of models.OrderBasket, and nil error when successful.
+// Otherwise, nil value of models.OrderBasket slice, and error will be returned.
+func (uc *OrderUsecase) GetCompletedOrderBaskets(ctx context.Context, afterOrderID int64, limit int) ([]models.OrderBasket, error) {
+	if limit <= 0 || limit > maxCompletedOrderBaskets {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxCompletedOrderBaskets)
+	}
+
+	baskets, err := uc.OrderService.GetCompletedOrderBaskets(ctx, afterOrderID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return baskets, nil
+}
+
 // convertCheckoutItemToProductItems convert checkout item to product items by given source slice of CheckoutItem.
 //
 // It returns slice of models.ProductItem when successful.
 // Otherwise, nil value of models.ProductItem slice will be returned.
 func convertCheckoutItemToProductItems(source []models.CheckoutItem) []models.ProductItem {
-	result := make([]models.ProductItem, len(source))
-	for index, item := range source {
-		result[index] = models.ProductItem{
-			ProductID: item.ProductID,
-			Qty:       item.Quantity,
-		}
+	result := make([]models.ProductItem, 0, len(source))
+	for _, item := range source {
+		result = append(result, item.StockItems()...)
 	}
 
 	return result
@@ -43,6 +43,9 @@ func main() {
 	xenditService := service.NewXenditService(grpcUserClient, databaseRepository, xenditRepository)
 	xenditUsecase := usecase.NewXenditUsecase(xenditService)
 
+	// grpc server for the order service, e.g. payment status of an order
+	go grpc.StartPaymentServer("50054", grpc.NewPaymentServer(paymentService))
+
 	// scheduler
 	scheduler := service.SchedulerService{
 		Database:       databaseRepository,
@@ -78,6 +81,24 @@ func main() {
 			}
 		})
 
+	// a cancelled order voids its pending invoice or refunds its payment
+	orderCancellationService := service.OrderCancellationService{
+		Database: databaseRepository,
+		Xendit:   xenditRepository,
+	}
+
+	deadLetterWriter := kafka.NewDeadLetterWriter(cfg.Kafka.Broker)
+	defer deadLetterWriter.Close()
+
+	kafka.StartOrderCancelledConsumer(cfg.Kafka.Broker, "order.cancelled", deadLetterWriter,
+		func(ctx context.Context, event models.OrderCancelledEvent) error {
+			err := orderCancellationService.ProcessOrderCancelled(ctx, event)
+			if err != nil {
+				log.Logger.Println("Failed Handling Order Cancelled Event: ", err.Error())
+			}
+			return err
+		})
+
 	// current condition
 	/*
 		- user checkout order
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"log"
+	"net"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/proto/paymentpb"
+
+	// external package
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type paymentServer struct {
+	paymentpb.UnimplementedPaymentServiceServer
+	PaymentService service.PaymentService
+}
+
+// NewPaymentServer new payment server by given PaymentService.
+//
+// It returns paymentpb.PaymentServiceServer when successful.
+// Otherwise, nil paymentpb.PaymentServiceServer will be returned.
+func NewPaymentServer(paymentService service.PaymentService) paymentpb.PaymentServiceServer {
+	return &paymentServer{
+		PaymentService: paymentService,
+	}
+}
+
+// StartPaymentServer start payment server by given port, and server.
+//
+// It blocks serving grpc requests, so it is meant to run in its own goroutine.
+func StartPaymentServer(port string, server paymentpb.PaymentServiceServer) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("Failed listen grpc port %s: %v", port, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	paymentpb.RegisterPaymentServiceServer(grpcServer, server)
+
+	log.Printf("gRPC server running on port: %s", port)
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatalf("Failed serving grpc: %v", err)
+	}
+}
+
+// GetPaymentByOrderID get payment by order id by given request pointer of paymentpb.GetPaymentByOrderIDRequest.
+//
+// It returns pointer of paymentpb.GetPaymentByOrderIDResult, and nil error when successful.
+// Otherwise, nil pointer of paymentpb.GetPaymentByOrderIDResult, and error will be returned.
+func (s *paymentServer) GetPaymentByOrderID(ctx context.Context, request *paymentpb.GetPaymentByOrderIDRequest) (*paymentpb.GetPaymentByOrderIDResult, error) {
+	if request.GetOrderId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+
+	paymentInfo, err := s.PaymentService.GetPaymentInfoByOrderID(ctx, request.GetOrderId())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no payment for order %d", request.GetOrderId())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &paymentpb.GetPaymentByOrderIDResult{
+		OrderId:     paymentInfo.OrderID,
+		Status:      paymentInfo.Status,
+		Amount:      paymentInfo.Amount,
+		InvoiceUrl:  paymentInfo.InvoiceURL,
+		ExpiredTime: paymentInfo.ExpiredTime.Unix(),
+	}, nil
+}
@@ -6,11 +6,24 @@ import (
 	"encoding/json"
 	"log"
 	"paymentfc/models"
+	"strconv"
+	"time"
 
 	// external package
 	"github.com/segmentio/kafka-go"
 )
 
+const (
+	maxHandlerAttempts = 5
+	retryBaseDelay     = time.Second
+	retryMaxDelay      = time.Minute
+
+	// headers a dead letter carries next to the original key and value
+	HeaderDeadLetterError  = "dead-letter-error"
+	HeaderDeadLetterTopic  = "dead-letter-topic"
+	HeaderDeadLetterOffset = "dead-letter-offset"
+)
+
 // StartOrderConsumer start order consumer by given broker, topic, and handler.
 func StartOrderConsumer(broker string, topic string, handler func(models.OrderCreatedEvent)) {
 	consumer := kafka.NewReader(kafka.ReaderConfig{
@@ -39,4 +52,107 @@ func StartOrderConsumer(broker string, topic string, handler func(models.OrderCr
 			handler(event)
 		}
 	}(consumer)
+}
+
+// StartOrderCancelledConsumer start order cancelled consumer by given broker, topic, deadLetterWriter, and handler.
+//
+// A message is committed only after handler succeeded. A failing handler is retried with a doubling delay,
+// and once the attempts run out the message is parked on the dead letter topic with the original key and
+// value, so replaying it is publishing it to topic again. It is committed only after the dead letter is
+// stored, until then writing it is retried as well.
+func StartOrderCancelledConsumer(broker string, topic string, deadLetterWriter *kafka.Writer, handler func(ctx context.Context, event models.OrderCancelledEvent) error) {
+	consumer := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{broker},
+		Topic:   topic,
+		GroupID: "paymentfc",
+	})
+
+	go func(r *kafka.Reader) {
+		ctx := context.Background()
+		for {
+			message, err := r.FetchMessage(ctx)
+			if err != nil {
+				log.Println("Error Fetch Message Kafka: ", err.Error())
+				time.Sleep(time.Second)
+				continue
+			}
+
+			var event models.OrderCancelledEvent
+			err = json.Unmarshal(message.Value, &event)
+			if err != nil {
+				log.Println("Error Unmarshal Message: ", err.Error())
+			} else {
+				log.Printf("Received Event Order Cancelled: %+v", event)
+				err = retryHandler(func() error {
+					return handler(ctx, event)
+				})
+			}
+
+			if err != nil {
+				parkMessage(ctx, deadLetterWriter, message, err)
+			}
+
+			err = r.CommitMessages(ctx, message)
+			if err != nil {
+				log.Println("Error Commit Message Kafka: ", err.Error())
+			}
+		}
+	}(consumer)
+}
+
+// retryHandler retry handler by given handle.
+//
+// It returns nil error when handle succeeded within maxHandlerAttempts.
+// Otherwise, the last error will be returned.
+func retryHandler(handle func() error) error {
+	var err error
+	delay := retryBaseDelay
+	for attempt := 1; attempt <= maxHandlerAttempts; attempt++ {
+		err = handle()
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("Handler failed at attempt %d, retrying in %v: %v", attempt, delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, retryMaxDelay)
+	}
+
+	return err
+}
+
+// parkMessage park message by given writer, message, and cause.
+//
+// It does not give up, the caller commits the message only once the dead letter is stored.
+func parkMessage(ctx context.Context, writer *kafka.Writer, message kafka.Message, cause error) {
+	deadLetter := kafka.Message{
+		Topic: DeadLetterTopic(message.Topic),
+		Key:   message.Key,
+		Value: message.Value,
+		Headers: append(message.Headers,
+			kafka.Header{Key: HeaderDeadLetterError, Value: []byte(cause.Error())},
+			kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(message.Topic)},
+			kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		),
+	}
+
+	delay := retryBaseDelay
+	for {
+		err := writer.WriteMessages(ctx, deadLetter)
+		if err == nil {
+			log.Printf("Parked message of %s at offset %d on %s: %v", message.Topic, message.Offset, deadLetter.Topic, cause)
+			return
+		}
+
+		log.Printf("Failed parking message on %s, retrying in %v: %v", deadLetter.Topic, delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
+
+// DeadLetterTopic dead letter topic by given topic.
+//
+// It returns the topic the messages of topic that could not be handled are parked on.
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
 }
\ No newline at end of file
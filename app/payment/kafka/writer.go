@@ -14,4 +14,16 @@ func NewWriter(broker string, topic string) *kafka.Writer {
 		Topic:    topic,
 		Balancer: &kafka.LeastBytes{},
 	}
+}
+
+// NewDeadLetterWriter new dead letter writer by given broker.
+//
+// The writer has no topic of its own, every message names the dead letter topic it goes to.
+// It returns pointer of kafka.Writer when successful.
+// Otherwise, nil pointer of kafka.Writer will be returned.
+func NewDeadLetterWriter(broker string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:     kafka.TCP(broker),
+		Balancer: &kafka.LeastBytes{},
+	}
 }
\ No newline at end of file
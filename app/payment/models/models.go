@@ -37,6 +37,7 @@ type Payment struct {
 	ExternalID  string    `json:"external_id"`
 	Amount      float64   `json:"amount"`
 	Status      string    `json:"status"`
+	InvoiceURL  string    `json:"invoice_url"`
 	CreateTime  time.Time `json:"create_time"`
 	UpdateTime  time.Time `json:"update_time"`
 	ExpiredTime time.Time `json:"expired_time"`
@@ -96,4 +97,28 @@ type XenditInvoiceResponse struct {
 	ExpiryDate time.Time `json:"expiry_date"`
 	InvoiceURL string    `json:"invoice_url"`
 	Status     string    `json:"status"`
+}
+
+// OrderCancelledEvent is published by the order service when a user cancels an order. Refund tells
+// whether the order service saw the order as paid, the status of the payment decides what is done.
+type OrderCancelledEvent struct {
+	OrderID   int64     `json:"order_id"`
+	UserID    int64     `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	Refund    bool      `json:"refund"`
+	Reason    string    `json:"reason"`
+	EventTime time.Time `json:"event_time"`
+}
+
+type XenditRefundRequest struct {
+	InvoiceID   string  `json:"invoice_id"`
+	ReferenceID string  `json:"reference_id"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"`
+}
+
+type XenditRefundResponse struct {
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+	Status string  `json:"status"`
 }
\ No newline at end of file
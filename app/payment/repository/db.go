@@ -13,6 +13,12 @@ import (
 )
 
 type PaymentDatabase interface {
+	// CancelPaymentRequests cancel payment requests by given orderID.
+	//
+	// It returns nil error when successful.
+	// Otherwise, error will be returned.
+	CancelPaymentRequests(ctx context.Context, orderID int64) error
+
 	// CheckPaymentAmountByOrderID check payment amount by order id by given orderID.
 	//
 	// It returns float64, and nil error when successful.
@@ -85,6 +91,12 @@ type PaymentDatabase interface {
 	// Otherwise, error will be returned.
 	MarkPaid(ctx context.Context, orderID int64) error
 
+	// MarkRefunded mark refunded by given paymentID.
+	//
+	// It returns nil error when successful.
+	// Otherwise, error will be returned.
+	MarkRefunded(ctx context.Context, paymentID int64) error
+
 	// SaveFailedPublishEvent save failed publish event by given FailedEvents.
 	//
 	// It returns nil error when successful.
@@ -427,6 +439,41 @@ func (r *paymentDatabase) MarkExpired(ctx context.Context, paymentID int64) erro
 	return nil
 }
 
+// MarkRefunded mark refunded by given paymentID.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *paymentDatabase) MarkRefunded(ctx context.Context, paymentID int64) error {
+	err := r.DB.Table("payments").WithContext(ctx).Model(&models.Payment{}).Where("id = ?", paymentID).
+		Updates(map[string]interface{}{
+			"status":      "REFUNDED",
+			"update_time": time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CancelPaymentRequests cancel payment requests by given orderID.
+//
+// A cancelled request is skipped by the schedulers, so no invoice is created for a cancelled order.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *paymentDatabase) CancelPaymentRequests(ctx context.Context, orderID int64) error {
+	err := r.DB.Table("payment_requests").WithContext(ctx).Where("order_id = ? AND status IN ?", orderID, []string{"PENDING", "FAILED"}).
+		Updates(map[string]interface{}{
+			"status":      "CANCELLED",
+			"update_time": time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // InsertAuditLog insert audit log by given PaymentAuditLog.
 //
 // It returns nil error when successful.
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"paymentfc/models"
 )
 
@@ -24,6 +25,24 @@ type XenditClient interface {
 	// It returns models.XenditInvoiceResponse, and nil error when successful.
 	// Otherwise, empty models.XenditInvoiceResponse, and error will be returned.
 	CreateInvoice(ctx context.Context, param models.XenditInvoiceRequest) (models.XenditInvoiceResponse, error)
+
+	// CreateRefund create refund by given XenditRefundRequest.
+	//
+	// It returns models.XenditRefundResponse, and nil error when successful.
+	// Otherwise, empty models.XenditRefundResponse, and error will be returned.
+	CreateRefund(ctx context.Context, param models.XenditRefundRequest) (models.XenditRefundResponse, error)
+
+	// ExpireInvoice expire invoice by given invoiceID.
+	//
+	// It returns nil error when successful.
+	// Otherwise, error will be returned.
+	ExpireInvoice(ctx context.Context, invoiceID string) error
+
+	// GetInvoiceByExternalID get invoice by external id by given externalID.
+	//
+	// It returns models.XenditInvoiceResponse, and nil error when successful.
+	// Otherwise, empty models.XenditInvoiceResponse, and error will be returned.
+	GetInvoiceByExternalID(ctx context.Context, externalID string) (models.XenditInvoiceResponse, error)
 }
 
 type xenditClient struct {
@@ -107,4 +126,110 @@ func (c *xenditClient) CheckInvoiceStatus(ctx context.Context, externalID string
 	}
 
 	return response[0].Status, nil
+}
+
+// GetInvoiceByExternalID get invoice by external id by given externalID.
+//
+// It returns models.XenditInvoiceResponse, and nil error when successful.
+// Otherwise, empty models.XenditInvoiceResponse, and error will be returned.
+func (c *xenditClient) GetInvoiceByExternalID(ctx context.Context, externalID string) (models.XenditInvoiceResponse, error) {
+	uri := fmt.Sprintf("https://api.xendit.co/v2/invoices?external_id=%s", url.QueryEscape(externalID))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return models.XenditInvoiceResponse{}, err
+	}
+
+	httpReq.SetBasicAuth(c.APISecretKey, "")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return models.XenditInvoiceResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return models.XenditInvoiceResponse{}, fmt.Errorf("xendit.GetInvoiceByExternalID() got error: %s", string(body))
+	}
+
+	var response []models.XenditInvoiceResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return models.XenditInvoiceResponse{}, err
+	}
+
+	if len(response) == 0 {
+		return models.XenditInvoiceResponse{}, fmt.Errorf("xendit.GetInvoiceByExternalID() found no invoice for %s", externalID)
+	}
+
+	return response[0], nil
+}
+
+// ExpireInvoice expire invoice by given invoiceID.
+//
+// An expired invoice can no longer be paid.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (c *xenditClient) ExpireInvoice(ctx context.Context, invoiceID string) error {
+	uri := fmt.Sprintf("https://api.xendit.co/invoices/%s/expire!", url.PathEscape(invoiceID))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	httpReq.SetBasicAuth(c.APISecretKey, "")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("xendit.ExpireInvoice() got error: %s", string(body))
+	}
+
+	return nil
+}
+
+// CreateRefund create refund by given XenditRefundRequest.
+//
+// It returns models.XenditRefundResponse, and nil error when successful.
+// Otherwise, empty models.XenditRefundResponse, and error will be returned.
+func (c *xenditClient) CreateRefund(ctx context.Context, param models.XenditRefundRequest) (models.XenditRefundResponse, error) {
+	var result models.XenditRefundResponse
+	payload, err := json.Marshal(param)
+	if err != nil {
+		return models.XenditRefundResponse{}, err
+	}
+
+	uri := "https://api.xendit.co/refunds"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewBuffer(payload))
+	if err != nil {
+		return models.XenditRefundResponse{}, err
+	}
+
+	httpReq.SetBasicAuth(c.APISecretKey, "")
+	httpReq.Header.Set("Content-Type", "application/json")
+	// the reference id makes a redelivered cancellation ask for the same refund instead of a second one
+	httpReq.Header.Set("Idempotency-Key", param.ReferenceID)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return models.XenditRefundResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return models.XenditRefundResponse{}, fmt.Errorf("xendit.CreateRefund() got error: %s", string(body))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return models.XenditRefundResponse{}, err
+	}
+
+	return result, nil
 }
\ No newline at end of file
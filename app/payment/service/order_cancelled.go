@@ -0,0 +1,119 @@
+package service
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/infrastructure/log"
+	"paymentfc/models"
+	"strings"
+	"time"
+
+	// external package
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// xenditRefundReasonCancellation is the refund reason xendit expects for a cancelled order
+const xenditRefundReasonCancellation = "CANCELLATION"
+
+type OrderCancellationService struct {
+	Database repository.PaymentDatabase
+	Xendit   repository.XenditClient
+}
+
+// ProcessOrderCancelled process order cancelled by given OrderCancelledEvent.
+//
+// A payment request that has no invoice yet is cancelled, a pending invoice is expired so it can no
+// longer be paid, and a paid invoice is refunded. The status of the payment decides, not the event, so
+// an invoice paid while the cancellation was on its way is refunded as well. Expired, failed and refunded
+// payments are left alone, which makes a redelivered event harmless.
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderCancellationService) ProcessOrderCancelled(ctx context.Context, event models.OrderCancelledEvent) error {
+	err := s.Database.CancelPaymentRequests(ctx, event.OrderID)
+	if err != nil {
+		return err
+	}
+
+	paymentInfo, err := s.Database.GetPaymentInfoByOrderID(ctx, event.OrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToUpper(paymentInfo.Status) {
+	case "PENDING":
+		invoice, err := s.Xendit.GetInvoiceByExternalID(ctx, paymentInfo.ExternalID)
+		if err != nil {
+			return err
+		}
+
+		err = s.Xendit.ExpireInvoice(ctx, invoice.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.Database.MarkExpired(ctx, paymentInfo.ID)
+		if err != nil {
+			return err
+		}
+
+		s.insertAuditLog(ctx, paymentInfo, "ExpireInvoice-OrderCancelled")
+	case "PAID":
+		invoice, err := s.Xendit.GetInvoiceByExternalID(ctx, paymentInfo.ExternalID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Xendit.CreateRefund(ctx, models.XenditRefundRequest{
+			InvoiceID:   invoice.ID,
+			ReferenceID: fmt.Sprintf("refund-%s", paymentInfo.ExternalID),
+			Amount:      paymentInfo.Amount,
+			Reason:      xenditRefundReasonCancellation,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.Database.MarkRefunded(ctx, paymentInfo.ID)
+		if err != nil {
+			return err
+		}
+
+		s.insertAuditLog(ctx, paymentInfo, "CreateRefund-OrderCancelled")
+	default:
+		log.Logger.WithFields(logrus.Fields{
+			"order_id": event.OrderID,
+			"status":   paymentInfo.Status,
+		}).Infof("[skip - order %d] Payment is %s, nothing to void or refund", event.OrderID, paymentInfo.Status)
+	}
+
+	return nil
+}
+
+// insertAuditLog insert audit log by given paymentInfo, and event.
+//
+// It only logs a failure, the payment already changed.
+func (s *OrderCancellationService) insertAuditLog(ctx context.Context, paymentInfo models.Payment, event string) {
+	auditLogParam := models.PaymentAuditLog{
+		OrderID:    paymentInfo.OrderID,
+		UserID:     paymentInfo.UserID,
+		PaymentID:  paymentInfo.ID,
+		ExternalID: paymentInfo.ExternalID,
+		Event:      event,
+		Actor:      "order",
+		CreateTime: time.Now(),
+	}
+
+	err := s.Database.InsertAuditLog(ctx, auditLogParam)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": auditLogParam,
+		}).WithError(err).Errorf("s.Database.InsertAuditLog() got error: %v", err)
+	}
+}
@@ -511,6 +511,7 @@ func (s *SchedulerService) StartProcessPendingPaymentRequests() {
 					Amount:      paymentRequest.Amount,
 					ExternalID:  externalID,
 					Status:      "PENDING",
+					InvoiceURL:  xenditInvoiceDetail.InvoiceURL,
 					CreateTime:  time.Now(),
 					ExpiredTime: xenditInvoiceDetail.ExpiryDate,
 				})
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/config"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/grpc"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/repository"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/service"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/usecase"
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	sharedConfig "github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
+	"github.com/PorcoGalliard/eCommerce-Microservice/resource"
+)
+
+// Usage:
+//
+//	catalog -import products.csv [-format csv] [-dry-run]
+//	catalog -export products.ndjson -format ndjson
+func main() {
+	importFile := flag.String("import", "", "path of the csv or ndjson file to import")
+	exportFile := flag.String("export", "", "path to write the exported catalog to")
+	format := flag.String("format", usecase.CatalogFormatCSV, "catalog format, csv or ndjson")
+	dryRun := flag.Bool("dry-run", false, "validate the import without saving anything")
+	flag.Parse()
+
+	log.SetupLogger()
+	if (*importFile == "") == (*exportFile == "") {
+		log.Logger.Fatal("❌ Use exactly one of -import or -export")
+	}
+
+	if !usecase.IsSupportedCatalogFormat(*format) {
+		log.Logger.Fatalf("❌ Invalid format %v, use csv or ndjson", *format)
+	}
+
+	cfg := sharedConfig.LoadConfig(&config.ProductConfig{},
+			sharedConfig.WithConfigPath("files/config"),
+			sharedConfig.WithConfigFile("product_service_config"),
+			sharedConfig.WithConfigType("yaml"))
+
+	postgre := resource.InitPostgres(cfg.Database)
+	redis := resource.InitRedis(cfg.Redis)
+	storage, err := repository.NewBlobStorage(cfg.Storage)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed init blob storage: %v", err)
+	}
+
+	notifier, err := repository.NewStockNotifier(cfg.Notifier)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed init stock notifier: %v", err)
+	}
+
+	kafkaWriter := resource.InitKafkaWriter(cfg.Kafka)
+	defer kafkaWriter.Close()
+
+	productRepository := repository.NewProductRepository(postgre, redis, storage, kafkaWriter, notifier)
+	orderClient, err := grpc.NewOrderClient(cfg.GRPC.OrderAddress)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed init grpc order client: %v", err)
+	}
+
+	productService := service.NewProductService(productRepository, orderClient, cfg.Inventory)
+	productUsecase := usecase.NewProductUsecase(productService)
+
+	ctx := context.Background()
+
+	if *exportFile != "" {
+		file, err := os.Create(*exportFile)
+		if err != nil {
+			log.Logger.Fatalf("❌ Failed creating export file: %v", err)
+		}
+		defer file.Close()
+
+		if err := productUsecase.ExportProducts(ctx, *format, file); err != nil {
+			log.Logger.Fatalf("❌ Failed exporting catalog: %v", err)
+		}
+
+		log.Logger.Infof("✅ Catalog exported to %s", *exportFile)
+		return
+	}
+
+	file, err := os.Open(*importFile)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed opening import file: %v", err)
+	}
+	defer file.Close()
+
+	result, err := productUsecase.ImportProducts(ctx, *format, file, *dryRun)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed importing catalog: %v", err)
+	}
+
+	report, _ := json.MarshalIndent(result, "", "  ")
+	os.Stdout.Write(append(report, '\n'))
+
+	log.Logger.Infof("✅ Imported %s: %d created, %d updated, %d failed", *importFile, result.Created, result.Updated, result.Failed)
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/config"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/grpc"
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/handler"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/kafka"
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/repository"
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/routes"
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/service"
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/usecase"
 	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
 	sharedConfig "github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
 	"github.com/PorcoGalliard/eCommerce-Microservice/resource"
 	"github.com/gin-gonic/gin"
@@ -22,14 +25,48 @@ func main()  {
 
 	postgre := resource.InitPostgres(cfg.Database) 
 	redis := resource.InitRedis(cfg.Redis)
+	storage, err := repository.NewBlobStorage(cfg.Storage)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed init blob storage: %v", err)
+	}
 
-	productRepository := repository.NewProductRepository(postgre, redis)
-	productService := service.NewProductService(productRepository)
+	notifier, err := repository.NewStockNotifier(cfg.Notifier)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed init stock notifier: %v", err)
+	}
+
+	kafkaWriter := resource.InitKafkaWriter(cfg.Kafka)
+	defer kafkaWriter.Close()
+
+	productRepository := repository.NewProductRepository(postgre, redis, storage, kafkaWriter, notifier)
+	orderClient, err := grpc.NewOrderClient(cfg.GRPC.OrderAddress)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed init grpc order client: %v", err)
+	}
+
+	if cfg.Inventory.SourcingRule != models.WarehouseSourcingNearest && cfg.Inventory.SourcingRule != models.WarehouseSourcingMostStock {
+		log.Logger.Fatalf("❌ Invalid inventory sourcing rule %q", cfg.Inventory.SourcingRule)
+	}
+
+	productService := service.NewProductService(productRepository, orderClient, cfg.Inventory)
+	productService.StartApplyScheduledPrices()
+	productService.StartRunFlashSales()
+	productService.StartRelayProductOutbox()
+	productService.StartComputeRelatedProducts()
 	productUsecase := usecase.NewProductUsecase(productService)
-	productHandler := handler.NewProductHandler(productUsecase)
+	productHandler := handler.NewProductHandler(productUsecase, cfg.Storefront)
+	productGRPCHandler := handler.NewProductGRPCHandler(productUsecase)
+
+	go grpc.StartProductServer(cfg.GRPC.Port, productGRPCHandler)
+
+	kafka.StartStockConsumer(cfg.Kafka, models.TopicStockUpdate, kafkaWriter, productUsecase.ApplyStockUpdate)
+	kafka.StartStockConsumer(cfg.Kafka, models.TopicStockRollback, kafkaWriter, productUsecase.RollbackStock)
 
 	router := gin.Default()
-	routes.SetupRoutes(router, productHandler)
+	routes.SetupRoutes(router, productHandler, cfg.Secret.JWTSecret)
+	if cfg.Storage.Driver == "local" {
+		router.Static("/media", cfg.Storage.LocalPath)
+	}
 
 	router.Run(":"+cfg.App.Port)
 }
\ No newline at end of file
@@ -7,4 +7,10 @@ type ProductConfig struct {
 	Database config.PostgreConfig
 	Redis config.RedisConfig
 	Secret config.SecretConfig
+	Storage config.StorageConfig
+	GRPC config.GRPCConfig
+	Kafka config.KafkaConfig
+	Notifier config.NotifierConfig
+	Storefront config.StorefrontConfig
+	Inventory config.InventoryConfig
 }
\ No newline at end of file
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/proto/orderpb"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const orderClientTimeout = 3 * time.Second
+
+type OrderClient interface {
+	HasCompletedOrderWithProduct(ctx context.Context, userID int64, productID int64) (bool, error)
+	GetCompletedOrderBaskets(ctx context.Context, afterOrderID int64, limit int) ([]models.OrderBasket, error)
+}
+
+type orderClient struct {
+	Client orderpb.OrderServiceClient
+}
+
+// NewOrderClient does not dial yet, the connection is made on the first call.
+func NewOrderClient(address string) (OrderClient, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &orderClient{
+		Client: orderpb.NewOrderServiceClient(conn),
+	}, nil
+}
+
+func (c *orderClient) HasCompletedOrderWithProduct(ctx context.Context, userID int64, productID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, orderClientTimeout)
+	defer cancel()
+
+	result, err := c.Client.HasCompletedOrderWithProduct(ctx, &orderpb.HasCompletedOrderWithProductRequest{
+		UserId: userID,
+		ProductId: productID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.HasCompletedOrder, nil
+}
+
+func (c *orderClient) GetCompletedOrderBaskets(ctx context.Context, afterOrderID int64, limit int) ([]models.OrderBasket, error) {
+	ctx, cancel := context.WithTimeout(ctx, orderClientTimeout)
+	defer cancel()
+
+	result, err := c.Client.GetCompletedOrderBaskets(ctx, &orderpb.GetCompletedOrderBasketsRequest{
+		AfterOrderId: afterOrderID,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baskets := make([]models.OrderBasket, 0, len(result.Baskets))
+	for _, basket := range result.Baskets {
+		baskets = append(baskets, models.OrderBasket{
+			OrderID: basket.OrderId,
+			ProductIDs: basket.ProductIds,
+		})
+	}
+	return baskets, nil
+}
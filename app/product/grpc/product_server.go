@@ -0,0 +1,25 @@
+package grpc
+
+import (
+	"net"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/proto/productpb"
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"google.golang.org/grpc"
+)
+
+// StartProductServer blocks while serving, run it in its own goroutine next to the Gin server.
+func StartProductServer(port string, server productpb.ProductServiceServer) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Logger.Fatalf("❌ Failed listen grpc port %s: %v", port, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	productpb.RegisterProductServiceServer(grpcServer, server)
+
+	log.Logger.Infof("✅ gRPC server running on port %s", port)
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Logger.Fatalf("❌ Failed serving grpc: %v", err)
+	}
+}
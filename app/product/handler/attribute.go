@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) GetProductCategoryAttributes(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	productAttributes, err := h.ProductUsecase.GetProductCategoryAttributes(c.Request.Context(), productCategoryID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+		}).Errorf("h.ProductUsecase.GetProductCategoryAttributes got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attributes": productAttributes,
+	})
+}
+
+func (h *ProductHandler) CreateProductAttribute(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	var productAttribute models.ProductAttribute
+	if err := c.ShouldBindJSON(&productAttribute); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+	productAttribute.CategoryID = productCategoryID
+
+	productAttributeID, err := h.ProductUsecase.CreateProductAttribute(c.Request.Context(), &productAttribute)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productAttribute": productAttribute,
+		}).Errorf("❌ h.ProductUsecase.CreateProductAttribute got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+	productAttribute.ID = productAttributeID
+
+	c.JSON(http.StatusCreated, gin.H{
+		"attribute": productAttribute,
+	})
+}
+
+func (h *ProductHandler) UpdateProductAttribute(c *gin.Context) {
+	productAttributeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productAttributeID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid attribute ID",
+		})
+		return
+	}
+
+	var productAttribute models.ProductAttribute
+	if err := c.ShouldBindJSON(&productAttribute); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+	productAttribute.ID = productAttributeID
+
+	updatedProductAttribute, err := h.ProductUsecase.UpdateProductAttribute(c.Request.Context(), &productAttribute)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productAttribute": productAttribute,
+		}).Errorf("❌ h.ProductUsecase.UpdateProductAttribute got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attribute": updatedProductAttribute,
+	})
+}
+
+func (h *ProductHandler) DeleteProductAttribute(c *gin.Context) {
+	productAttributeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productAttributeID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid attribute ID",
+		})
+		return
+	}
+
+	if err := h.ProductUsecase.DeleteProductAttribute(c.Request.Context(), productAttributeID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productAttributeID": productAttributeID,
+		}).Errorf("❌ h.ProductUsecase.DeleteProductAttribute got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
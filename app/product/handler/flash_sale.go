@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) GetFlashSales(c *gin.Context) {
+	var param models.FlashSaleListParameter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	flashSales, err := h.ProductUsecase.GetFlashSales(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUsecase.GetFlashSales got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flash_sales": flashSales,
+	})
+}
+
+func (h *ProductHandler) CreateFlashSale(c *gin.Context) {
+	var param models.FlashSaleParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	flashSale, err := h.ProductUsecase.CreateFlashSale(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("❌ h.ProductUsecase.CreateFlashSale got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"flash_sale": flashSale,
+	})
+}
+
+func (h *ProductHandler) EndFlashSale(c *gin.Context) {
+	flashSaleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"flashSaleID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid flash sale ID",
+		})
+		return
+	}
+
+	if err = h.ProductUsecase.EndFlashSale(c.Request.Context(), flashSaleID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"flashSaleID": flashSaleID,
+		}).Errorf("h.ProductUsecase.EndFlashSale got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success end flash sale",
+	})
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/proto/productpb"
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/usecase"
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProductGRPCHandler serves the product gRPC API other services use for authoritative product data.
+type ProductGRPCHandler struct {
+	productpb.UnimplementedProductServiceServer
+	ProductUsecase usecase.ProductUsecase
+}
+
+func NewProductGRPCHandler(productUsecase *usecase.ProductUsecase) *ProductGRPCHandler {
+	return &ProductGRPCHandler{
+		ProductUsecase: *productUsecase,
+	}
+}
+
+func (h *ProductGRPCHandler) GetProductsByIDs(ctx context.Context, request *productpb.GetProductsByIDsRequest) (*productpb.GetProductsByIDsResult, error) {
+	products, missingProductIDs, err := h.ProductUsecase.GetProductsByIDs(ctx, request.GetProductIds())
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productIDs": request.GetProductIds(),
+		}).Errorf("h.ProductUsecase.GetProductsByIDs got an error at %v", err)
+		return nil, productGRPCError(err)
+	}
+
+	result := &productpb.GetProductsByIDsResult{
+		Products: make([]*productpb.Product, len(products)),
+		MissingProductIds: missingProductIDs,
+	}
+	for i := range products {
+		result.Products[i] = toProductPB(&products[i])
+	}
+	return result, nil
+}
+
+func (h *ProductGRPCHandler) CheckAvailability(ctx context.Context, request *productpb.CheckAvailabilityRequest) (*productpb.CheckAvailabilityResult, error) {
+	items := make([]models.ProductAvailabilityItem, len(request.GetItems()))
+	for i, item := range request.GetItems() {
+		items[i] = models.ProductAvailabilityItem{
+			ProductID: item.GetProductId(),
+			VariantID: item.GetVariantId(),
+			Quantity: int(item.GetQuantity()),
+		}
+	}
+
+	availabilities, allAvailable, err := h.ProductUsecase.CheckAvailability(ctx, items)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"items": items,
+		}).Errorf("h.ProductUsecase.CheckAvailability got an error at %v", err)
+		return nil, productGRPCError(err)
+	}
+
+	result := &productpb.CheckAvailabilityResult{
+		AllAvailable: allAvailable,
+		Items: make([]*productpb.ItemAvailability, len(availabilities)),
+	}
+	for i, availability := range availabilities {
+		result.Items[i] = &productpb.ItemAvailability{
+			ProductId: availability.ProductID,
+			VariantId: availability.VariantID,
+			Quantity: int32(availability.Quantity),
+			AvailableStock: int32(availability.AvailableStock),
+			Available: availability.Available,
+			Reason: availability.Reason,
+			Name: availability.Name,
+			UnitPrice: availability.UnitPrice,
+		}
+	}
+	return result, nil
+}
+
+func (h *ProductGRPCHandler) ReserveFlashSaleStock(ctx context.Context, request *productpb.ReserveFlashSaleStockRequest) (*productpb.ReserveFlashSaleStockResult, error) {
+	items := make([]models.ProductAvailabilityItem, len(request.GetItems()))
+	for i, item := range request.GetItems() {
+		items[i] = models.ProductAvailabilityItem{
+			ProductID: item.GetProductId(),
+			VariantID: item.GetVariantId(),
+			Quantity: int(item.GetQuantity()),
+		}
+	}
+
+	reservations, err := h.ProductUsecase.ReserveFlashSaleStock(ctx, request.GetUserId(), items)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": request.GetUserId(),
+			"items": items,
+		}).Errorf("h.ProductUsecase.ReserveFlashSaleStock got an error at %v", err)
+		return nil, productGRPCError(err)
+	}
+
+	result := &productpb.ReserveFlashSaleStockResult{
+		Reservations: make([]*productpb.FlashSaleReservation, len(reservations)),
+	}
+	for i, reservation := range reservations {
+		result.Reservations[i] = &productpb.FlashSaleReservation{
+			ProductId: reservation.ProductID,
+			VariantId: reservation.VariantID,
+			Quantity: int32(reservation.Quantity),
+			FlashSaleId: reservation.FlashSaleID,
+			Price: reservation.Price,
+		}
+	}
+	return result, nil
+}
+
+func (h *ProductGRPCHandler) ReleaseFlashSaleStock(ctx context.Context, request *productpb.ReleaseFlashSaleStockRequest) (*productpb.ReleaseFlashSaleStockResult, error) {
+	reservations := make([]models.FlashSaleReservation, len(request.GetReservations()))
+	for i, reservation := range request.GetReservations() {
+		reservations[i] = models.FlashSaleReservation{
+			ProductID: reservation.GetProductId(),
+			VariantID: reservation.GetVariantId(),
+			Quantity: int(reservation.GetQuantity()),
+			FlashSaleID: reservation.GetFlashSaleId(),
+			Price: reservation.GetPrice(),
+		}
+	}
+
+	if err := h.ProductUsecase.ReleaseFlashSaleStock(ctx, request.GetUserId(), reservations); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": request.GetUserId(),
+			"reservations": reservations,
+		}).Errorf("h.ProductUsecase.ReleaseFlashSaleStock got an error at %v", err)
+		return nil, productGRPCError(err)
+	}
+	return &productpb.ReleaseFlashSaleStockResult{}, nil
+}
+
+func (h *ProductGRPCHandler) GetWishlistItem(ctx context.Context, request *productpb.GetWishlistItemRequest) (*productpb.WishlistItem, error) {
+	wishlistItem, err := h.ProductUsecase.GetWishlistItem(ctx, request.GetUserId(), request.GetWishlistItemId())
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": request.GetUserId(),
+			"wishlistItemID": request.GetWishlistItemId(),
+		}).Errorf("h.ProductUsecase.GetWishlistItem got an error at %v", err)
+		return nil, productGRPCError(err)
+	}
+
+	return &productpb.WishlistItem{
+		Id: wishlistItem.ID,
+		ProductId: wishlistItem.ProductID,
+		VariantId: wishlistItem.VariantID,
+	}, nil
+}
+
+func (h *ProductGRPCHandler) RemoveWishlistItem(ctx context.Context, request *productpb.RemoveWishlistItemRequest) (*productpb.RemoveWishlistItemResult, error) {
+	if err := h.ProductUsecase.RemoveWishlistItem(ctx, request.GetUserId(), request.GetWishlistItemId()); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": request.GetUserId(),
+			"wishlistItemID": request.GetWishlistItemId(),
+		}).Errorf("h.ProductUsecase.RemoveWishlistItem got an error at %v", err)
+		return nil, productGRPCError(err)
+	}
+	return &productpb.RemoveWishlistItemResult{}, nil
+}
+
+func toProductPB(product *models.Product) *productpb.Product {
+	productPB := &productpb.Product{
+		Id: product.ID,
+		Sku: product.SKU,
+		Name: product.Name,
+		Price: product.Price,
+		EffectivePrice: product.EffectivePrice,
+		Stock: int32(product.Stock),
+		CategoryId: product.Category_ID,
+		Status: product.Status,
+		Purchasable: product.IsPurchasable(),
+		Variants: make([]*productpb.ProductVariant, len(product.Variants)),
+		Type: product.Type,
+		BundleItems: make([]*productpb.BundleItem, len(product.BundleItems)),
+	}
+
+	for i, productVariant := range product.Variants {
+		price := product.EffectivePrice
+		if productVariant.PriceOverride != nil {
+			price = *productVariant.PriceOverride
+		}
+
+		productPB.Variants[i] = &productpb.ProductVariant{
+			Id: productVariant.ID,
+			Sku: productVariant.SKU,
+			Options: productVariant.Options,
+			Price: price,
+			Stock: int32(productVariant.Stock),
+		}
+	}
+
+	for i, productBundleItem := range product.BundleItems {
+		productPB.BundleItems[i] = &productpb.BundleItem{
+			ProductId: productBundleItem.ProductID,
+			VariantId: productBundleItem.VariantID,
+			Quantity: int32(productBundleItem.Quantity),
+		}
+	}
+	return productPB
+}
+
+func productGRPCError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidProductBatch):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrFlashSaleNotRunning),
+		errors.Is(err, usecase.ErrFlashSaleLimitExceeded),
+		errors.Is(err, usecase.ErrFlashSaleSoldOut):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrWishlistItemNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -1,30 +1,51 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/usecase"
 	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
 	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+const productImageUploadTimeout = time.Minute
+
 type ProductHandler struct {
 	ProductUsecase usecase.ProductUsecase
+	Storefront config.StorefrontConfig
 }
 
-func NewProductHandler(productUsecase *usecase.ProductUsecase) *ProductHandler {
+func NewProductHandler(productUsecase *usecase.ProductUsecase, storefront config.StorefrontConfig) *ProductHandler {
 	return &ProductHandler{
 		ProductUsecase: *productUsecase,
+		Storefront: storefront,
 	}
 }
 
 func (h *ProductHandler) ProductManagement(c *gin.Context) {
+	// edit needs the body itself to tell the fields that were sent from the ones left out
+	body, err := c.GetRawData()
+	if err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
 	var param *models.ProductManagementParameter
-	if err := c.ShouldBindJSON(&param); err != nil {
+	if err := json.Unmarshal(body, &param); err != nil {
 		log.Logger.Error(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error_message": "Invalid input",
@@ -32,6 +53,14 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 		return
 	}
 
+	if param == nil {
+		log.Logger.Error("❌ Missing request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
 	if param.Action == "" {
 		log.Logger.Error("❌ Missing Action")
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -55,8 +84,8 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 			log.Logger.WithFields(logrus.Fields{
 				"param": param,
 			}).Errorf("❌ h.ProductUsecase.CreateNewProduct got an error at %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error_message": err,
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -75,13 +104,13 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 			return
 		}
 
-		product, err := h.ProductUsecase.UpdateProduct(c.Request.Context(), &param.Product)
+		product, err := h.ProductUsecase.ReplaceProduct(c.Request.Context(), param.ID, body)
 		if err != nil {
 			log.Logger.WithFields(logrus.Fields{
 				"params": param,
-			}).Errorf("❌ h.ProductUsecase.UpdateProduct got an error at %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error_message": err,
+			}).Errorf("❌ h.ProductUsecase.ReplaceProduct got an error at %v", err)
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -106,8 +135,8 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 			log.Logger.WithFields(logrus.Fields{
 				"params": param,
 			}).Errorf("❌ h.ProductUsecase.DeleteProduct got an error at %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error_message": err,
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -128,8 +157,11 @@ func (h *ProductHandler) ProductManagement(c *gin.Context) {
 }
 
 func (h *ProductHandler) ProductCategoryManagement(c *gin.Context) {
-	var param *models.ProductCategoryManagementParameter
-	if err := c.ShouldBindJSON(&param); err != nil {
+	// a category is active unless the request says otherwise
+	param := &models.ProductCategoryManagementParameter{
+		ProductCategory: models.ProductCategory{IsActive: true},
+	}
+	if err := c.ShouldBindJSON(param); err != nil {
 		log.Logger.Error(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error_message": "Invalid input",
@@ -159,8 +191,8 @@ func (h *ProductHandler) ProductCategoryManagement(c *gin.Context) {
 			log.Logger.WithFields(logrus.Fields{
 				"param": param,
 			}).Errorf("❌ h.ProductUsecase.CreateNewProductCategory got an error at %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error_message": err,
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -178,13 +210,13 @@ func (h *ProductHandler) ProductCategoryManagement(c *gin.Context) {
 			})
 			return
 		}
-		productCategory, err := h.ProductUsecase.UpdateProductCategory(c.Request.Context(), &param.ProductCategory)
+		productCategory, err := h.ProductUsecase.RenameProductCategory(c.Request.Context(), param.ID, param.Name)
 		if err != nil {
 			log.Logger.WithFields(logrus.Fields{
 				"params": param,
-			}).Errorf("❌ h.ProductUsecase.UpdateProductCategory got an error at %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error_message": err,
+			}).Errorf("❌ h.ProductUsecase.RenameProductCategory got an error at %v", err)
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -204,12 +236,12 @@ func (h *ProductHandler) ProductCategoryManagement(c *gin.Context) {
 			return
 		}
 
-		if err := h.ProductUsecase.DeleteProductCategory(c.Request.Context(), param.ID); err != nil {
+		if err := h.ProductUsecase.DeleteProductCategory(c.Request.Context(), param.ID, param.ReassignTo); err != nil {
 			log.Logger.WithFields(logrus.Fields{
 				"params": param,
 			}).Errorf("❌ h.ProductUsecase.DeleteProductCategory got an error at %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error_message": err,
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
 			})
 			return
 		}
@@ -228,6 +260,153 @@ func (h *ProductHandler) ProductCategoryManagement(c *gin.Context) {
 	}
 }
 
+func (h *ProductHandler) ProductVariantManagement(c *gin.Context) {
+	// edit needs the body itself to tell the fields that were sent from the ones left out
+	body, err := c.GetRawData()
+	if err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	var param *models.ProductVariantManagementParameter
+	if err := json.Unmarshal(body, &param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if param == nil {
+		log.Logger.Error("❌ Missing request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if param.Action == "" {
+		log.Logger.Error("❌ Missing Action")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Missing parameter action",
+		})
+		return
+	}
+
+	switch param.Action {
+	case "add":
+		if param.ID != 0 || param.ProductID == 0 {
+			log.Logger.Error("❌ Invalid request, params ID must be empty and product ID must be set")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": "Invalid request",
+			})
+			return
+		}
+
+		productVariantID, err := h.ProductUsecase.CreateNewProductVariant(c.Request.Context(), &param.ProductVariant)
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"param": param,
+			}).Errorf("❌ h.ProductUsecase.CreateNewProductVariant got an error at %v", err)
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message": fmt.Sprintf("Successfully created new product variant %d", productVariantID),
+		})
+		return
+
+	case "edit":
+		if param.ID == 0 {
+			log.Logger.Error("❌ Params ID is missing")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": "Invalid request",
+			})
+			return
+		}
+
+		productVariant, err := h.ProductUsecase.ReplaceProductVariant(c.Request.Context(), param.ID, body)
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"params": param,
+			}).Errorf("❌ h.ProductUsecase.ReplaceProductVariant got an error at %v", err)
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "success updating product variant",
+			"productVariant": productVariant,
+		})
+		return
+
+	case "delete":
+		if param.ID == 0 {
+			log.Logger.Error("❌ Params ID is missing")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": "Invalid request",
+			})
+			return
+		}
+
+		if err := h.ProductUsecase.DeleteProductVariant(c.Request.Context(), param.ID); err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"params": param,
+			}).Errorf("❌ h.ProductUsecase.DeleteProductVariant got an error at %v", err)
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("Successfully deleted product variant %d", param.ID),
+		})
+		return
+
+	default:
+		log.Logger.Errorf("❌ Invalid Action %v", param.Action)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid action",
+		})
+		return
+	}
+}
+
+func (h *ProductHandler) GetProducts(c *gin.Context) {
+	var param models.ProductListParameter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid query parameter",
+		})
+		return
+	}
+	param.RawFilters = c.QueryMap("filter")
+	param.Locale = negotiateLocale(c)
+
+	products, err := h.ProductUsecase.GetProducts(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUsecase.GetProducts got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
 func (h *ProductHandler) GetProductInfo(c *gin.Context) {
 	productIDStr := c.Param("id")
 	productID, err := strconv.ParseInt(productIDStr, 10, 64)
@@ -241,7 +420,7 @@ func (h *ProductHandler) GetProductInfo(c *gin.Context) {
 		return
 	}
 
-	product, err := h.ProductUsecase.GetProductByID(c.Request.Context(), productID)
+	product, err := h.ProductUsecase.GetProductByID(c.Request.Context(), productID, negotiateLocale(c))
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
 			"productID": productID,
@@ -254,11 +433,22 @@ func (h *ProductHandler) GetProductInfo(c *gin.Context) {
 
 	if product.ID == 0 {
 		log.Logger.WithFields(logrus.Fields{
-			"productID": "ProductID not found",
+			"productID": productID,
 		}).Info("Product ID not found")
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusNotFound, gin.H{
 			"error_message": "Product not exist",
 		})
+		return
+	}
+
+	// only requests made with a token fill the recently viewed list
+	if userID, ok := userIDFromContext(c); ok {
+		if err = h.ProductUsecase.RecordProductView(c.Request.Context(), userID, product); err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"userID": userID,
+				"productID": productID,
+			}).Errorf("h.ProductUsecase.RecordProductView got an error at %v", err)
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -271,14 +461,15 @@ func (h *ProductHandler) GetProductCategoryInfo(c *gin.Context) {
 	productCategoryID, err := strconv.Atoi(productCategoryIDStr)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
-			"productCategoryID": productCategoryID,
+			"productCategoryID": productCategoryIDStr,
 		}).Errorf("strconv.Atoi got an error at %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error_message": "Invalid product category ID",
 		})
+		return
 	}
 
-	productCategory, err := h.ProductUsecase.GetProductCategoryByID(c.Request.Context(), productCategoryID)
+	productCategory, err := h.ProductUsecase.GetProductCategoryByID(c.Request.Context(), productCategoryID, negotiateLocale(c))
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
 			"productCategoryID": productCategoryID,
@@ -293,7 +484,7 @@ func (h *ProductHandler) GetProductCategoryInfo(c *gin.Context) {
 		log.Logger.WithFields(logrus.Fields{
 			"productCategoryID": productCategoryID,
 		}).Info("Product Category ID not found")
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusNotFound, gin.H{
 			"error_message": "Product category ID not exist",
 		})
 		return
@@ -302,4 +493,745 @@ func (h *ProductHandler) GetProductCategoryInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"Product Category": productCategory,
 	})
-}
\ No newline at end of file
+}
+
+func (h *ProductHandler) GetProductCategoryTree(c *gin.Context) {
+	includeInactive, err := strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+	if err != nil {
+		log.Logger.Errorf("❌ Invalid include_inactive %v", c.Query("include_inactive"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid include_inactive",
+		})
+		return
+	}
+
+	tree, err := h.ProductUsecase.GetProductCategoryTree(c.Request.Context(), includeInactive, negotiateLocale(c))
+	if err != nil {
+		log.Logger.Errorf("h.ProductUsecase.GetProductCategoryTree got an error at %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": tree,
+	})
+}
+
+func (h *ProductHandler) GetProductCategoryBreadcrumb(c *gin.Context) {
+	productCategoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": c.Param("id"),
+		}).Errorf("strconv.Atoi got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product category ID",
+		})
+		return
+	}
+
+	breadcrumb, err := h.ProductUsecase.GetProductCategoryBreadcrumb(c.Request.Context(), productCategoryID, negotiateLocale(c))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+		}).Errorf("h.ProductUsecase.GetProductCategoryBreadcrumb got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"breadcrumb": breadcrumb,
+	})
+}
+
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Missing import file",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", strings.ToLower(strings.TrimPrefix(filepath.Ext(fileHeader.Filename), ".")))
+	if !usecase.IsSupportedCatalogFormat(format) {
+		log.Logger.Errorf("❌ Invalid import format %v", format)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid format, use csv or ndjson",
+		})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		log.Logger.Errorf("❌ Invalid dry_run %v", c.Query("dry_run"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid dry_run",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Logger.Errorf("fileHeader.Open got an error at %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	// RequestLogger caps every request at 2 seconds, a large catalog file takes longer to import
+	ctx := context.WithoutCancel(c.Request.Context())
+	result, err := h.ProductUsecase.ImportProducts(ctx, format, file, dryRun)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"filename": fileHeader.Filename,
+			"format": format,
+		}).Errorf("❌ h.ProductUsecase.ImportProducts got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result": result,
+	})
+}
+
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", usecase.CatalogFormatCSV)
+	if !usecase.IsSupportedCatalogFormat(format) {
+		log.Logger.Errorf("❌ Invalid export format %v", format)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid format, use csv or ndjson",
+		})
+		return
+	}
+
+	contentType := "text/csv"
+	if format == usecase.CatalogFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+	c.Status(http.StatusOK)
+
+	// RequestLogger caps every request at 2 seconds, far too short to stream the whole catalog
+	ctx := context.WithoutCancel(c.Request.Context())
+	if err := h.ProductUsecase.ExportProducts(ctx, format, c.Writer); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"format": format,
+		}).Errorf("❌ h.ProductUsecase.ExportProducts got an error at %v", err)
+	}
+}
+
+func (h *ProductHandler) UploadProductImage(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Missing image file",
+		})
+		return
+	}
+
+	if fileHeader.Size > usecase.MaxProductImageSize {
+		log.Logger.Errorf("❌ Image %s is too large: %d bytes", fileHeader.Filename, fileHeader.Size)
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error_message": fmt.Sprintf("Image must not exceed %d MB", usecase.MaxProductImageSize>>20),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Logger.Errorf("fileHeader.Open got an error at %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	// RequestLogger caps every request at 2 seconds, decoding, resizing and uploading a large image takes longer
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), productImageUploadTimeout)
+	defer cancel()
+
+	productImage, err := h.ProductUsecase.UploadProductImage(ctx, productID, file)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"filename": fileHeader.Filename,
+		}).Errorf("❌ h.ProductUsecase.UploadProductImage got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"image": productImage,
+	})
+}
+
+func (h *ProductHandler) ReorderProductImages(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	var param models.ReorderProductImagesParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if err := h.ProductUsecase.ReorderProductImages(c.Request.Context(), productID, param.ImageIDs); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"param": param,
+		}).Errorf("❌ h.ProductUsecase.ReorderProductImages got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success reordering product images",
+	})
+}
+
+func (h *ProductHandler) DeleteProductImage(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	productImageID, err := strconv.ParseInt(c.Param("image_id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productImageID": c.Param("image_id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid image ID",
+		})
+		return
+	}
+
+	if err := h.ProductUsecase.DeleteProductImage(c.Request.Context(), productID, productImageID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"productImageID": productImageID,
+		}).Errorf("❌ h.ProductUsecase.DeleteProductImage got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Successfully deleted product image %d", productImageID),
+	})
+}
+
+func (h *ProductHandler) UpdateProductStatus(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	var param models.ProductStatusParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	product, err := h.ProductUsecase.UpdateProductStatus(c.Request.Context(), productID, param.Status)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"param": param,
+		}).Errorf("❌ h.ProductUsecase.UpdateProductStatus got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Successfully moved product %d to %s", productID, product.Status),
+		"product": product,
+	})
+}
+
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	product, err := h.ProductUsecase.RestoreProduct(c.Request.Context(), productID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("❌ h.ProductUsecase.RestoreProduct got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Successfully restored product %d as draft", productID),
+		"product": product,
+	})
+}
+
+func (h *ProductHandler) ScheduleProductPrice(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	var param models.ProductPriceScheduleParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	productPriceID, err := h.ProductUsecase.ScheduleProductPrice(c.Request.Context(), productID, &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"param": param,
+		}).Errorf("❌ h.ProductUsecase.ScheduleProductPrice got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Successfully scheduled %s price %d", param.Kind, productPriceID),
+	})
+}
+
+// GetProductPrices returns the whole price history, or the price at one moment when "at" is given.
+func (h *ProductHandler) GetProductPrices(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	if atStr := c.Query("at"); atStr != "" {
+		at, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			log.Logger.Errorf("❌ Invalid at %v", atStr)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": "Invalid at, use RFC3339 like 2024-05-14T10:00:00Z",
+			})
+			return
+		}
+
+		productPrice, err := h.ProductUsecase.GetProductPriceAt(c.Request.Context(), productID, at)
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"productID": productID,
+				"at": atStr,
+			}).Errorf("h.ProductUsecase.GetProductPriceAt got an error at %v", err)
+			c.JSON(productErrorStatus(err), gin.H{
+				"error_message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"price": productPrice,
+		})
+		return
+	}
+
+	productPrices, err := h.ProductUsecase.GetProductPriceHistory(c.Request.Context(), productID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("h.ProductUsecase.GetProductPriceHistory got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"prices": productPrices,
+	})
+}
+
+func (h *ProductHandler) CreateProductReview(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	var param models.ProductReviewParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	productReviewID, err := h.ProductUsecase.CreateProductReview(c.Request.Context(), productID, userID, &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"userID": userID,
+		}).Errorf("❌ h.ProductUsecase.CreateProductReview got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Successfully submitted review %d, it will be visible once approved", productReviewID),
+	})
+}
+
+func (h *ProductHandler) GetProductReviews(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	var param models.ProductReviewListParameter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid query parameter",
+		})
+		return
+	}
+
+	// customers only ever see approved reviews
+	param.ProductID = productID
+	param.Status = models.ReviewStatusApproved
+
+	productReviews, err := h.ProductUsecase.GetProductReviews(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUsecase.GetProductReviews got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, productReviews)
+}
+
+func (h *ProductHandler) GetProductReviewsForModeration(c *gin.Context) {
+	var param models.ProductReviewListParameter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid query parameter",
+		})
+		return
+	}
+
+	if param.Status == "" {
+		param.Status = models.ReviewStatusPending
+	}
+
+	productReviews, err := h.ProductUsecase.GetProductReviews(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUsecase.GetProductReviews got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, productReviews)
+}
+
+func (h *ProductHandler) UpdateProductReviewStatus(c *gin.Context) {
+	productReviewID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productReviewID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid review ID",
+		})
+		return
+	}
+
+	var param models.ProductReviewStatusParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	productReview, err := h.ProductUsecase.UpdateProductReviewStatus(c.Request.Context(), productReviewID, param.Status)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productReviewID": productReviewID,
+			"param": param,
+		}).Errorf("❌ h.ProductUsecase.UpdateProductReviewStatus got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Successfully moved review %d to %s", productReviewID, productReview.Status),
+		"review": productReview,
+	})
+}
+
+func (h *ProductHandler) VoteProductReview(c *gin.Context) {
+	h.setProductReviewVote(c, true)
+}
+
+func (h *ProductHandler) UnvoteProductReview(c *gin.Context) {
+	h.setProductReviewVote(c, false)
+}
+
+func (h *ProductHandler) setProductReviewVote(c *gin.Context, helpful bool) {
+	productReviewID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productReviewID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid review ID",
+		})
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	if err := h.ProductUsecase.SetProductReviewVote(c.Request.Context(), productReviewID, userID, helpful); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productReviewID": productReviewID,
+			"userID": userID,
+			"helpful": helpful,
+		}).Errorf("❌ h.ProductUsecase.SetProductReviewVote got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success updating helpful vote",
+	})
+}
+
+func userIDFromContext(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		return 0, false
+	}
+
+	userIDFloat, ok := userID.(float64)
+	if !ok || userIDFloat == 0 {
+		return 0, false
+	}
+	return int64(userIDFloat), true
+}
+
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrProductNotFound),
+		errors.Is(err, usecase.ErrProductVariantNotFound),
+		errors.Is(err, usecase.ErrProductImageNotFound),
+		errors.Is(err, usecase.ErrProductCategoryNotFound),
+		errors.Is(err, usecase.ErrProductPriceNotFound),
+		errors.Is(err, usecase.ErrProductReviewNotFound),
+		errors.Is(err, usecase.ErrProductStockSubscriptionNotFound),
+		errors.Is(err, usecase.ErrProductAttributeNotFound),
+		errors.Is(err, usecase.ErrFlashSaleNotFound),
+		errors.Is(err, usecase.ErrTranslationNotFound),
+		errors.Is(err, usecase.ErrWarehouseNotFound),
+		errors.Is(err, usecase.ErrWishlistItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidProduct),
+		errors.Is(err, usecase.ErrInvalidProductVariant),
+		errors.Is(err, usecase.ErrInvalidProductImage),
+		errors.Is(err, usecase.ErrInvalidProductCategory),
+		errors.Is(err, usecase.ErrInvalidProductStatus),
+		errors.Is(err, usecase.ErrInvalidProductPrice),
+		errors.Is(err, usecase.ErrInvalidProductReview),
+		errors.Is(err, usecase.ErrInvalidProductAttribute),
+		errors.Is(err, usecase.ErrInvalidProductFilter),
+		errors.Is(err, usecase.ErrInvalidFlashSale),
+		errors.Is(err, usecase.ErrInvalidTranslation),
+		errors.Is(err, usecase.ErrInvalidWarehouse),
+		errors.Is(err, usecase.ErrInvalidWishlistItem):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrProductReviewNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrProductCategoryInUse),
+		errors.Is(err, usecase.ErrProductReviewExists),
+		errors.Is(err, usecase.ErrProductInStock),
+		errors.Is(err, usecase.ErrFlashSaleNotRunning),
+		errors.Is(err, usecase.ErrFlashSaleLimitExceeded),
+		errors.Is(err, usecase.ErrFlashSaleSoldOut):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrProductImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *ProductHandler) SubscribeProductStock(c *gin.Context) {
+	h.setProductStockSubscription(c, true)
+}
+
+func (h *ProductHandler) UnsubscribeProductStock(c *gin.Context) {
+	h.setProductStockSubscription(c, false)
+}
+
+func (h *ProductHandler) setProductStockSubscription(c *gin.Context, subscribe bool) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	var err error
+	if subscribe {
+		err = h.ProductUsecase.SubscribeProductStock(c.Request.Context(), productID, userID)
+	} else {
+		err = h.ProductUsecase.UnsubscribeProductStock(c.Request.Context(), productID, userID)
+	}
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"userID": userID,
+			"subscribe": subscribe,
+		}).Errorf("❌ h.ProductUsecase.SetProductStockSubscription got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	if !subscribe {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success subscribing to back in stock notification",
+	})
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) GetRelatedProducts(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return
+	}
+
+	var param models.RelatedProductParameter
+	if err = c.ShouldBindQuery(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid query parameter",
+		})
+		return
+	}
+
+	products, err := h.ProductUsecase.GetRelatedProducts(c.Request.Context(), productID, &param, negotiateLocale(c))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("h.ProductUsecase.GetRelatedProducts got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": products,
+	})
+}
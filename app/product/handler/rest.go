@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	var product models.Product
+	if err := c.ShouldBindJSON(&product); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if product.ID != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "ID is assigned by the server and must be empty",
+		})
+		return
+	}
+
+	productID, err := h.ProductUsecase.CreateNewProduct(c.Request.Context(), &product)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"product": product,
+		}).Errorf("❌ h.ProductUsecase.CreateNewProduct got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	createdProduct, err := h.ProductUsecase.GetProductByID(c.Request.Context(), productID, models.DefaultLocale)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("h.ProductUsecase.GetProductByID got an error at %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/v1/products/%d", productID))
+	c.JSON(http.StatusCreated, gin.H{
+		"product": createdProduct,
+	})
+}
+
+func (h *ProductHandler) ReplaceProduct(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil || len(body) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	updatedProduct, err := h.ProductUsecase.ReplaceProduct(c.Request.Context(), productID, body)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("❌ h.ProductUsecase.ReplaceProduct got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product": updatedProduct,
+	})
+}
+
+func (h *ProductHandler) PatchProduct(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil || len(patch) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	updatedProduct, err := h.ProductUsecase.PatchProduct(c.Request.Context(), productID, patch)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("❌ h.ProductUsecase.PatchProduct got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product": updatedProduct,
+	})
+}
+
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ProductUsecase.DeleteProduct(c.Request.Context(), productID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("❌ h.ProductUsecase.DeleteProduct got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ProductHandler) CreateProductVariant(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	var productVariant models.ProductVariant
+	if err := c.ShouldBindJSON(&productVariant); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if productVariant.ID != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "ID is assigned by the server and must be empty",
+		})
+		return
+	}
+	productVariant.ProductID = productID
+
+	productVariantID, err := h.ProductUsecase.CreateNewProductVariant(c.Request.Context(), &productVariant)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productVariant": productVariant,
+		}).Errorf("❌ h.ProductUsecase.CreateNewProductVariant got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+	productVariant.ID = productVariantID
+
+	c.Header("Location", fmt.Sprintf("/v1/products/%d/variants/%d", productID, productVariantID))
+	c.JSON(http.StatusCreated, gin.H{
+		"productVariant": productVariant,
+	})
+}
+
+func (h *ProductHandler) ReplaceProductVariant(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	productVariantID, ok := parseProductVariantID(c)
+	if !ok {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil || len(body) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if _, err := h.ProductUsecase.GetProductVariantOfProduct(c.Request.Context(), productID, productVariantID); err != nil {
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	updatedProductVariant, err := h.ProductUsecase.ReplaceProductVariant(c.Request.Context(), productVariantID, body)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"productVariantID": productVariantID,
+		}).Errorf("❌ h.ProductUsecase.ReplaceProductVariant got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"productVariant": updatedProductVariant,
+	})
+}
+
+func (h *ProductHandler) PatchProductVariant(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	productVariantID, ok := parseProductVariantID(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil || len(patch) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	updatedProductVariant, err := h.ProductUsecase.PatchProductVariant(c.Request.Context(), productID, productVariantID, patch)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"productVariantID": productVariantID,
+		}).Errorf("❌ h.ProductUsecase.PatchProductVariant got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"productVariant": updatedProductVariant,
+	})
+}
+
+func (h *ProductHandler) DeleteProductVariant(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	productVariantID, ok := parseProductVariantID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.ProductUsecase.GetProductVariantOfProduct(c.Request.Context(), productID, productVariantID); err != nil {
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.ProductUsecase.DeleteProductVariant(c.Request.Context(), productVariantID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"productVariantID": productVariantID,
+		}).Errorf("❌ h.ProductUsecase.DeleteProductVariant got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ProductHandler) CreateProductCategory(c *gin.Context) {
+	// a category is active unless the request says otherwise
+	productCategory := models.ProductCategory{IsActive: true}
+	if err := c.ShouldBindJSON(&productCategory); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if productCategory.ID != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "ID is assigned by the server and must be empty",
+		})
+		return
+	}
+
+	productCategoryID, err := h.ProductUsecase.CreateNewProductCategory(c.Request.Context(), &productCategory)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategory": productCategory,
+		}).Errorf("❌ h.ProductUsecase.CreateNewProductCategory got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/v1/categories/%d", productCategoryID))
+	c.JSON(http.StatusCreated, gin.H{
+		"category": productCategory,
+	})
+}
+
+func (h *ProductHandler) ReplaceProductCategory(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	// a category is active unless the request says otherwise
+	productCategory := models.ProductCategory{IsActive: true}
+	if err := c.ShouldBindJSON(&productCategory); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+	productCategory.ID = productCategoryID
+
+	updatedProductCategory, err := h.ProductUsecase.UpdateProductCategory(c.Request.Context(), &productCategory)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategory": productCategory,
+		}).Errorf("❌ h.ProductUsecase.UpdateProductCategory got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category": updatedProductCategory,
+	})
+}
+
+func (h *ProductHandler) PatchProductCategory(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil || len(patch) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	updatedProductCategory, err := h.ProductUsecase.PatchProductCategory(c.Request.Context(), productCategoryID, patch)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+		}).Errorf("❌ h.ProductUsecase.PatchProductCategory got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category": updatedProductCategory,
+	})
+}
+
+// DeleteProductCategory takes ?reassign_to=ID to move the products and child categories before deleting.
+func (h *ProductHandler) DeleteProductCategory(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	reassignTo, err := strconv.Atoi(c.DefaultQuery("reassign_to", "0"))
+	if err != nil {
+		log.Logger.Errorf("❌ Invalid reassign_to %v", c.Query("reassign_to"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid reassign_to",
+		})
+		return
+	}
+
+	if err := h.ProductUsecase.DeleteProductCategory(c.Request.Context(), productCategoryID, reassignTo); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+			"reassignTo": reassignTo,
+		}).Errorf("❌ h.ProductUsecase.DeleteProductCategory got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseProductID writes the 400 response itself, callers only need to return when it fails.
+func parseProductID(c *gin.Context) (int64, bool) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product ID",
+		})
+		return 0, false
+	}
+	return productID, true
+}
+
+func parseProductCategoryID(c *gin.Context) (int, bool) {
+	productCategoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productCategoryID <= 0 {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": c.Param("id"),
+		}).Errorf("strconv.Atoi got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product category ID",
+		})
+		return 0, false
+	}
+	return productCategoryID, true
+}
+
+func parseProductVariantID(c *gin.Context) (int64, bool) {
+	productVariantID, err := strconv.ParseInt(c.Param("variant_id"), 10, 64)
+	if err != nil || productVariantID <= 0 {
+		log.Logger.WithFields(logrus.Fields{
+			"productVariantID": c.Param("variant_id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid product variant ID",
+		})
+		return 0, false
+	}
+	return productVariantID, true
+}
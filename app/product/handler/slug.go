@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapURLSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns string `xml:"xmlns,attr"`
+	URLs []sitemapLocation `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName xml.Name `xml:"sitemapindex"`
+	Xmlns string `xml:"xmlns,attr"`
+	Sitemaps []sitemapLocation `xml:"sitemap"`
+}
+
+type sitemapLocation struct {
+	Loc string `xml:"loc"`
+}
+
+// GetProductBySlug redirects a slug the product gave up to its current slug.
+func (h *ProductHandler) GetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	product, currentSlug, err := h.ProductUsecase.GetProductBySlug(c.Request.Context(), slug, negotiateLocale(c))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"slug": slug,
+		}).Errorf("h.ProductUsecase.GetProductBySlug got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	if currentSlug != "" {
+		c.Redirect(http.StatusMovedPermanently, "/v1/products/by-slug/"+url.PathEscape(currentSlug))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product": product,
+	})
+}
+
+func (h *ProductHandler) GetProductCategoryBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	productCategory, currentSlug, err := h.ProductUsecase.GetProductCategoryBySlug(c.Request.Context(), slug, negotiateLocale(c))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"slug": slug,
+		}).Errorf("h.ProductUsecase.GetProductCategoryBySlug got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	if currentSlug != "" {
+		c.Redirect(http.StatusMovedPermanently, "/v1/categories/by-slug/"+url.PathEscape(currentSlug))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category": productCategory,
+	})
+}
+
+// GetSitemap lists the storefront URL of every published product. Past one sitemap file worth of
+// products it returns a sitemap index, and ?page=n returns one file of it.
+func (h *ProductHandler) GetSitemap(c *gin.Context) {
+	page := 0
+	if c.Query("page") != "" {
+		var err error
+		page, err = strconv.Atoi(c.Query("page"))
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"page": c.Query("page"),
+			}).Errorf("strconv.Atoi got an error at %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error_message": "Invalid page",
+			})
+			return
+		}
+	}
+
+	products, pages, err := h.ProductUsecase.GetProductSitemap(c.Request.Context(), page)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"page": page,
+		}).Errorf("h.ProductUsecase.GetProductSitemap got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	baseURL := strings.TrimRight(h.Storefront.BaseURL, "/")
+
+	var document interface{}
+	if page == 0 && pages > 1 {
+		index := sitemapIndex{
+			Xmlns: sitemapNamespace,
+			Sitemaps: make([]sitemapLocation, pages),
+		}
+		for i := range index.Sitemaps {
+			index.Sitemaps[i].Loc = fmt.Sprintf("%s/sitemap.xml?page=%d", baseURL, i+1)
+		}
+		document = index
+	} else {
+		urlSet := sitemapURLSet{
+			Xmlns: sitemapNamespace,
+			URLs: make([]sitemapLocation, len(products)),
+		}
+		for i, product := range products {
+			urlSet.URLs[i].Loc = baseURL + "/products/" + url.PathEscape(product.Slug)
+		}
+		document = urlSet
+	}
+
+	body, err := xml.Marshal(document)
+	if err != nil {
+		log.Logger.Errorf("xml.Marshal got an error at %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// negotiateLocale picks the locale of a read from ?locale= first, then from Accept-Language, and falls
+// back to DefaultLocale. Only the primary subtag counts, so en-US is served as en. The picked locale is
+// announced in Content-Language, and Vary keeps shared caches from mixing up the locales.
+func negotiateLocale(c *gin.Context) string {
+	locale := matchLocale(c.Query("locale"))
+	if locale == "" {
+		locale = matchAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+
+	if locale == "" {
+		locale = models.DefaultLocale
+	}
+
+	c.Header("Content-Language", locale)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return locale
+}
+
+func matchLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i != -1 {
+		tag = tag[:i]
+	}
+
+	if !models.IsSupportedLocale(tag) {
+		return ""
+	}
+	return tag
+}
+
+// matchAcceptLanguage returns the supported locale with the highest q value, or "" when none is accepted.
+func matchAcceptLanguage(header string) string {
+	type acceptedLanguage struct {
+		tag string
+		q float64
+	}
+
+	var acceptedLanguages []acceptedLanguage
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		accepted := acceptedLanguage{tag: strings.TrimSpace(fields[0]), q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				q = 0
+			}
+			accepted.q = q
+		}
+
+		if accepted.tag == "" || accepted.q <= 0 {
+			continue
+		}
+		acceptedLanguages = append(acceptedLanguages, accepted)
+	}
+
+	sort.SliceStable(acceptedLanguages, func(i, j int) bool {
+		return acceptedLanguages[i].q > acceptedLanguages[j].q
+	})
+
+	for _, accepted := range acceptedLanguages {
+		if accepted.tag == "*" {
+			return models.DefaultLocale
+		}
+
+		if locale := matchLocale(accepted.tag); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+func (h *ProductHandler) SaveProductTranslation(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	var productTranslation models.ProductTranslation
+	if err := c.ShouldBindJSON(&productTranslation); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+	productTranslation.ID = 0
+	productTranslation.ProductID = productID
+	productTranslation.Locale = c.Param("locale")
+
+	if err := h.ProductUsecase.SaveProductTranslation(c.Request.Context(), &productTranslation); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"locale": productTranslation.Locale,
+		}).Errorf("h.ProductUsecase.SaveProductTranslation got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"translation": productTranslation,
+	})
+}
+
+func (h *ProductHandler) DeleteProductTranslation(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	locale := c.Param("locale")
+	if err := h.ProductUsecase.DeleteProductTranslation(c.Request.Context(), productID, locale); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"locale": locale,
+		}).Errorf("h.ProductUsecase.DeleteProductTranslation got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ProductHandler) SaveProductCategoryTranslation(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	var productCategoryTranslation models.ProductCategoryTranslation
+	if err := c.ShouldBindJSON(&productCategoryTranslation); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+	productCategoryTranslation.ID = 0
+	productCategoryTranslation.CategoryID = productCategoryID
+	productCategoryTranslation.Locale = c.Param("locale")
+
+	if err := h.ProductUsecase.SaveProductCategoryTranslation(c.Request.Context(), &productCategoryTranslation); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+			"locale": productCategoryTranslation.Locale,
+		}).Errorf("h.ProductUsecase.SaveProductCategoryTranslation got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"translation": productCategoryTranslation,
+	})
+}
+
+func (h *ProductHandler) DeleteProductCategoryTranslation(c *gin.Context) {
+	productCategoryID, ok := parseProductCategoryID(c)
+	if !ok {
+		return
+	}
+
+	locale := c.Param("locale")
+	if err := h.ProductUsecase.DeleteProductCategoryTranslation(c.Request.Context(), productCategoryID, locale); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+			"locale": locale,
+		}).Errorf("h.ProductUsecase.DeleteProductCategoryTranslation got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
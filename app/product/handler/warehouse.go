@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) GetWarehouses(c *gin.Context) {
+	warehouses, err := h.ProductUsecase.GetWarehouses(c.Request.Context())
+	if err != nil {
+		log.Logger.Errorf("h.ProductUsecase.GetWarehouses got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"warehouses": warehouses,
+	})
+}
+
+func (h *ProductHandler) CreateWarehouse(c *gin.Context) {
+	var param models.Warehouse
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	warehouse, err := h.ProductUsecase.CreateWarehouse(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUsecase.CreateWarehouse got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"warehouse": warehouse,
+	})
+}
+
+func (h *ProductHandler) UpdateWarehouse(c *gin.Context) {
+	warehouseID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"warehouseID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid warehouse ID",
+		})
+		return
+	}
+
+	var param models.Warehouse
+	if err = c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	param.ID = warehouseID
+	warehouse, err := h.ProductUsecase.UpdateWarehouse(c.Request.Context(), &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.ProductUsecase.UpdateWarehouse got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"warehouse": warehouse,
+	})
+}
+
+func (h *ProductHandler) SetWarehouseStock(c *gin.Context) {
+	warehouseID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"warehouseID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid warehouse ID",
+		})
+		return
+	}
+
+	var param models.WarehouseStockParameter
+	if err = c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	if err = h.ProductUsecase.SetWarehouseStock(c.Request.Context(), warehouseID, &param); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"warehouseID": warehouseID,
+			"param": param,
+		}).Errorf("h.ProductUsecase.SetWarehouseStock got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success update warehouse stock",
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *ProductHandler) GetWishlist(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	wishlistItems, err := h.ProductUsecase.GetWishlist(c.Request.Context(), userID, negotiateLocale(c))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("h.ProductUsecase.GetWishlist got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wishlist": wishlistItems,
+	})
+}
+
+func (h *ProductHandler) AddWishlistItem(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	var param models.WishlistParameter
+	if err := c.ShouldBindJSON(&param); err != nil {
+		log.Logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid input",
+		})
+		return
+	}
+
+	wishlistItem, err := h.ProductUsecase.AddWishlistItem(c.Request.Context(), userID, &param)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"param": param,
+		}).Errorf("h.ProductUsecase.AddWishlistItem got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"wishlist_item": wishlistItem,
+	})
+}
+
+func (h *ProductHandler) RemoveWishlistItem(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	wishlistItemID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"wishlistItemID": c.Param("id"),
+		}).Errorf("strconv.ParseInt got an error at %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error_message": "Invalid wishlist item ID",
+		})
+		return
+	}
+
+	if err = h.ProductUsecase.RemoveWishlistItem(c.Request.Context(), userID, wishlistItemID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"wishlistItemID": wishlistItemID,
+		}).Errorf("h.ProductUsecase.RemoveWishlistItem got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success remove wishlist item",
+	})
+}
+
+func (h *ProductHandler) GetRecentlyViewedProducts(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error_message": "Invalid user id",
+		})
+		return
+	}
+
+	products, err := h.ProductUsecase.GetRecentlyViewedProducts(c.Request.Context(), userID, negotiateLocale(c))
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+		}).Errorf("h.ProductUsecase.GetRecentlyViewedProducts got an error at %v", err)
+		c.JSON(productErrorStatus(err), gin.H{
+			"error_message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": products,
+	})
+}
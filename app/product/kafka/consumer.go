@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxStockHandlerAttempts = 5
+	stockRetryBaseDelay = time.Second
+	stockRetryMaxDelay = time.Minute
+)
+
+// StartStockConsumer commits a message only after the handler is done with it. A message that keeps
+// failing is parked on the dead letter topic of topic, see models.DeadLetterTopic, and committed once the
+// dead letter is stored, so one bad order cannot block the partition and is never lost. The dead letter
+// keeps the original key and value, replaying it is publishing it to topic again.
+func StartStockConsumer(cfg config.KafkaConfig, topic string, deadLetterWriter *kafka.Writer, handler func(ctx context.Context, event *models.ProductStockUpdateEvent) error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cfg.Brokers,
+		GroupID: cfg.GroupID,
+		Topic: topic,
+	})
+
+	go func(r *kafka.Reader) {
+		log.Logger.Infof("✅ Listening to topic %s", topic)
+		ctx := context.Background()
+		for {
+			message, err := r.FetchMessage(ctx)
+			if err != nil {
+				log.Logger.Errorf("❌ Failed fetching message from %s: %v", topic, err)
+				time.Sleep(time.Second)
+				continue
+			}
+
+			var event models.ProductStockUpdateEvent
+			if err = json.Unmarshal(message.Value, &event); err != nil {
+				log.Logger.WithFields(logrus.Fields{
+					"topic": topic,
+					"offset": message.Offset,
+				}).Errorf("❌ json.Unmarshal got an error at %v", err)
+			} else {
+				err = handleStockEvent(ctx, topic, &event, handler)
+			}
+
+			if err != nil {
+				parkStockMessage(ctx, deadLetterWriter, message, err)
+			}
+
+			if err = r.CommitMessages(ctx, message); err != nil {
+				log.Logger.Errorf("❌ Failed committing message from %s: %v", topic, err)
+			}
+		}
+	}(reader)
+}
+
+// handleStockEvent retries with a doubling delay and returns the last error once the attempts run out.
+// An invalid event fails the same way every time, it is returned right away.
+func handleStockEvent(ctx context.Context, topic string, event *models.ProductStockUpdateEvent, handler func(ctx context.Context, event *models.ProductStockUpdateEvent) error) error {
+	var err error
+	delay := stockRetryBaseDelay
+	for attempt := 1; attempt <= maxStockHandlerAttempts; attempt++ {
+		if err = handler(ctx, event); err == nil {
+			return nil
+		}
+
+		if errors.Is(err, models.ErrInvalidProductStockEvent) {
+			return err
+		}
+
+		log.Logger.WithFields(logrus.Fields{
+			"topic": topic,
+			"orderID": event.OrderID,
+			"attempt": attempt,
+		}).Warnf("⚠️ Stock event failed, retrying in %v: %v", delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, stockRetryMaxDelay)
+	}
+	return err
+}
+
+// parkStockMessage does not give up, the message is only committed after its dead letter is stored.
+func parkStockMessage(ctx context.Context, writer *kafka.Writer, message kafka.Message, cause error) {
+	deadLetter := kafka.Message{
+		Topic: models.DeadLetterTopic(message.Topic),
+		Key: message.Key,
+		Value: message.Value,
+		Headers: append(message.Headers,
+			kafka.Header{Key: models.HeaderDeadLetterError, Value: []byte(cause.Error())},
+			kafka.Header{Key: models.HeaderDeadLetterTopic, Value: []byte(message.Topic)},
+			kafka.Header{Key: models.HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		),
+	}
+
+	delay := stockRetryBaseDelay
+	for {
+		err := writer.WriteMessages(ctx, deadLetter)
+		if err == nil {
+			log.Logger.WithFields(logrus.Fields{
+				"topic": message.Topic,
+				"offset": message.Offset,
+			}).Errorf("❌ Parked stock message on %s: %v", deadLetter.Topic, cause)
+			return
+		}
+
+		log.Logger.WithFields(logrus.Fields{
+			"topic": deadLetter.Topic,
+			"offset": message.Offset,
+		}).Errorf("❌ Failed parking stock message, retrying in %v: %v", delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, stockRetryMaxDelay)
+	}
+}
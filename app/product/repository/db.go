@@ -3,14 +3,25 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/PorcoGalliard/eCommerce-Microservice/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// productOutboxLockKey is the advisory lock of the outbox relay, any constant unique within the database works.
+	productOutboxLockKey = 7301001
+	// productStockOrderLockClass scopes the advisory locks of stock events to their order, see LockProductStockOrderTx
+	productStockOrderLockClass = 7301002
 )
 
 func (r *ProductRepository) FindProductByID(ctx context.Context, productID int64) (*models.Product, error) {
 	var product models.Product
-	err := r.Database.WithContext(ctx).Table("product").Where("id = ?", productID).Last(&product).Error
+	// deleted products are still read by ID, past orders keep pointing at them
+	err := r.Database.WithContext(ctx).Unscoped().Table("product").Where("id = ?", productID).Last(&product).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &models.Product{}, nil
@@ -20,6 +31,15 @@ func (r *ProductRepository) FindProductByID(ctx context.Context, productID int64
 	return &product, nil
 }
 
+func (r *ProductRepository) FindProductsByIDs(ctx context.Context, productIDs []int64) ([]models.Product, error) {
+	var products []models.Product
+	err := r.Database.WithContext(ctx).Unscoped().Table("product").Where("id IN ?", productIDs).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 func (r *ProductRepository) FindProductCategoryByID(ctx context.Context, productCategoryID int) (*models.ProductCategory, error) {
 	var productCategory models.ProductCategory
 	err := r.Database.WithContext(ctx).Table("product_category").Where("id = ?", productCategoryID).Last(&productCategory).Error
@@ -32,8 +52,8 @@ func (r *ProductRepository) FindProductCategoryByID(ctx context.Context, product
 	return &productCategory, nil
 }
 
-func (r *ProductRepository) InsertProduct(ctx context.Context, product *models.Product) (int64, error) {
-	err := r.Database.WithContext(ctx).Table("product").Create(product).Error
+func (r *ProductRepository) InsertProductTx(ctx context.Context, tx *gorm.DB, product *models.Product) (int64, error) {
+	err := tx.WithContext(ctx).Table("product").Create(product).Error
 	if err != nil {
 		return 0, err
 	}
@@ -48,34 +68,1438 @@ func (r *ProductRepository) InsertProductCategory(ctx context.Context, productCa
 	return productCategory.ID, nil
 }
 
-func (r *ProductRepository) UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
-	err := r.Database.WithContext(ctx).Table("product").Save(product).Error
+func (r *ProductRepository) UpdateProductColumnsTx(ctx context.Context, tx *gorm.DB, productID int64, columns map[string]interface{}) error {
+	err := tx.WithContext(ctx).Table("product").Where("id = ?", productID).Updates(columns).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return product, nil
+	return nil
 }
 
-func (r *ProductRepository) UpdateProductCategory(ctx context.Context, productCategory *models.ProductCategory) (*models.ProductCategory, error) {
-	err := r.Database.WithContext(ctx).Table("product_category").Save(productCategory).Error
+func (r *ProductRepository) UpdateProductCategoryTx(ctx context.Context, tx *gorm.DB, productCategory *models.ProductCategory) (*models.ProductCategory, error) {
+	err := tx.WithContext(ctx).Table("product_category").Save(productCategory).Error
 	if err != nil {
 		return nil, err
 	}
 	return productCategory, nil
 }
 
-func (r *ProductRepository) DeleteProduct(ctx context.Context, productID int64) error {
-	err := r.Database.WithContext(ctx).Table("product").Delete(&models.Product{}, productID).Error
+func (r *ProductRepository) UpdateProductCategoryName(ctx context.Context, productCategoryID int, name string) error {
+	err := r.Database.WithContext(ctx).Table("product_category").Where("id = ?", productCategoryID).Update("name", name).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) DeleteProductTx(ctx context.Context, tx *gorm.DB, productID int64) error {
+	err := tx.WithContext(ctx).Table("product").Delete(&models.Product{}, productID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) UpdateProductStatusTx(ctx context.Context, tx *gorm.DB, productID int64, status string) error {
+	err := tx.WithContext(ctx).Table("product").Where("id = ?", productID).Update("status", status).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) RestoreProductTx(ctx context.Context, tx *gorm.DB, productID int64) error {
+	err := tx.WithContext(ctx).Unscoped().Table("product").Where("id = ?", productID).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"status": models.ProductStatusDraft,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) DeleteProductCategoryTx(ctx context.Context, tx *gorm.DB, productCategoryID int) error {
+	err := tx.WithContext(ctx).Table("product_category_translation").Where("category_id = ?", productCategoryID).Delete(&models.ProductCategoryTranslation{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.WithContext(ctx).Table("product_category").Delete(&models.ProductCategory{}, productCategoryID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.Database.WithContext(ctx).Transaction(fn)
+}
+
+func (r *ProductRepository) FindProductCategoriesByNames(ctx context.Context, names []string) ([]models.ProductCategory, error) {
+	var productCategories []models.ProductCategory
+	err := r.Database.WithContext(ctx).Table("product_category").Where("LOWER(name) IN ?", names).Find(&productCategories).Error
+	if err != nil {
+		return nil, err
+	}
+	return productCategories, nil
+}
+
+func (r *ProductRepository) FindAllProductCategories(ctx context.Context) ([]models.ProductCategory, error) {
+	var productCategories []models.ProductCategory
+	err := r.Database.WithContext(ctx).Table("product_category").Order("sort_order, name").Find(&productCategories).Error
+	if err != nil {
+		return nil, err
+	}
+	return productCategories, nil
+}
+
+func (r *ProductRepository) FindProductsBySKUs(ctx context.Context, skus []string) ([]models.Product, error) {
+	var products []models.Product
+	err := r.Database.WithContext(ctx).Unscoped().Table("product").Where("sku IN ?", skus).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) FindProductsBySKUsForUpdateTx(ctx context.Context, tx *gorm.DB, skus []string) ([]models.Product, error) {
+	var products []models.Product
+	err := tx.WithContext(ctx).Unscoped().Table("product").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku IN ?", skus).Order("id").Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) UpsertProductsBySKUTx(ctx context.Context, tx *gorm.DB, products []models.Product) error {
+	err := tx.WithContext(ctx).Table("product").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "sku"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "price", "stock", "category_id"}),
+	}).Create(&products).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductsInBatches(ctx context.Context, batchSize int, fn func(products []models.Product) error) error {
+	var products []models.Product
+	err := r.Database.WithContext(ctx).Table("product").Order("id").FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(products)
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProducts(ctx context.Context, param *models.ProductListParameter) ([]models.Product, int64, error) {
+	filter := r.productListFilter(param, "")
+
+	var total int64
+	if err := r.Database.WithContext(ctx).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []models.Product
+	err := r.Database.WithContext(ctx).Scopes(filter).
+		Order("id DESC").
+		Offset((param.Page - 1) * param.Limit).
+		Limit(param.Limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (r *ProductRepository) FindProductBundleItemsByBundleIDs(ctx context.Context, bundleIDs []int64) ([]models.ProductBundleItem, error) {
+	var productBundleItems []models.ProductBundleItem
+	err := r.Database.WithContext(ctx).Table("product_bundle_item").Where("bundle_id IN ?", bundleIDs).Order("bundle_id, id").Find(&productBundleItems).Error
+	if err != nil {
+		return nil, err
+	}
+	return productBundleItems, nil
+}
+
+// FindBundleIDsByComponentIDs returns the bundles that contain any of productIDs.
+func (r *ProductRepository) FindBundleIDsByComponentIDs(ctx context.Context, productIDs []int64) ([]int64, error) {
+	var bundleIDs []int64
+	err := r.Database.WithContext(ctx).Table("product_bundle_item").Distinct("bundle_id").Where("product_id IN ?", productIDs).Pluck("bundle_id", &bundleIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return bundleIDs, nil
+}
+
+func (r *ProductRepository) ReplaceProductBundleItemsTx(ctx context.Context, tx *gorm.DB, bundleID int64, productBundleItems []models.ProductBundleItem) error {
+	err := tx.WithContext(ctx).Table("product_bundle_item").Where("bundle_id = ?", bundleID).Delete(&models.ProductBundleItem{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(productBundleItems) == 0 {
+		return nil
+	}
+
+	for i := range productBundleItems {
+		productBundleItems[i].ID = 0
+		productBundleItems[i].BundleID = bundleID
+	}
+	return tx.WithContext(ctx).Table("product_bundle_item").Create(&productBundleItems).Error
+}
+
+func (r *ProductRepository) FindProductOptionsByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductOption, error) {
+	var productOptions []models.ProductOption
+	err := r.Database.WithContext(ctx).Table("product_option").Where("product_id IN ?", productIDs).Order("product_id, position").Find(&productOptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return productOptions, nil
+}
+
+func (r *ProductRepository) ReplaceProductOptionsTx(ctx context.Context, tx *gorm.DB, productID int64, productOptions []models.ProductOption) error {
+	err := tx.WithContext(ctx).Table("product_option").Where("product_id = ?", productID).Delete(&models.ProductOption{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(productOptions) == 0 {
+		return nil
+	}
+
+	for i := range productOptions {
+		productOptions[i].ID = 0
+		productOptions[i].ProductID = productID
+		productOptions[i].Position = i
+	}
+
+	err = tx.WithContext(ctx).Table("product_option").Create(&productOptions).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductVariantsByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductVariant, error) {
+	var productVariants []models.ProductVariant
+	err := r.Database.WithContext(ctx).Table("product_variant").Where("product_id IN ?", productIDs).Order("id").Find(&productVariants).Error
+	if err != nil {
+		return nil, err
+	}
+	return productVariants, nil
+}
+
+func (r *ProductRepository) FindProductVariantByID(ctx context.Context, productVariantID int64) (*models.ProductVariant, error) {
+	var productVariant models.ProductVariant
+	err := r.Database.WithContext(ctx).Table("product_variant").Where("id = ?", productVariantID).Last(&productVariant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ProductVariant{}, nil
+		}
+		return nil, err
+	}
+	return &productVariant, nil
+}
+
+func (r *ProductRepository) InsertProductVariant(ctx context.Context, productVariant *models.ProductVariant) (int64, error) {
+	err := r.Database.WithContext(ctx).Table("product_variant").Create(productVariant).Error
+	if err != nil {
+		return 0, err
+	}
+	return productVariant.ID, nil
+}
+
+func (r *ProductRepository) UpdateProductVariantColumnsTx(ctx context.Context, tx *gorm.DB, productVariantID int64, columns map[string]interface{}) error {
+	err := tx.WithContext(ctx).Table("product_variant").Where("id = ?", productVariantID).Updates(columns).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) DeleteProductVariant(ctx context.Context, productVariantID int64) error {
+	err := r.Database.WithContext(ctx).Table("product_variant").Delete(&models.ProductVariant{}, productVariantID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductImagesByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductImage, error) {
+	var productImages []models.ProductImage
+	err := r.Database.WithContext(ctx).Table("product_image").Where("product_id IN ?", productIDs).Order("product_id, position").Find(&productImages).Error
+	if err != nil {
+		return nil, err
+	}
+	return productImages, nil
+}
+
+func (r *ProductRepository) FindProductImageByID(ctx context.Context, productImageID int64) (*models.ProductImage, error) {
+	var productImage models.ProductImage
+	err := r.Database.WithContext(ctx).Table("product_image").Where("id = ?", productImageID).Last(&productImage).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ProductImage{}, nil
+		}
+		return nil, err
+	}
+	return &productImage, nil
+}
+
+// NextProductImagePositionTx is only safe while the product row is locked, see FindProductsForUpdateTx.
+func (r *ProductRepository) NextProductImagePositionTx(ctx context.Context, tx *gorm.DB, productID int64) (int, error) {
+	var position int
+	err := tx.WithContext(ctx).Table("product_image").Where("product_id = ?", productID).Select("COALESCE(MAX(position) + 1, 0)").Scan(&position).Error
+	if err != nil {
+		return 0, err
+	}
+	return position, nil
+}
+
+func (r *ProductRepository) InsertProductImageTx(ctx context.Context, tx *gorm.DB, productImage *models.ProductImage) (int64, error) {
+	err := tx.WithContext(ctx).Table("product_image").Create(productImage).Error
+	if err != nil {
+		return 0, err
+	}
+	return productImage.ID, nil
+}
+
+func (r *ProductRepository) UpdateProductImagePositionTx(ctx context.Context, tx *gorm.DB, productImageID int64, position int) error {
+	err := tx.WithContext(ctx).Table("product_image").Where("id = ?", productImageID).Update("position", position).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) DeleteProductImage(ctx context.Context, productImageID int64) error {
+	err := r.Database.WithContext(ctx).Table("product_image").Delete(&models.ProductImage{}, productImageID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindProductBySlug also finds deleted products, their slug stays taken so it can come back on restore.
+func (r *ProductRepository) FindProductBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	var product models.Product
+	err := r.Database.WithContext(ctx).Unscoped().Table("product").Where("slug = ?", slug).Last(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.Product{}, nil
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *ProductRepository) FindSlugRedirect(ctx context.Context, entityType string, slug string) (*models.SlugRedirect, error) {
+	var slugRedirect models.SlugRedirect
+	err := r.Database.WithContext(ctx).Table("slug_redirect").Where("entity_type = ? AND slug = ?", entityType, slug).Last(&slugRedirect).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.SlugRedirect{}, nil
+		}
+		return nil, err
+	}
+	return &slugRedirect, nil
+}
+
+func (r *ProductRepository) UpsertSlugRedirectTx(ctx context.Context, tx *gorm.DB, slugRedirect *models.SlugRedirect) error {
+	err := tx.WithContext(ctx).Table("slug_redirect").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "entity_type"}, {Name: "slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"entity_id", "create_time"}),
+	}).Create(slugRedirect).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) CountPublishedProductSlugs(ctx context.Context) (int64, error) {
+	var total int64
+	err := r.publishedProductSlugs(ctx).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// FindPublishedProductSlugs only selects id and slug, enough to build product URLs.
+func (r *ProductRepository) FindPublishedProductSlugs(ctx context.Context, offset int, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.publishedProductSlugs(ctx).Select("id", "slug").Order("id").Offset(offset).Limit(limit).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) publishedProductSlugs(ctx context.Context) *gorm.DB {
+	return r.Database.WithContext(ctx).Model(&models.Product{}).Table("product").
+		Where("status = ? AND slug <> ''", models.ProductStatusPublished)
+}
+
+func (r *ProductRepository) FindProductCategoryBySlug(ctx context.Context, slug string) (*models.ProductCategory, error) {
+	var productCategory models.ProductCategory
+	err := r.Database.WithContext(ctx).Table("product_category").Where("slug = ?", slug).Last(&productCategory).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ProductCategory{}, nil
+		}
+		return nil, err
+	}
+	return &productCategory, nil
+}
+
+func (r *ProductRepository) CountProductsByCategoryID(ctx context.Context, productCategoryID int) (int64, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("product").Where("category_id = ?", productCategoryID).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *ProductRepository) CountChildProductCategories(ctx context.Context, productCategoryID int) (int64, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("product_category").Where("parent_id = ?", productCategoryID).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *ProductRepository) FindProductsByCategoryIDForUpdateTx(ctx context.Context, tx *gorm.DB, productCategoryID int) ([]models.Product, error) {
+	var products []models.Product
+	err := tx.WithContext(ctx).Unscoped().Table("product").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("category_id = ?", productCategoryID).Order("id").Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) ReassignProductsCategoryTx(ctx context.Context, tx *gorm.DB, fromCategoryID int, toCategoryID int) error {
+	err := tx.WithContext(ctx).Table("product").Where("category_id = ?", fromCategoryID).Update("category_id", toCategoryID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) ReassignChildProductCategoriesTx(ctx context.Context, tx *gorm.DB, fromCategoryID int, toCategoryID int) error {
+	err := tx.WithContext(ctx).Table("product_category").Where("parent_id = ?", fromCategoryID).Update("parent_id", toCategoryID).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *ProductRepository) DeleteProductCategory(ctx context.Context, productCategoryID int) error {
-	err := r.Database.WithContext(ctx).Table("product_category").Delete(&models.ProductCategory{}, productCategoryID).Error
+func (r *ProductRepository) UpdateProductPriceTx(ctx context.Context, tx *gorm.DB, productID int64, price float64) error {
+	err := tx.WithContext(ctx).Table("product").Where("id = ?", productID).Update("price", price).Error
 	if err != nil {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *ProductRepository) FindProductPricesForUpdateTx(ctx context.Context, tx *gorm.DB, productID int64, kind string) ([]models.ProductPrice, error) {
+	var productPrices []models.ProductPrice
+	err := tx.WithContext(ctx).Table("product_price").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND kind = ?", productID, kind).
+		Order("effective_from").
+		Find(&productPrices).Error
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+func (r *ProductRepository) InsertProductPriceTx(ctx context.Context, tx *gorm.DB, productPrice *models.ProductPrice) (int64, error) {
+	err := tx.WithContext(ctx).Table("product_price").Create(productPrice).Error
+	if err != nil {
+		return 0, err
+	}
+	return productPrice.ID, nil
+}
+
+func (r *ProductRepository) UpdateProductPriceEffectiveToTx(ctx context.Context, tx *gorm.DB, productPriceID int64, effectiveTo time.Time) error {
+	err := tx.WithContext(ctx).Table("product_price").Where("id = ?", productPriceID).Update("effective_to", effectiveTo).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductPrices(ctx context.Context, productID int64) ([]models.ProductPrice, error) {
+	var productPrices []models.ProductPrice
+	err := r.Database.WithContext(ctx).Table("product_price").Where("product_id = ?", productID).Order("effective_from DESC, id DESC").Find(&productPrices).Error
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+func (r *ProductRepository) FindProductPricesAt(ctx context.Context, productID int64, at time.Time) ([]models.ProductPrice, error) {
+	var productPrices []models.ProductPrice
+	err := r.Database.WithContext(ctx).Table("product_price").
+		Where("product_id = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", productID, at, at).
+		Find(&productPrices).Error
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+func (r *ProductRepository) CountProductPricesByKind(ctx context.Context, productID int64, kind string) (int64, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("product_price").Where("product_id = ? AND kind = ?", productID, kind).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *ProductRepository) FindUnfinishedProductSalesByProductIDs(ctx context.Context, productIDs []int64, now time.Time) ([]models.ProductPrice, error) {
+	var productPrices []models.ProductPrice
+	err := r.Database.WithContext(ctx).Table("product_price").
+		Where("product_id IN ? AND kind = ? AND effective_to > ?", productIDs, models.ProductPriceKindSale, now).
+		Order("product_id, effective_from").
+		Find(&productPrices).Error
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+// FindDueListPrices returns the list prices that are in effect at now but not yet copied onto product.price.
+func (r *ProductRepository) FindDueListPrices(ctx context.Context, now time.Time) ([]models.ProductPrice, error) {
+	var productPrices []models.ProductPrice
+	err := r.Database.WithContext(ctx).Table("product_price").
+		Select("product_price.*").
+		Joins("JOIN product ON product.id = product_price.product_id").
+		Where("product_price.kind = ? AND product_price.effective_from <= ?", models.ProductPriceKindList, now).
+		Where("(product_price.effective_to IS NULL OR product_price.effective_to > ?)", now).
+		Where("product.price <> product_price.price").
+		Find(&productPrices).Error
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+func (r *ProductRepository) FindProductReviewByID(ctx context.Context, productReviewID int64) (*models.ProductReview, error) {
+	var productReview models.ProductReview
+	err := r.Database.WithContext(ctx).Table("product_review").Where("id = ?", productReviewID).Last(&productReview).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ProductReview{}, nil
+		}
+		return nil, err
+	}
+	return &productReview, nil
+}
+
+func (r *ProductRepository) FindProductReviewByProductIDAndUserID(ctx context.Context, productID int64, userID int64) (*models.ProductReview, error) {
+	var productReview models.ProductReview
+	err := r.Database.WithContext(ctx).Table("product_review").Where("product_id = ? AND user_id = ?", productID, userID).Last(&productReview).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ProductReview{}, nil
+		}
+		return nil, err
+	}
+	return &productReview, nil
+}
+
+func (r *ProductRepository) InsertProductReview(ctx context.Context, productReview *models.ProductReview) (int64, error) {
+	err := r.Database.WithContext(ctx).Table("product_review").Create(productReview).Error
+	if err != nil {
+		return 0, err
+	}
+	return productReview.ID, nil
+}
+
+func (r *ProductRepository) FindProductReviews(ctx context.Context, param *models.ProductReviewListParameter) ([]models.ProductReview, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Table("product_review")
+		if param.ProductID != 0 {
+			db = db.Where("product_id = ?", param.ProductID)
+		}
+		if param.Status != "" {
+			db = db.Where("status = ?", param.Status)
+		}
+		return db
+	}
+
+	var total int64
+	if err := r.Database.WithContext(ctx).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "id DESC"
+	if param.Sort == models.ReviewSortHelpful {
+		order = "helpful_count DESC, id DESC"
+	}
+
+	var productReviews []models.ProductReview
+	err := r.Database.WithContext(ctx).Scopes(filter).
+		Order(order).
+		Offset((param.Page - 1) * param.Limit).
+		Limit(param.Limit).
+		Find(&productReviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return productReviews, total, nil
+}
+
+func (r *ProductRepository) UpdateProductReviewStatusTx(ctx context.Context, tx *gorm.DB, productReviewID int64, status string) error {
+	err := tx.WithContext(ctx).Table("product_review").Where("id = ?", productReviewID).Updates(map[string]interface{}{
+		"status": status,
+		"update_time": time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RefreshProductRatingTx recomputes the cached rating columns of a product from its approved reviews.
+func (r *ProductRepository) RefreshProductRatingTx(ctx context.Context, tx *gorm.DB, productID int64) error {
+	err := tx.WithContext(ctx).Exec(`UPDATE product SET
+		rating_avg = (SELECT COALESCE(AVG(rating), 0) FROM product_review WHERE product_id = ? AND status = ?),
+		rating_count = (SELECT COUNT(*) FROM product_review WHERE product_id = ? AND status = ?)
+		WHERE id = ?`,
+		productID, models.ReviewStatusApproved, productID, models.ReviewStatusApproved, productID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// InsertProductReviewVoteTx reports false when the user already voted for the review.
+func (r *ProductRepository) InsertProductReviewVoteTx(ctx context.Context, tx *gorm.DB, productReviewVote *models.ProductReviewVote) (bool, error) {
+	result := tx.WithContext(ctx).Table("product_review_vote").Clauses(clause.OnConflict{DoNothing: true}).Create(productReviewVote)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
+// DeleteProductReviewVoteTx reports false when there was no vote to remove.
+func (r *ProductRepository) DeleteProductReviewVoteTx(ctx context.Context, tx *gorm.DB, productReviewID int64, userID int64) (bool, error) {
+	result := tx.WithContext(ctx).Table("product_review_vote").Where("review_id = ? AND user_id = ?", productReviewID, userID).Delete(&models.ProductReviewVote{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
+func (r *ProductRepository) UpdateProductReviewHelpfulCountTx(ctx context.Context, tx *gorm.DB, productReviewID int64, delta int) error {
+	err := tx.WithContext(ctx).Table("product_review").Where("id = ?", productReviewID).Update("helpful_count", gorm.Expr("helpful_count + ?", delta)).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// InsertProductStockEventTx reports false when the order event was already applied.
+func (r *ProductRepository) InsertProductStockEventTx(ctx context.Context, tx *gorm.DB, productStockEvent *models.ProductStockEvent) (bool, error) {
+	result := tx.WithContext(ctx).Table("product_stock_event").Clauses(clause.OnConflict{DoNothing: true}).Create(productStockEvent)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
+// LockProductStockOrderTx takes a lock on orderID held until tx ends, so the stock events of one order are
+// applied one after the other. Two orders whose IDs share the lower 32 bits only wait on each other.
+func (r *ProductRepository) LockProductStockOrderTx(ctx context.Context, tx *gorm.DB, orderID int64) error {
+	err := tx.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(?, ?)", productStockOrderLockClass, int32(orderID)).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) HasProductStockEventTx(ctx context.Context, tx *gorm.DB, orderID int64, topic string) (bool, error) {
+	var total int64
+	err := tx.WithContext(ctx).Table("product_stock_event").Where("order_id = ? AND topic = ?", orderID, topic).Count(&total).Error
+	if err != nil {
+		return false, err
+	}
+	return total != 0, nil
+}
+
+// FindProductsForUpdateTx locks in id order so two orders touching the same products cannot deadlock.
+func (r *ProductRepository) FindProductsForUpdateTx(ctx context.Context, tx *gorm.DB, productIDs []int64) ([]models.Product, error) {
+	var products []models.Product
+	err := tx.WithContext(ctx).Unscoped().Table("product").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", productIDs).Order("id").Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) FindProductVariantsForUpdateTx(ctx context.Context, tx *gorm.DB, productVariantIDs []int64) ([]models.ProductVariant, error) {
+	var productVariants []models.ProductVariant
+	err := tx.WithContext(ctx).Table("product_variant").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", productVariantIDs).Order("id").Find(&productVariants).Error
+	if err != nil {
+		return nil, err
+	}
+	return productVariants, nil
+}
+
+func (r *ProductRepository) FindProductVariantsByProductIDTx(ctx context.Context, tx *gorm.DB, productID int64) ([]models.ProductVariant, error) {
+	var productVariants []models.ProductVariant
+	err := tx.WithContext(ctx).Table("product_variant").Where("product_id = ?", productID).Order("id").Find(&productVariants).Error
+	if err != nil {
+		return nil, err
+	}
+	return productVariants, nil
+}
+
+func (r *ProductRepository) UpdateProductStockTx(ctx context.Context, tx *gorm.DB, productID int64, stock int) error {
+	err := tx.WithContext(ctx).Unscoped().Table("product").Where("id = ?", productID).Update("stock", stock).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) UpdateProductVariantStockTx(ctx context.Context, tx *gorm.DB, productVariantID int64, stock int) error {
+	err := tx.WithContext(ctx).Table("product_variant").Where("id = ?", productVariantID).Update("stock", stock).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpsertProductStockSubscription makes an already notified subscription pending again.
+func (r *ProductRepository) UpsertProductStockSubscription(ctx context.Context, productStockSubscription *models.ProductStockSubscription) error {
+	err := r.Database.WithContext(ctx).Table("product_stock_subscription").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"notify_time": nil}),
+	}).Create(productStockSubscription).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProductStockSubscription reports false when the user was not subscribed.
+func (r *ProductRepository) DeleteProductStockSubscription(ctx context.Context, productID int64, userID int64) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("product_stock_subscription").Where("product_id = ? AND user_id = ?", productID, userID).Delete(&models.ProductStockSubscription{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
+func (r *ProductRepository) FindPendingProductStockSubscriptions(ctx context.Context, productID int64) ([]models.ProductStockSubscription, error) {
+	var productStockSubscriptions []models.ProductStockSubscription
+	err := r.Database.WithContext(ctx).Table("product_stock_subscription").Where("product_id = ? AND notify_time IS NULL", productID).
+		Order("create_time").Find(&productStockSubscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return productStockSubscriptions, nil
+}
+
+func (r *ProductRepository) MarkProductStockSubscriptionsNotified(ctx context.Context, productStockSubscriptionIDs []int64, notifyTime time.Time) error {
+	err := r.Database.WithContext(ctx).Table("product_stock_subscription").Where("id IN ?", productStockSubscriptionIDs).Update("notify_time", notifyTime).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// numericAttributeValue is the value of an attribute as a number, NULL when the text is not one.
+const numericAttributeValue = `CASE WHEN v.value ~ '^-?[0-9]+(\.[0-9]+)?$' THEN v.value::numeric END`
+
+// productListFilter scopes the public listing. The filter on skipCode is left out, which is how the facet
+// counts of a filtered attribute still show the values a customer could switch to.
+func (r *ProductRepository) productListFilter(param *models.ProductListParameter, skipCode string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Model(&models.Product{}).Table("product").Where("product.status = ?", models.ProductStatusPublished)
+		if len(param.CategoryIDs) != 0 {
+			db = db.Where("product.category_id IN ?", param.CategoryIDs)
+		}
+
+		if param.Q != "" {
+			pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(param.Q) + "%"
+			if param.Locale == "" || param.Locale == models.DefaultLocale {
+				db = db.Where("(product.name ILIKE ? OR product.description ILIKE ?)", pattern, pattern)
+			} else {
+				translated := db.Session(&gorm.Session{NewDB: true}).Table("product_translation t").Select("t.product_id").
+					Where("t.locale = ? AND (t.name ILIKE ? OR t.description ILIKE ?)", param.Locale, pattern, pattern)
+				db = db.Where("(product.name ILIKE ? OR product.description ILIKE ? OR product.id IN (?))", pattern, pattern, translated)
+			}
+		}
+
+		for _, attributeFilter := range param.Filters {
+			if attributeFilter.Code == skipCode {
+				continue
+			}
+
+			matching := db.Session(&gorm.Session{NewDB: true}).Table("product_attribute_value v").Select("v.product_id").
+				Joins("JOIN product_attribute a ON a.id = v.attribute_id").
+				Where("a.code = ?", attributeFilter.Code)
+			if len(attributeFilter.Values) != 0 {
+				matching = matching.Where("v.value IN ?", attributeFilter.Values)
+			}
+			// the CASE keeps the cast away from values that are not numbers, postgres may evaluate the
+			// conditions in any order so the type check alone does not protect it
+			if attributeFilter.Min != nil {
+				matching = matching.Where("a.type = ? AND "+numericAttributeValue+" >= ?", models.AttributeTypeNumber, *attributeFilter.Min)
+			}
+			if attributeFilter.Max != nil {
+				matching = matching.Where("a.type = ? AND "+numericAttributeValue+" <= ?", models.AttributeTypeNumber, *attributeFilter.Max)
+			}
+			db = db.Where("product.id IN (?)", matching)
+		}
+		return db
+	}
+}
+
+// CountProductFacets counts the listed products per value of every code, with the filter on skipCode left out.
+func (r *ProductRepository) CountProductFacets(ctx context.Context, param *models.ProductListParameter, codes []string, skipCode string) ([]models.ProductFacetCount, error) {
+	db := r.Database.WithContext(ctx)
+	listed := db.Scopes(r.productListFilter(param, skipCode)).Select("product.id")
+
+	var productFacetCounts []models.ProductFacetCount
+	err := db.Table("product_attribute_value v").
+		Select("a.code, v.value, COUNT(DISTINCT v.product_id) AS count").
+		Joins("JOIN product_attribute a ON a.id = v.attribute_id").
+		Where("a.code IN ? AND v.product_id IN (?)", codes, listed).
+		Group("a.code, v.value").
+		Find(&productFacetCounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return productFacetCounts, nil
+}
+
+func (r *ProductRepository) FindProductAttributes(ctx context.Context, productCategoryIDs []int64) ([]models.ProductAttribute, error) {
+	var productAttributes []models.ProductAttribute
+	db := r.Database.WithContext(ctx).Table("product_attribute")
+	if productCategoryIDs != nil {
+		db = db.Where("category_id IN ?", productCategoryIDs)
+	}
+
+	err := db.Order("position, id").Find(&productAttributes).Error
+	if err != nil {
+		return nil, err
+	}
+	return productAttributes, nil
+}
+
+func (r *ProductRepository) FindProductAttributeByID(ctx context.Context, productAttributeID int64) (*models.ProductAttribute, error) {
+	var productAttribute models.ProductAttribute
+	err := r.Database.WithContext(ctx).Table("product_attribute").Where("id = ?", productAttributeID).Last(&productAttribute).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ProductAttribute{}, nil
+		}
+		return nil, err
+	}
+	return &productAttribute, nil
+}
+
+func (r *ProductRepository) InsertProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) (int64, error) {
+	err := r.Database.WithContext(ctx).Table("product_attribute").Create(productAttribute).Error
+	if err != nil {
+		return 0, err
+	}
+	return productAttribute.ID, nil
+}
+
+func (r *ProductRepository) UpdateProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) (*models.ProductAttribute, error) {
+	err := r.Database.WithContext(ctx).Table("product_attribute").Save(productAttribute).Error
+	if err != nil {
+		return nil, err
+	}
+	return productAttribute, nil
+}
+
+func (r *ProductRepository) DeleteProductAttributeTx(ctx context.Context, tx *gorm.DB, productAttributeID int64) error {
+	err := tx.WithContext(ctx).Table("product_attribute_value").Where("attribute_id = ?", productAttributeID).Delete(&models.ProductAttributeValue{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.WithContext(ctx).Table("product_attribute").Delete(&models.ProductAttribute{}, productAttributeID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) CountProductAttributeValues(ctx context.Context, productAttributeID int64) (int64, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("product_attribute_value").Where("attribute_id = ?", productAttributeID).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *ProductRepository) FindProductAttributeValuesByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductAttributeValue, error) {
+	var productAttributeValues []models.ProductAttributeValue
+	err := r.Database.WithContext(ctx).Table("product_attribute_value").Where("product_id IN ?", productIDs).Find(&productAttributeValues).Error
+	if err != nil {
+		return nil, err
+	}
+	return productAttributeValues, nil
+}
+
+func (r *ProductRepository) ReplaceProductAttributeValuesTx(ctx context.Context, tx *gorm.DB, productID int64, productAttributeValues []models.ProductAttributeValue) error {
+	err := tx.WithContext(ctx).Table("product_attribute_value").Where("product_id = ?", productID).Delete(&models.ProductAttributeValue{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(productAttributeValues) == 0 {
+		return nil
+	}
+
+	for i := range productAttributeValues {
+		productAttributeValues[i].ProductID = productID
+	}
+	return tx.WithContext(ctx).Table("product_attribute_value").Create(&productAttributeValues).Error
+}
+
+func (r *ProductRepository) FindProductAttributesByIDs(ctx context.Context, productAttributeIDs []int64) ([]models.ProductAttribute, error) {
+	var productAttributes []models.ProductAttribute
+	err := r.Database.WithContext(ctx).Table("product_attribute").Where("id IN ?", productAttributeIDs).Find(&productAttributes).Error
+	if err != nil {
+		return nil, err
+	}
+	return productAttributes, nil
+}
+
+func (r *ProductRepository) FindProductIDsByAttributeID(ctx context.Context, productAttributeID int64) ([]int64, error) {
+	var productIDs []int64
+	err := r.Database.WithContext(ctx).Table("product_attribute_value").Where("attribute_id = ?", productAttributeID).Pluck("product_id", &productIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return productIDs, nil
+}
+
+func (r *ProductRepository) DeleteProductAttributesByCategoryIDTx(ctx context.Context, tx *gorm.DB, productCategoryID int) error {
+	productAttributeIDs := tx.Session(&gorm.Session{NewDB: true}).Table("product_attribute").Select("id").Where("category_id = ?", productCategoryID)
+	err := tx.WithContext(ctx).Table("product_attribute_value").Where("attribute_id IN (?)", productAttributeIDs).Delete(&models.ProductAttributeValue{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.WithContext(ctx).Table("product_attribute").Where("category_id = ?", productCategoryID).Delete(&models.ProductAttribute{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) InsertFlashSale(ctx context.Context, flashSale *models.FlashSale) (int64, error) {
+	err := r.Database.WithContext(ctx).Table("flash_sale").Create(flashSale).Error
+	if err != nil {
+		return 0, err
+	}
+	return flashSale.ID, nil
+}
+
+func (r *ProductRepository) FindFlashSaleByID(ctx context.Context, flashSaleID int64) (*models.FlashSale, error) {
+	var flashSale models.FlashSale
+	err := r.Database.WithContext(ctx).Table("flash_sale").Where("id = ?", flashSaleID).Last(&flashSale).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.FlashSale{}, nil
+		}
+		return nil, err
+	}
+	return &flashSale, nil
+}
+
+func (r *ProductRepository) FindFlashSaleForUpdateTx(ctx context.Context, tx *gorm.DB, flashSaleID int64) (*models.FlashSale, error) {
+	var flashSale models.FlashSale
+	err := tx.WithContext(ctx).Table("flash_sale").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", flashSaleID).Last(&flashSale).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.FlashSale{}, nil
+		}
+		return nil, err
+	}
+	return &flashSale, nil
+}
+
+func (r *ProductRepository) FindFlashSalesByStatuses(ctx context.Context, statuses []string) ([]models.FlashSale, error) {
+	var flashSales []models.FlashSale
+	err := r.Database.WithContext(ctx).Table("flash_sale").Where("status IN ?", statuses).Order("start_time, id").Find(&flashSales).Error
+	if err != nil {
+		return nil, err
+	}
+	return flashSales, nil
+}
+
+// CountOverlappingFlashSales counts the scheduled or running sales of the same item whose window overlaps.
+func (r *ProductRepository) CountOverlappingFlashSales(ctx context.Context, flashSale *models.FlashSale) (int64, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("flash_sale").
+		Where("product_id = ? AND variant_id = ? AND status IN ?", flashSale.ProductID, flashSale.VariantID,
+			[]string{models.FlashSaleStatusScheduled, models.FlashSaleStatusActive}).
+		Where("start_time < ? AND end_time > ?", flashSale.EndTime, flashSale.StartTime).
+		Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *ProductRepository) FindDueFlashSales(ctx context.Context, now time.Time) ([]models.FlashSale, error) {
+	var flashSales []models.FlashSale
+	err := r.Database.WithContext(ctx).Table("flash_sale").
+		Where("status = ? AND start_time <= ?", models.FlashSaleStatusScheduled, now).
+		Order("start_time, id").Find(&flashSales).Error
+	if err != nil {
+		return nil, err
+	}
+	return flashSales, nil
+}
+
+func (r *ProductRepository) UpdateFlashSale(ctx context.Context, flashSaleID int64, updates map[string]interface{}) error {
+	err := r.Database.WithContext(ctx).Table("flash_sale").Where("id = ?", flashSaleID).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) UpdateFlashSaleTx(ctx context.Context, tx *gorm.DB, flashSaleID int64, updates map[string]interface{}) error {
+	err := tx.WithContext(ctx).Table("flash_sale").Where("id = ?", flashSaleID).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddStockTx changes the stock of a variant, or of the product when productVariantID is 0.
+func (r *ProductRepository) AddStockTx(ctx context.Context, tx *gorm.DB, productID int64, productVariantID int64, delta int) error {
+	db := tx.WithContext(ctx).Unscoped().Table("product").Where("id = ?", productID)
+	if productVariantID != 0 {
+		db = tx.WithContext(ctx).Table("product_variant").Where("id = ?", productVariantID)
+	}
+
+	err := db.Update("stock", gorm.Expr("stock + ?", delta)).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) InsertProductOutboxTx(ctx context.Context, tx *gorm.DB, productOutbox *models.ProductOutbox) error {
+	err := tx.WithContext(ctx).Table("product_outbox").Create(productOutbox).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// TryLockProductOutboxTx takes a lock held until tx ends, so only one instance relays at a time and
+// events of the same product leave in the order they were written.
+func (r *ProductRepository) TryLockProductOutboxTx(ctx context.Context, tx *gorm.DB) (bool, error) {
+	var locked bool
+	err := tx.WithContext(ctx).Raw("SELECT pg_try_advisory_xact_lock(?)", productOutboxLockKey).Scan(&locked).Error
+	if err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+func (r *ProductRepository) FindPendingProductOutboxTx(ctx context.Context, tx *gorm.DB, limit int) ([]models.ProductOutbox, error) {
+	var productOutboxes []models.ProductOutbox
+	err := tx.WithContext(ctx).Table("product_outbox").Where("publish_time IS NULL").Order("id").Limit(limit).Find(&productOutboxes).Error
+	if err != nil {
+		return nil, err
+	}
+	return productOutboxes, nil
+}
+
+func (r *ProductRepository) MarkProductOutboxPublishedTx(ctx context.Context, tx *gorm.DB, productOutboxIDs []int64, publishTime time.Time) error {
+	err := tx.WithContext(ctx).Table("product_outbox").Where("id IN ?", productOutboxIDs).Update("publish_time", publishTime).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) MarkProductOutboxFailedTx(ctx context.Context, tx *gorm.DB, productOutboxIDs []int64, lastError string) error {
+	err := tx.WithContext(ctx).Table("product_outbox").Where("id IN ?", productOutboxIDs).Updates(map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"),
+		"last_error": lastError,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) DeletePublishedProductOutbox(ctx context.Context, before time.Time) error {
+	err := r.Database.WithContext(ctx).Table("product_outbox").Where("publish_time < ?", before).Delete(&models.ProductOutbox{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductTranslations(ctx context.Context, productIDs []int64, locale string) ([]models.ProductTranslation, error) {
+	var productTranslations []models.ProductTranslation
+	err := r.Database.WithContext(ctx).Table("product_translation").Where("product_id IN ? AND locale = ?", productIDs, locale).Find(&productTranslations).Error
+	if err != nil {
+		return nil, err
+	}
+	return productTranslations, nil
+}
+
+func (r *ProductRepository) UpsertProductTranslation(ctx context.Context, productTranslation *models.ProductTranslation) error {
+	err := r.Database.WithContext(ctx).Table("product_translation").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description"}),
+	}).Create(productTranslation).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProductTranslation reports false when the product had no translation in locale.
+func (r *ProductRepository) DeleteProductTranslation(ctx context.Context, productID int64, locale string) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("product_translation").Where("product_id = ? AND locale = ?", productID, locale).Delete(&models.ProductTranslation{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
+func (r *ProductRepository) FindProductCategoryTranslations(ctx context.Context, locale string) ([]models.ProductCategoryTranslation, error) {
+	var productCategoryTranslations []models.ProductCategoryTranslation
+	err := r.Database.WithContext(ctx).Table("product_category_translation").Where("locale = ?", locale).Find(&productCategoryTranslations).Error
+	if err != nil {
+		return nil, err
+	}
+	return productCategoryTranslations, nil
+}
+
+func (r *ProductRepository) UpsertProductCategoryTranslation(ctx context.Context, productCategoryTranslation *models.ProductCategoryTranslation) error {
+	err := r.Database.WithContext(ctx).Table("product_category_translation").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(productCategoryTranslation).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProductCategoryTranslation reports false when the category had no translation in locale.
+func (r *ProductRepository) DeleteProductCategoryTranslation(ctx context.Context, productCategoryID int, locale string) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("product_category_translation").Where("category_id = ? AND locale = ?", productCategoryID, locale).Delete(&models.ProductCategoryTranslation{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
+func (r *ProductRepository) FindWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	err := r.Database.WithContext(ctx).Table("warehouse").Order("priority, id").Find(&warehouses).Error
+	if err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (r *ProductRepository) FindWarehouseByID(ctx context.Context, warehouseID int64) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.Database.WithContext(ctx).Table("warehouse").Where("id = ?", warehouseID).Last(&warehouse).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.Warehouse{}, nil
+		}
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *ProductRepository) FindWarehouseByCode(ctx context.Context, code string) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.Database.WithContext(ctx).Table("warehouse").Where("code = ?", code).Last(&warehouse).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.Warehouse{}, nil
+		}
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *ProductRepository) InsertWarehouse(ctx context.Context, warehouse *models.Warehouse) (int64, error) {
+	err := r.Database.WithContext(ctx).Table("warehouse").Create(warehouse).Error
+	if err != nil {
+		return 0, err
+	}
+	return warehouse.ID, nil
+}
+
+func (r *ProductRepository) UpdateWarehouse(ctx context.Context, warehouse *models.Warehouse) (*models.Warehouse, error) {
+	err := r.Database.WithContext(ctx).Table("warehouse").Omit("create_time").Save(warehouse).Error
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// FindWarehouseStocksForUpdateTx locks in warehouse order so two orders sourcing the same item cannot deadlock.
+func (r *ProductRepository) FindWarehouseStocksForUpdateTx(ctx context.Context, tx *gorm.DB, productID int64, productVariantID int64) ([]models.WarehouseStock, error) {
+	var warehouseStocks []models.WarehouseStock
+	err := tx.WithContext(ctx).Table("warehouse_stock").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND variant_id = ?", productID, productVariantID).Order("warehouse_id").Find(&warehouseStocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return warehouseStocks, nil
+}
+
+func (r *ProductRepository) UpsertWarehouseStockTx(ctx context.Context, tx *gorm.DB, warehouseStock *models.WarehouseStock) error {
+	err := tx.WithContext(ctx).Table("warehouse_stock").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "warehouse_id"}, {Name: "product_id"}, {Name: "variant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stock", "update_time"}),
+	}).Create(warehouseStock).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) AddWarehouseStockTx(ctx context.Context, tx *gorm.DB, warehouseID int64, productID int64, productVariantID int64, delta int) error {
+	err := tx.WithContext(ctx).Table("warehouse_stock").
+		Where("warehouse_id = ? AND product_id = ? AND variant_id = ?", warehouseID, productID, productVariantID).
+		Updates(map[string]interface{}{
+			"stock": gorm.Expr("stock + ?", delta),
+			"update_time": time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// HasWarehouseStock reports whether the stock of the product or variant is kept per warehouse.
+func (r *ProductRepository) HasWarehouseStock(ctx context.Context, productID int64, productVariantID int64) (bool, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("warehouse_stock").Where("product_id = ? AND variant_id = ?", productID, productVariantID).Count(&total).Error
+	if err != nil {
+		return false, err
+	}
+	return total != 0, nil
+}
+
+// FindWarehouseStockProductIDs reports which of productIDs keep their own stock per warehouse.
+func (r *ProductRepository) FindWarehouseStockProductIDs(ctx context.Context, productIDs []int64) (map[int64]bool, error) {
+	var managedProductIDs []int64
+	err := r.Database.WithContext(ctx).Table("warehouse_stock").Distinct("product_id").
+		Where("product_id IN ? AND variant_id = 0", productIDs).Pluck("product_id", &managedProductIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[int64]bool, len(managedProductIDs))
+	for _, productID := range managedProductIDs {
+		managed[productID] = true
+	}
+	return managed, nil
+}
+
+func (r *ProductRepository) FindWarehouseAvailabilities(ctx context.Context, productIDs []int64) (map[int64][]models.WarehouseAvailability, error) {
+	var rows []struct {
+		ProductID int64
+		models.WarehouseAvailability
+	}
+	err := r.Database.WithContext(ctx).Table("warehouse_stock s").
+		Select("s.product_id, s.warehouse_id, w.code, w.name, s.variant_id, s.stock").
+		Joins("JOIN warehouse w ON w.id = s.warehouse_id").
+		Where("s.product_id IN ?", productIDs).
+		Order("s.product_id, w.priority, w.id, s.variant_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	warehouseAvailabilities := map[int64][]models.WarehouseAvailability{}
+	for _, row := range rows {
+		warehouseAvailabilities[row.ProductID] = append(warehouseAvailabilities[row.ProductID], row.WarehouseAvailability)
+	}
+	return warehouseAvailabilities, nil
+}
+
+func (r *ProductRepository) InsertProductStockAllocationsTx(ctx context.Context, tx *gorm.DB, productStockAllocations []models.ProductStockAllocation) error {
+	if len(productStockAllocations) == 0 {
+		return nil
+	}
+
+	err := tx.WithContext(ctx).Table("product_stock_allocation").Create(&productStockAllocations).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductStockAllocationsTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.ProductStockAllocation, error) {
+	var productStockAllocations []models.ProductStockAllocation
+	err := tx.WithContext(ctx).Table("product_stock_allocation").Where("order_id = ?", orderID).Order("id").Find(&productStockAllocations).Error
+	if err != nil {
+		return nil, err
+	}
+	return productStockAllocations, nil
+}
+
+func (r *ProductRepository) InsertProductStockShortfallsTx(ctx context.Context, tx *gorm.DB, productStockShortfalls []models.ProductStockShortfall) error {
+	if len(productStockShortfalls) == 0 {
+		return nil
+	}
+
+	err := tx.WithContext(ctx).Table("product_stock_shortfall").Create(&productStockShortfalls).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) FindProductStockShortfallsTx(ctx context.Context, tx *gorm.DB, orderID int64) ([]models.ProductStockShortfall, error) {
+	var productStockShortfalls []models.ProductStockShortfall
+	err := tx.WithContext(ctx).Table("product_stock_shortfall").Where("order_id = ?", orderID).Order("id").Find(&productStockShortfalls).Error
+	if err != nil {
+		return nil, err
+	}
+	return productStockShortfalls, nil
+}
+
+// ReplaceRelatedProductsTx swaps the whole co-occurrence table for a fresh computation.
+func (r *ProductRepository) ReplaceRelatedProductsTx(ctx context.Context, tx *gorm.DB, relatedProducts []models.RelatedProduct) error {
+	err := tx.WithContext(ctx).Table("related_product").Where("1 = 1").Delete(&models.RelatedProduct{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(relatedProducts) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Table("related_product").CreateInBatches(&relatedProducts, 1000).Error
+}
+
+func (r *ProductRepository) ReplaceProductSalesTx(ctx context.Context, tx *gorm.DB, productSales []models.ProductSales) error {
+	err := tx.WithContext(ctx).Table("product_sales").Where("1 = 1").Delete(&models.ProductSales{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(productSales) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Table("product_sales").CreateInBatches(&productSales, 1000).Error
+}
+
+func (r *ProductRepository) FindRelatedProductIDs(ctx context.Context, productID int64, limit int) ([]int64, error) {
+	var relatedProductIDs []int64
+	err := r.Database.WithContext(ctx).Table("related_product").Where("product_id = ?", productID).
+		Order("score DESC, related_product_id").Limit(limit).Pluck("related_product_id", &relatedProductIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return relatedProductIDs, nil
+}
+
+// FindCategoryBestsellerIDs ranks the published products of a category by the completed orders holding them.
+func (r *ProductRepository) FindCategoryBestsellerIDs(ctx context.Context, categoryID int64, excludedProductIDs []int64, limit int) ([]int64, error) {
+	query := r.Database.WithContext(ctx).Table("product_sales AS s").
+		Joins("JOIN product p ON p.id = s.product_id").
+		Where("p.category_id = ? AND p.status = ? AND p.deleted_at IS NULL", categoryID, models.ProductStatusPublished)
+	if len(excludedProductIDs) != 0 {
+		query = query.Where("s.product_id NOT IN ?", excludedProductIDs)
+	}
+
+	var productIDs []int64
+	err := query.Order("s.order_count DESC, s.product_id").Limit(limit).Pluck("s.product_id", &productIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return productIDs, nil
+}
+
+func (r *ProductRepository) FindWishlistItems(ctx context.Context, userID int64) ([]models.WishlistItem, error) {
+	var wishlistItems []models.WishlistItem
+	err := r.Database.WithContext(ctx).Table("product_wishlist").Where("user_id = ?", userID).Order("create_time DESC, id DESC").Find(&wishlistItems).Error
+	if err != nil {
+		return nil, err
+	}
+	return wishlistItems, nil
+}
+
+func (r *ProductRepository) CountWishlistItems(ctx context.Context, userID int64) (int64, error) {
+	var total int64
+	err := r.Database.WithContext(ctx).Table("product_wishlist").Where("user_id = ?", userID).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *ProductRepository) FindWishlistItemByID(ctx context.Context, wishlistItemID int64, userID int64) (*models.WishlistItem, error) {
+	var wishlistItem models.WishlistItem
+	err := r.Database.WithContext(ctx).Table("product_wishlist").Where("id = ? AND user_id = ?", wishlistItemID, userID).Last(&wishlistItem).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.WishlistItem{}, nil
+		}
+		return nil, err
+	}
+	return &wishlistItem, nil
+}
+
+// InsertWishlistItem leaves an item the user already saved as it is and returns its ID.
+func (r *ProductRepository) InsertWishlistItem(ctx context.Context, wishlistItem *models.WishlistItem) (int64, error) {
+	err := r.Database.WithContext(ctx).Table("product_wishlist").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}, {Name: "variant_id"}},
+		DoNothing: true,
+	}).Create(wishlistItem).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if wishlistItem.ID == 0 {
+		err = r.Database.WithContext(ctx).Table("product_wishlist").
+			Where("user_id = ? AND product_id = ? AND variant_id = ?", wishlistItem.UserID, wishlistItem.ProductID, wishlistItem.VariantID).
+			Last(wishlistItem).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	return wishlistItem.ID, nil
+}
+
+// DeleteWishlistItem reports false when the user has no such item.
+func (r *ProductRepository) DeleteWishlistItem(ctx context.Context, wishlistItemID int64, userID int64) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("product_wishlist").Where("id = ? AND user_id = ?", wishlistItemID, userID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// PublishProductStockAlert keys the message by product so consumers see the alerts of one product in order.
+func (r *ProductRepository) PublishProductStockAlert(ctx context.Context, topic string, event *models.ProductStockAlertEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return r.Publisher.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key: []byte(fmt.Sprintf("product-%d", event.ProductID)),
+		Value: value,
+	})
+}
+
+// PublishProductOutbox writes the events in one call. Messages with the same key land on the same partition
+// in the order given, so events of one product keep their order.
+func (r *ProductRepository) PublishProductOutbox(ctx context.Context, productOutboxes []models.ProductOutbox) error {
+	messages := make([]kafka.Message, len(productOutboxes))
+	for i, productOutbox := range productOutboxes {
+		messages[i] = kafka.Message{
+			Topic: productOutbox.Topic,
+			Key: []byte(productOutbox.MessageKey),
+			Value: []byte(productOutbox.Payload),
+		}
+	}
+	return r.Publisher.WriteMessages(ctx, messages...)
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// StockNotifier tells a subscriber that a product they waited for can be bought again.
+type StockNotifier interface {
+	NotifyBackInStock(ctx context.Context, subscription *models.ProductStockSubscription, product *models.Product) error
+}
+
+func NewStockNotifier(cfg config.NotifierConfig) (StockNotifier, error) {
+	switch cfg.Driver {
+	case "log":
+		return &logNotifier{}, nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook notifier needs a webhook_url")
+		}
+		return &webhookNotifier{
+			url: cfg.WebhookURL,
+			client: &http.Client{Timeout: 5 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier driver %q", cfg.Driver)
+	}
+}
+
+// logNotifier only writes the notification to the log, for local setups without a delivery channel.
+type logNotifier struct{}
+
+func (n *logNotifier) NotifyBackInStock(ctx context.Context, subscription *models.ProductStockSubscription, product *models.Product) error {
+	log.Logger.WithFields(logrus.Fields{
+		"userID": subscription.UserID,
+		"productID": product.ID,
+	}).Infof("📦 %s is back in stock", product.Name)
+	return nil
+}
+
+// webhookNotifier posts the notification to a delivery service that knows how to reach the user.
+type webhookNotifier struct {
+	url string
+	client *http.Client
+}
+
+func (n *webhookNotifier) NotifyBackInStock(ctx context.Context, subscription *models.ProductStockSubscription, product *models.Product) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type": "product.back_in_stock",
+		"user_id": subscription.UserID,
+		"product_id": product.ID,
+		"sku": product.SKU,
+		"name": product.Name,
+		"stock": product.Stock,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook notifier got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (r *ProductRepository) NotifyBackInStock(ctx context.Context, subscription *models.ProductStockSubscription, product *models.Product) error {
+	return r.Notifier.NotifyBackInStock(ctx, subscription, product)
+}
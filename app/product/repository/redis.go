@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/PorcoGalliard/eCommerce-Microservice/models"
@@ -11,12 +13,87 @@ import (
 )
 
 var (
-	cacheKeyProductInfo = "product:%d"
-	cacheKeyProductCategoryInfo = "product_category:%d"
+	// the product and category caches hold one entry per locale, the last part of the key
+	cacheKeyProductInfo = "product:%d:%s"
+	cacheKeyProductCategoryInfo = "product_category:%d:%s"
+	cacheKeyProductCategoryAll = "product_category:all:%s"
+
+	// the running flash sales by "productID:variantID", each value is "flashSaleID:price:perUserLimit"
+	keyFlashSaleActive = "flash_sale:active"
+	keyFlashSaleStock = "flash_sale:%d:stock"
+	keyFlashSaleBuyers = "flash_sale:%d:buyers"
+	// keyFlashSaleFinal keeps the remaining stock of an ended sale until it is written back to Postgres
+	keyFlashSaleFinal = "flash_sale:%d:final"
+
+	// the products a user opened, scored by the time of the last view
+	keyRecentlyViewed = "recently_viewed:%d"
+)
+
+const (
+	// RecentlyViewedLimit is how many products a user's recently viewed list keeps
+	RecentlyViewedLimit = 20
+	recentlyViewedTTL = 30 * 24 * time.Hour
+)
+
+// reserveFlashSaleScript checks every item first and only then takes the stock, so a checkout either gets
+// all of its flash sale items or none. KEYS are stock and buyers key pairs, ARGV is the user followed by
+// quantity and per user limit pairs. It returns {0, 0} or {code, item index}.
+var reserveFlashSaleScript = redis.NewScript(`
+local user = ARGV[1]
+local n = #KEYS / 2
+for i = 1, n do
+	local stock = tonumber(redis.call('GET', KEYS[i * 2 - 1]))
+	if not stock then
+		return {-1, i}
+	end
+	local qty = tonumber(ARGV[i * 2])
+	local limit = tonumber(ARGV[i * 2 + 1])
+	local bought = tonumber(redis.call('HGET', KEYS[i * 2], user) or '0')
+	if limit > 0 and bought + qty > limit then
+		return {-2, i}
+	end
+	if stock < qty then
+		return {-3, i}
+	end
+end
+for i = 1, n do
+	redis.call('DECRBY', KEYS[i * 2 - 1], ARGV[i * 2])
+	redis.call('HINCRBY', KEYS[i * 2], user, ARGV[i * 2])
+end
+return {0, 0}
+`)
+
+// releaseFlashSaleScript returns 0 when the sale already ended, the caller puts the units back into Postgres then.
+var releaseFlashSaleScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('INCRBY', KEYS[1], ARGV[2])
+local left = redis.call('HINCRBY', KEYS[2], ARGV[1], -tonumber(ARGV[2]))
+if left <= 0 then
+	redis.call('HDEL', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// endFlashSaleScript freezes the counter under the final key and returns what is left, nil when the
+// counter was lost. Running it again returns the same value.
+var endFlashSaleScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('RENAME', KEYS[1], KEYS[2])
+end
+return redis.call('GET', KEYS[2])
+`)
+
+const (
+	FlashSaleReserved = 0
+	FlashSaleNotRunning = -1
+	FlashSaleLimitExceeded = -2
+	FlashSaleSoldOut = -3
 )
 
-func (r *ProductRepository) GetProductByIDFromRedis(ctx context.Context, productID int64) (*models.Product, error) {
-	cacheKey := fmt.Sprintf(cacheKeyProductInfo, productID)
+func (r *ProductRepository) GetProductByIDFromRedis(ctx context.Context, productID int64, locale string) (*models.Product, error) {
+	cacheKey := fmt.Sprintf(cacheKeyProductInfo, productID, locale)
 
 	productStr, err := r.Redis.Get(ctx, cacheKey).Result()
 	if err != nil {
@@ -35,8 +112,52 @@ func (r *ProductRepository) GetProductByIDFromRedis(ctx context.Context, product
 	return product, nil
 }
 
-func (r *ProductRepository) GetProductCategoryByIDFromRedis(ctx context.Context, productCategoryID int) (*models.ProductCategory, error) {
-	cacheKey := fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID)
+// GetProductsByIDsFromRedis fetches all keys in one round trip, cache misses are simply absent from the map.
+func (r *ProductRepository) GetProductsByIDsFromRedis(ctx context.Context, productIDs []int64, locale string) (map[int64]*models.Product, error) {
+	cacheKeys := make([]string, len(productIDs))
+	for i, productID := range productIDs {
+		cacheKeys[i] = fmt.Sprintf(cacheKeyProductInfo, productID, locale)
+	}
+
+	values, err := r.Redis.MGet(ctx, cacheKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]*models.Product, len(productIDs))
+	for i, value := range values {
+		productStr, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		product := new(models.Product)
+		if err = json.Unmarshal([]byte(productStr), product); err != nil {
+			return nil, err
+		}
+		products[productIDs[i]] = product
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) SetProductsByID(ctx context.Context, products []*models.Product, locale string) error {
+	pipe := r.Redis.Pipeline()
+	for _, product := range products {
+		productJSON, err := json.Marshal(product)
+		if err != nil {
+			return err
+		}
+		pipe.SetEx(ctx, fmt.Sprintf(cacheKeyProductInfo, product.ID, locale), productJSON, 10 * time.Minute)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) GetProductCategoryByIDFromRedis(ctx context.Context, productCategoryID int, locale string) (*models.ProductCategory, error) {
+	cacheKey := fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID, locale)
 
 	productCategoryStr, err := r.Redis.Get(ctx, cacheKey).Result()
 	if err != nil {
@@ -54,8 +175,8 @@ func (r *ProductRepository) GetProductCategoryByIDFromRedis(ctx context.Context,
 	return productCategory, nil
 }
 
-func (r *ProductRepository) SetProductByID(ctx context.Context, product *models.Product, productID int64) error {
-	cacheKey := fmt.Sprintf(cacheKeyProductInfo, productID)
+func (r *ProductRepository) SetProductByID(ctx context.Context, product *models.Product, productID int64, locale string) error {
+	cacheKey := fmt.Sprintf(cacheKeyProductInfo, productID, locale)
 
 	productJSON, err := json.Marshal(product)
 	if err != nil {
@@ -69,8 +190,8 @@ func (r *ProductRepository) SetProductByID(ctx context.Context, product *models.
 	return nil
 }
 
-func (r *ProductRepository) SetProductCategoryByID (ctx context.Context, productCategory *models.ProductCategory, productCategoryID int) error {
-	cacheKey := fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID)
+func (r *ProductRepository) SetProductCategoryByID (ctx context.Context, productCategory *models.ProductCategory, productCategoryID int, locale string) error {
+	cacheKey := fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID, locale)
 
 	productCategoryJSON, err := json.Marshal(productCategory)
 	if err != nil {
@@ -82,4 +203,221 @@ func (r *ProductRepository) SetProductCategoryByID (ctx context.Context, product
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// DeleteProductCacheByID drops the entries of every supported locale.
+func (r *ProductRepository) DeleteProductCacheByID(ctx context.Context, productID int64) error {
+	cacheKeys := make([]string, len(models.SupportedLocales))
+	for i, locale := range models.SupportedLocales {
+		cacheKeys[i] = fmt.Sprintf(cacheKeyProductInfo, productID, locale)
+	}
+
+	if err := r.Redis.Del(ctx, cacheKeys...).Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ProductRepository) GetAllProductCategoriesFromRedis(ctx context.Context, locale string) ([]models.ProductCategory, error) {
+	productCategoriesStr, err := r.Redis.Get(ctx, fmt.Sprintf(cacheKeyProductCategoryAll, locale)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var productCategories []models.ProductCategory
+	if err = json.Unmarshal([]byte(productCategoriesStr), &productCategories); err != nil {
+		return nil, err
+	}
+	return productCategories, nil
+}
+
+func (r *ProductRepository) SetAllProductCategories(ctx context.Context, productCategories []models.ProductCategory, locale string) error {
+	productCategoriesJSON, err := json.Marshal(productCategories)
+	if err != nil {
+		return err
+	}
+
+	if err = r.Redis.SetEx(ctx, fmt.Sprintf(cacheKeyProductCategoryAll, locale), productCategoriesJSON, 10 * time.Minute).Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProductCategoryCache drops the entries of every supported locale, the category list included.
+func (r *ProductRepository) DeleteProductCategoryCache(ctx context.Context, productCategoryID int) error {
+	var cacheKeys []string
+	for _, locale := range models.SupportedLocales {
+		cacheKeys = append(cacheKeys, fmt.Sprintf(cacheKeyProductCategoryInfo, productCategoryID, locale), fmt.Sprintf(cacheKeyProductCategoryAll, locale))
+	}
+
+	if err := r.Redis.Del(ctx, cacheKeys...).Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LoadFlashSaleStock reports false when the counter already exists, so a sale is never loaded twice.
+func (r *ProductRepository) LoadFlashSaleStock(ctx context.Context, flashSaleID int64, stock int) (bool, error) {
+	loaded, err := r.Redis.SetNX(ctx, fmt.Sprintf(keyFlashSaleStock, flashSaleID), stock, 0).Result()
+	if err != nil {
+		return false, err
+	}
+	return loaded, nil
+}
+
+func (r *ProductRepository) DeleteFlashSaleKeys(ctx context.Context, flashSaleID int64) error {
+	return r.Redis.Del(ctx,
+		fmt.Sprintf(keyFlashSaleStock, flashSaleID),
+		fmt.Sprintf(keyFlashSaleBuyers, flashSaleID),
+		fmt.Sprintf(keyFlashSaleFinal, flashSaleID),
+	).Err()
+}
+
+func (r *ProductRepository) SetActiveFlashSale(ctx context.Context, flashSale *models.FlashSale) error {
+	field := fmt.Sprintf("%d:%d", flashSale.ProductID, flashSale.VariantID)
+	value := fmt.Sprintf("%d:%s:%d", flashSale.ID, strconv.FormatFloat(flashSale.Price, 'f', -1, 64), flashSale.PerUserLimit)
+	return r.Redis.HSet(ctx, keyFlashSaleActive, field, value).Err()
+}
+
+func (r *ProductRepository) DeleteActiveFlashSale(ctx context.Context, flashSale *models.FlashSale) error {
+	return r.Redis.HDel(ctx, keyFlashSaleActive, fmt.Sprintf("%d:%d", flashSale.ProductID, flashSale.VariantID)).Err()
+}
+
+// GetActiveFlashSales returns the running sale of every item that has one, keyed by its position in items.
+func (r *ProductRepository) GetActiveFlashSales(ctx context.Context, items []models.ProductAvailabilityItem) (map[int]models.FlashSale, error) {
+	fields := make([]string, len(items))
+	for i, item := range items {
+		fields[i] = fmt.Sprintf("%d:%d", item.ProductID, item.VariantID)
+	}
+
+	values, err := r.Redis.HMGet(ctx, keyFlashSaleActive, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	flashSales := map[int]models.FlashSale{}
+	for i, value := range values {
+		valueStr, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		parts := strings.Split(valueStr, ":")
+		if len(parts) != 3 {
+			continue
+		}
+
+		flashSaleID, errID := strconv.ParseInt(parts[0], 10, 64)
+		price, errPrice := strconv.ParseFloat(parts[1], 64)
+		perUserLimit, errLimit := strconv.Atoi(parts[2])
+		if errID != nil || errPrice != nil || errLimit != nil {
+			continue
+		}
+
+		flashSales[i] = models.FlashSale{
+			ID: flashSaleID,
+			ProductID: items[i].ProductID,
+			VariantID: items[i].VariantID,
+			Price: price,
+			PerUserLimit: perUserLimit,
+		}
+	}
+	return flashSales, nil
+}
+
+// ReserveFlashSaleStock returns FlashSaleReserved, or the failure code and the index of the item that failed.
+func (r *ProductRepository) ReserveFlashSaleStock(ctx context.Context, userID int64, reservations []models.FlashSaleReservation, flashSales map[int64]models.FlashSale) (int, int, error) {
+	keys := make([]string, 0, len(reservations)*2)
+	args := make([]interface{}, 0, len(reservations)*2+1)
+	args = append(args, userID)
+	for _, reservation := range reservations {
+		keys = append(keys, fmt.Sprintf(keyFlashSaleStock, reservation.FlashSaleID), fmt.Sprintf(keyFlashSaleBuyers, reservation.FlashSaleID))
+		args = append(args, reservation.Quantity, flashSales[reservation.FlashSaleID].PerUserLimit)
+	}
+
+	result, err := reserveFlashSaleScript.Run(ctx, r.Redis, keys, args...).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(result[0]), int(result[1]) - 1, nil
+}
+
+// ReleaseFlashSaleStock reports false when the sale already ended and nothing was released.
+func (r *ProductRepository) ReleaseFlashSaleStock(ctx context.Context, userID int64, flashSaleID int64, quantity int) (bool, error) {
+	keys := []string{fmt.Sprintf(keyFlashSaleStock, flashSaleID), fmt.Sprintf(keyFlashSaleBuyers, flashSaleID)}
+	released, err := releaseFlashSaleScript.Run(ctx, r.Redis, keys, userID, quantity).Int()
+	if err != nil {
+		return false, err
+	}
+	return released == 1, nil
+}
+
+func (r *ProductRepository) GetFlashSaleStock(ctx context.Context, flashSaleID int64) (int, bool, error) {
+	stock, err := r.Redis.Get(ctx, fmt.Sprintf(keyFlashSaleStock, flashSaleID)).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return stock, true, nil
+}
+
+// EndFlashSaleStock reports false when the counter was lost and the remaining stock is unknown.
+func (r *ProductRepository) EndFlashSaleStock(ctx context.Context, flashSaleID int64) (int, bool, error) {
+	keys := []string{fmt.Sprintf(keyFlashSaleStock, flashSaleID), fmt.Sprintf(keyFlashSaleFinal, flashSaleID)}
+	remaining, err := endFlashSaleScript.Run(ctx, r.Redis, keys).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return remaining, true, nil
+}
+
+// AddRecentlyViewedProduct moves productID to the front of the list and drops what falls off its end.
+func (r *ProductRepository) AddRecentlyViewedProduct(ctx context.Context, userID int64, productID int64, viewTime time.Time) error {
+	key := fmt.Sprintf(keyRecentlyViewed, userID)
+
+	pipe := r.Redis.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{
+		Score: float64(viewTime.UnixMilli()),
+		Member: productID,
+	})
+	pipe.ZRemRangeByRank(ctx, key, 0, -RecentlyViewedLimit-1)
+	pipe.Expire(ctx, key, recentlyViewedTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRecentlyViewedProductIDs returns the latest view first.
+func (r *ProductRepository) GetRecentlyViewedProductIDs(ctx context.Context, userID int64) ([]int64, error) {
+	members, err := r.Redis.ZRevRange(ctx, fmt.Sprintf(keyRecentlyViewed, userID), 0, RecentlyViewedLimit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		productID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, productID)
+	}
+	return productIDs, nil
+}
+
+func (r *ProductRepository) RemoveRecentlyViewedProducts(ctx context.Context, userID int64, productIDs []int64) error {
+	members := make([]interface{}, len(productIDs))
+	for i, productID := range productIDs {
+		members[i] = productID
+	}
+	return r.Redis.ZRem(ctx, fmt.Sprintf(keyRecentlyViewed, userID), members...).Err()
+}
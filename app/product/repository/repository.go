@@ -2,17 +2,24 @@ package repository
 
 import (
 	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
 	"gorm.io/gorm"
 )
 
 type ProductRepository struct {
 	Database *gorm.DB
 	Redis *redis.Client
+	Storage BlobStorage
+	Publisher *kafka.Writer
+	Notifier StockNotifier
 }
 
-func NewProductRepository(db *gorm.DB, redis *redis.Client) *ProductRepository {
+func NewProductRepository(db *gorm.DB, redis *redis.Client, storage BlobStorage, publisher *kafka.Writer, notifier StockNotifier) *ProductRepository {
 	return &ProductRepository{
 		Database: db,
 		Redis: redis,
+		Storage: storage,
+		Publisher: publisher,
+		Notifier: notifier,
 	}
-}
\ No newline at end of file
+}
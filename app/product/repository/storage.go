@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
+)
+
+type BlobStorage interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+func NewBlobStorage(cfg config.StorageConfig) (BlobStorage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalStorage(cfg.LocalPath, cfg.PublicURL)
+	case "s3":
+		return NewS3Storage(cfg.S3, cfg.PublicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+func (r *ProductRepository) PutObject(ctx context.Context, key string, content []byte, contentType string) error {
+	return r.Storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType)
+}
+
+func (r *ProductRepository) DeleteObject(ctx context.Context, key string) error {
+	return r.Storage.Delete(ctx, key)
+}
+
+func (r *ProductRepository) ObjectURL(key string) string {
+	return r.Storage.URL(key)
+}
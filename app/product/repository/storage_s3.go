@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3Storage works with any S3 compatible server, e.g. AWS S3, MinIO or Cloudflare R2
+type s3Storage struct {
+	client *minio.Client
+	bucket string
+	publicURL string
+}
+
+func NewS3Storage(cfg config.S3Config, publicURL string) (BlobStorage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds: credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Storage{
+		client: client,
+		bucket: cfg.Bucket,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, content, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *s3Storage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
@@ -3,14 +3,86 @@ package routes
 import (
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/handler"
 	"github.com/PorcoGalliard/eCommerce-Microservice/middleware"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, productHandler *handler.ProductHandler) {
+func SetupRoutes(router *gin.Engine, productHandler *handler.ProductHandler, JWTSecret string) {
 	router.Use(middleware.RequestLogger())
-	router.POST("/v1/product_category", productHandler.ProductCategoryManagement)
-	router.POST("/v1/product", productHandler.ProductManagement)
+	// Deprecated action-switch endpoints, kept while clients move to /v1/products and /v1/categories
+	router.POST("/v1/product_category", middleware.AuthMiddleware(JWTSecret), middleware.RoleMiddleware(models.RoleAdmin, models.RoleStaff), middleware.DeprecationMiddleware("/v1/categories"), productHandler.ProductCategoryManagement)
+	router.POST("/v1/product", middleware.AuthMiddleware(JWTSecret), middleware.RoleMiddleware(models.RoleAdmin, models.RoleStaff), middleware.DeprecationMiddleware("/v1/products"), productHandler.ProductManagement)
+	router.POST("/v1/product_variant", middleware.AuthMiddleware(JWTSecret), middleware.RoleMiddleware(models.RoleAdmin, models.RoleStaff), middleware.DeprecationMiddleware("/v1/products/{id}/variants"), productHandler.ProductVariantManagement)
 
-	router.GET("/v1/product/:id", productHandler.GetProductInfo)
+	router.GET("/v1/product", productHandler.GetProducts)
+	router.GET("/v1/product/:id", middleware.OptionalAuthMiddleware(JWTSecret), productHandler.GetProductInfo)
+	router.GET("/v1/product/:id/reviews", productHandler.GetProductReviews)
+	router.GET("/v1/product_category/tree", productHandler.GetProductCategoryTree)
 	router.GET("/v1/product_category/:id", productHandler.GetProductCategoryInfo)
+	router.GET("/v1/product_category/:id/breadcrumb", productHandler.GetProductCategoryBreadcrumb)
+
+	router.GET("/v1/products", productHandler.GetProducts)
+	router.GET("/v1/products/:id", middleware.OptionalAuthMiddleware(JWTSecret), productHandler.GetProductInfo)
+	router.GET("/v1/products/:id/related", productHandler.GetRelatedProducts)
+	router.GET("/v1/products/by-slug/:slug", productHandler.GetProductBySlug)
+	router.GET("/v1/categories", productHandler.GetProductCategoryTree)
+	router.GET("/v1/categories/:id", productHandler.GetProductCategoryInfo)
+	router.GET("/v1/categories/by-slug/:slug", productHandler.GetProductCategoryBySlug)
+	router.GET("/v1/categories/:id/breadcrumb", productHandler.GetProductCategoryBreadcrumb)
+	router.GET("/v1/categories/:id/attributes", productHandler.GetProductCategoryAttributes)
+	router.GET("/v1/flash_sales", productHandler.GetFlashSales)
+	router.GET("/v1/sitemap.xml", productHandler.GetSitemap)
+
+	// Customer API
+	customer := router.Group("/v1")
+	customer.Use(middleware.AuthMiddleware(JWTSecret))
+	customer.POST("/product/:id/reviews", productHandler.CreateProductReview)
+	customer.POST("/product_review/:id/helpful", productHandler.VoteProductReview)
+	customer.DELETE("/product_review/:id/helpful", productHandler.UnvoteProductReview)
+	customer.POST("/products/:id/stock_subscription", productHandler.SubscribeProductStock)
+	customer.DELETE("/products/:id/stock_subscription", productHandler.UnsubscribeProductStock)
+	customer.GET("/wishlist", productHandler.GetWishlist)
+	customer.POST("/wishlist", productHandler.AddWishlistItem)
+	customer.DELETE("/wishlist/:id", productHandler.RemoveWishlistItem)
+	customer.GET("/recently_viewed", productHandler.GetRecentlyViewedProducts)
+
+	// Staff API
+	staff := router.Group("/v1")
+	staff.Use(middleware.AuthMiddleware(JWTSecret), middleware.RoleMiddleware(models.RoleAdmin, models.RoleStaff))
+	staff.POST("/products", productHandler.CreateProduct)
+	staff.PUT("/products/:id", productHandler.ReplaceProduct)
+	staff.PATCH("/products/:id", productHandler.PatchProduct)
+	staff.DELETE("/products/:id", productHandler.DeleteProduct)
+	staff.POST("/products/:id/variants", productHandler.CreateProductVariant)
+	staff.PUT("/products/:id/variants/:variant_id", productHandler.ReplaceProductVariant)
+	staff.PATCH("/products/:id/variants/:variant_id", productHandler.PatchProductVariant)
+	staff.DELETE("/products/:id/variants/:variant_id", productHandler.DeleteProductVariant)
+	staff.POST("/categories", productHandler.CreateProductCategory)
+	staff.PUT("/categories/:id", productHandler.ReplaceProductCategory)
+	staff.PATCH("/categories/:id", productHandler.PatchProductCategory)
+	staff.DELETE("/categories/:id", productHandler.DeleteProductCategory)
+	staff.PUT("/products/:id/translations/:locale", productHandler.SaveProductTranslation)
+	staff.DELETE("/products/:id/translations/:locale", productHandler.DeleteProductTranslation)
+	staff.PUT("/categories/:id/translations/:locale", productHandler.SaveProductCategoryTranslation)
+	staff.DELETE("/categories/:id/translations/:locale", productHandler.DeleteProductCategoryTranslation)
+	staff.POST("/categories/:id/attributes", productHandler.CreateProductAttribute)
+	staff.PUT("/attributes/:id", productHandler.UpdateProductAttribute)
+	staff.DELETE("/attributes/:id", productHandler.DeleteProductAttribute)
+	staff.POST("/flash_sales", productHandler.CreateFlashSale)
+	staff.POST("/flash_sales/:id/end", productHandler.EndFlashSale)
+	staff.GET("/warehouses", productHandler.GetWarehouses)
+	staff.POST("/warehouses", productHandler.CreateWarehouse)
+	staff.PUT("/warehouses/:id", productHandler.UpdateWarehouse)
+	staff.PUT("/warehouses/:id/stock", productHandler.SetWarehouseStock)
+	staff.POST("/product/import", productHandler.ImportProducts)
+	staff.GET("/product/export", productHandler.ExportProducts)
+	staff.PUT("/product/:id/status", productHandler.UpdateProductStatus)
+	staff.POST("/product/:id/restore", productHandler.RestoreProduct)
+	staff.POST("/product/:id/prices", productHandler.ScheduleProductPrice)
+	staff.GET("/product/:id/prices", productHandler.GetProductPrices)
+	staff.GET("/product_review", productHandler.GetProductReviewsForModeration)
+	staff.PUT("/product_review/:id/status", productHandler.UpdateProductReviewStatus)
+	staff.POST("/product/:id/images", productHandler.UploadProductImage)
+	staff.PUT("/product/:id/images/order", productHandler.ReorderProductImages)
+	staff.DELETE("/product/:id/images/:image_id", productHandler.DeleteProductImage)
 }
\ No newline at end of file
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"gorm.io/gorm"
+)
+
+// GetProductAttributes returns the attributes defined on productCategoryIDs, or every attribute when it is nil.
+func (s *ProductService) GetProductAttributes(ctx context.Context, productCategoryIDs []int64) ([]models.ProductAttribute, error) {
+	productAttributes, err := s.ProductRepo.FindProductAttributes(ctx, productCategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	return productAttributes, nil
+}
+
+func (s *ProductService) GetProductAttributeByID(ctx context.Context, productAttributeID int64) (*models.ProductAttribute, error) {
+	productAttribute, err := s.ProductRepo.FindProductAttributeByID(ctx, productAttributeID)
+	if err != nil {
+		return nil, err
+	}
+	return productAttribute, nil
+}
+
+func (s *ProductService) CreateProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) (int64, error) {
+	productAttributeID, err := s.ProductRepo.InsertProductAttribute(ctx, productAttribute)
+	if err != nil {
+		return 0, err
+	}
+	return productAttributeID, nil
+}
+
+func (s *ProductService) UpdateProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) (*models.ProductAttribute, error) {
+	updatedProductAttribute, err := s.ProductRepo.UpdateProductAttribute(ctx, productAttribute)
+	if err != nil {
+		return nil, err
+	}
+	return updatedProductAttribute, nil
+}
+
+func (s *ProductService) CountProductAttributeValues(ctx context.Context, productAttributeID int64) (int64, error) {
+	total, err := s.ProductRepo.CountProductAttributeValues(ctx, productAttributeID)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// DeleteProductAttribute removes the definition together with every value products had for it.
+func (s *ProductService) DeleteProductAttribute(ctx context.Context, productAttributeID int64) error {
+	productIDs, err := s.ProductRepo.FindProductIDsByAttributeID(ctx, productAttributeID)
+	if err != nil {
+		return err
+	}
+
+	err = s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		return s.ProductRepo.DeleteProductAttributeTx(ctx, tx, productAttributeID)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, productID := range productIDs {
+		s.invalidateProductCache(ctx, productID)
+	}
+	return nil
+}
+
+// GetProductFacets counts the values of facetAttributes among the listed products. Attributes without a filter
+// are counted in one query, every filtered attribute is counted on its own without its filter.
+func (s *ProductService) GetProductFacets(ctx context.Context, param *models.ProductListParameter, facetAttributes []models.ProductAttribute) ([]models.ProductFacet, error) {
+	if len(facetAttributes) == 0 {
+		return []models.ProductFacet{}, nil
+	}
+
+	filtered := make(map[string]bool, len(param.Filters))
+	for _, attributeFilter := range param.Filters {
+		filtered[attributeFilter.Code] = true
+	}
+
+	var unfilteredCodes []string
+	for _, productAttribute := range facetAttributes {
+		if !filtered[productAttribute.Code] {
+			unfilteredCodes = append(unfilteredCodes, productAttribute.Code)
+		}
+	}
+
+	var productFacetCounts []models.ProductFacetCount
+	if len(unfilteredCodes) != 0 {
+		counts, err := s.ProductRepo.CountProductFacets(ctx, param, unfilteredCodes, "")
+		if err != nil {
+			return nil, err
+		}
+		productFacetCounts = append(productFacetCounts, counts...)
+	}
+
+	for _, productAttribute := range facetAttributes {
+		if !filtered[productAttribute.Code] {
+			continue
+		}
+
+		counts, err := s.ProductRepo.CountProductFacets(ctx, param, []string{productAttribute.Code}, productAttribute.Code)
+		if err != nil {
+			return nil, err
+		}
+		productFacetCounts = append(productFacetCounts, counts...)
+	}
+
+	valuesByCode := map[string][]models.ProductFacetValue{}
+	for _, productFacetCount := range productFacetCounts {
+		valuesByCode[productFacetCount.Code] = append(valuesByCode[productFacetCount.Code], models.ProductFacetValue{
+			Value: productFacetCount.Value,
+			Count: productFacetCount.Count,
+		})
+	}
+
+	productFacets := make([]models.ProductFacet, 0, len(facetAttributes))
+	for _, productAttribute := range facetAttributes {
+		values := valuesByCode[productAttribute.Code]
+		if values == nil {
+			values = []models.ProductFacetValue{}
+		}
+
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+
+		productFacets = append(productFacets, models.ProductFacet{
+			Code: productAttribute.Code,
+			Name: productAttribute.Name,
+			Type: productAttribute.Type,
+			Values: values,
+		})
+	}
+	return productFacets, nil
+}
+
+func (s *ProductService) attachProductAttributes(ctx context.Context, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	productAttributeValues, err := s.ProductRepo.FindProductAttributeValuesByProductIDs(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(productAttributeValues) == 0 {
+		return nil
+	}
+
+	var productAttributeIDs []int64
+	for _, productAttributeValue := range productAttributeValues {
+		productAttributeIDs = append(productAttributeIDs, productAttributeValue.AttributeID)
+	}
+
+	productAttributes, err := s.ProductRepo.FindProductAttributesByIDs(ctx, productAttributeIDs)
+	if err != nil {
+		return err
+	}
+
+	codeByID := make(map[int64]string, len(productAttributes))
+	for _, productAttribute := range productAttributes {
+		codeByID[productAttribute.ID] = productAttribute.Code
+	}
+
+	attributesByProductID := map[int64]map[string]string{}
+	for _, productAttributeValue := range productAttributeValues {
+		code, ok := codeByID[productAttributeValue.AttributeID]
+		if !ok {
+			continue
+		}
+
+		if attributesByProductID[productAttributeValue.ProductID] == nil {
+			attributesByProductID[productAttributeValue.ProductID] = map[string]string{}
+		}
+		attributesByProductID[productAttributeValue.ProductID][code] = productAttributeValue.Value
+	}
+
+	for _, product := range products {
+		product.Attributes = attributesByProductID[product.ID]
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+)
+
+// GetProductsByIDs serves what it can from the cache and loads the rest from the database in one query.
+// Products come back in the order of productIDs with their text in DefaultLocale, unknown IDs are skipped.
+func (s *ProductService) GetProductsByIDs(ctx context.Context, productIDs []int64) ([]models.Product, error) {
+	cachedProducts, err := s.ProductRepo.GetProductsByIDsFromRedis(ctx, productIDs, models.DefaultLocale)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productIDs": productIDs,
+		}).Errorf("s.ProductRepo.GetProductsByIDsFromRedis got an error at %v", err)
+		cachedProducts = map[int64]*models.Product{}
+	}
+
+	var missingProductIDs []int64
+	for _, productID := range productIDs {
+		if _, ok := cachedProducts[productID]; !ok {
+			missingProductIDs = append(missingProductIDs, productID)
+		}
+	}
+
+	if len(missingProductIDs) != 0 {
+		loadedProducts, err := s.GetProductsByIDsFromDatabase(ctx, missingProductIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		productRefs := make([]*models.Product, len(loadedProducts))
+		for i := range loadedProducts {
+			productRefs[i] = &loadedProducts[i]
+			cachedProducts[loadedProducts[i].ID] = &loadedProducts[i]
+		}
+
+		if len(productRefs) != 0 {
+			if err = s.ProductRepo.SetProductsByID(ctx, productRefs, models.DefaultLocale); err != nil {
+				log.Logger.WithFields(logrus.Fields{
+					"productIDs": missingProductIDs,
+				}).Errorf("s.ProductRepo.SetProductsByID got an error at %v", err)
+			}
+		}
+	}
+
+	now := time.Now()
+	products := make([]models.Product, 0, len(productIDs))
+	for _, productID := range productIDs {
+		product, ok := cachedProducts[productID]
+		if !ok {
+			continue
+		}
+
+		applyEffectivePrice(product, now)
+		products = append(products, *product)
+	}
+	return products, nil
+}
+
+// GetProductsByIDsFromDatabase skips the cache, for callers that need the current stock.
+func (s *ProductService) GetProductsByIDsFromDatabase(ctx context.Context, productIDs []int64) ([]models.Product, error) {
+	products, err := s.ProductRepo.FindProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productRefs := make([]*models.Product, len(products))
+	for i := range products {
+		productRefs[i] = &products[i]
+	}
+
+	if err = s.attachProductVariants(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductImages(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductAttributes(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductSales(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductBundles(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, product := range productRefs {
+		applyEffectivePrice(product, now)
+	}
+	return products, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+)
+
+// attachProductBundles fills the components of the bundles among products and derives their stock: the
+// number of whole bundles the components can make up, 0 once a component can not be bought.
+func (s *ProductService) attachProductBundles(ctx context.Context, products []*models.Product) error {
+	var bundleIDs []int64
+	for _, product := range products {
+		if product.Type == models.ProductTypeBundle {
+			bundleIDs = append(bundleIDs, product.ID)
+		}
+	}
+
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+
+	productBundleItems, err := s.ProductRepo.FindProductBundleItemsByBundleIDs(ctx, bundleIDs)
+	if err != nil {
+		return err
+	}
+
+	itemsByBundleID := map[int64][]models.ProductBundleItem{}
+	var componentIDs []int64
+	for _, productBundleItem := range productBundleItems {
+		itemsByBundleID[productBundleItem.BundleID] = append(itemsByBundleID[productBundleItem.BundleID], productBundleItem)
+		componentIDs = append(componentIDs, productBundleItem.ProductID)
+	}
+
+	componentsByID := map[int64]*models.Product{}
+	variantsByID := map[int64]*models.ProductVariant{}
+	if len(componentIDs) != 0 {
+		components, err := s.ProductRepo.FindProductsByIDs(ctx, componentIDs)
+		if err != nil {
+			return err
+		}
+
+		for i := range components {
+			componentsByID[components[i].ID] = &components[i]
+		}
+
+		productVariants, err := s.ProductRepo.FindProductVariantsByProductIDs(ctx, componentIDs)
+		if err != nil {
+			return err
+		}
+
+		for i := range productVariants {
+			variantsByID[productVariants[i].ID] = &productVariants[i]
+		}
+	}
+
+	for _, product := range products {
+		if product.Type != models.ProductTypeBundle {
+			continue
+		}
+
+		product.BundleItems = itemsByBundleID[product.ID]
+		product.Stock = bundleStock(product.BundleItems, componentsByID, variantsByID)
+	}
+	return nil
+}
+
+func bundleStock(productBundleItems []models.ProductBundleItem, componentsByID map[int64]*models.Product, variantsByID map[int64]*models.ProductVariant) int {
+	if len(productBundleItems) == 0 {
+		return 0
+	}
+
+	stock := -1
+	for _, productBundleItem := range productBundleItems {
+		component, ok := componentsByID[productBundleItem.ProductID]
+		if !ok || !component.IsPurchasable() || component.Type == models.ProductTypeBundle || productBundleItem.Quantity <= 0 {
+			return 0
+		}
+
+		componentStock := component.Stock
+		if productBundleItem.VariantID != 0 {
+			productVariant, ok := variantsByID[productBundleItem.VariantID]
+			if !ok || productVariant.ProductID != component.ID {
+				return 0
+			}
+			componentStock = productVariant.Stock
+		}
+
+		units := componentStock / productBundleItem.Quantity
+		if stock == -1 || units < stock {
+			stock = units
+		}
+	}
+
+	if stock < 0 {
+		return 0
+	}
+	return stock
+}
+
+func (s *ProductService) GetBundleIDsByComponentIDs(ctx context.Context, productIDs []int64) ([]int64, error) {
+	bundleIDs, err := s.ProductRepo.FindBundleIDsByComponentIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return bundleIDs, nil
+}
+
+// invalidateBundleCaches drops the cached bundles containing any of productIDs, their derived stock and
+// availability change with the components.
+func (s *ProductService) invalidateBundleCaches(ctx context.Context, productIDs []int64) {
+	if len(productIDs) == 0 {
+		return
+	}
+
+	bundleIDs, err := s.ProductRepo.FindBundleIDsByComponentIDs(ctx, productIDs)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productIDs": productIDs,
+		}).Errorf("s.ProductRepo.FindBundleIDsByComponentIDs got an error at %v", err)
+		return
+	}
+
+	for _, bundleID := range bundleIDs {
+		if err = s.ProductRepo.DeleteProductCacheByID(ctx, bundleID); err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"productID": bundleID,
+			}).Errorf("s.ProductRepo.DeleteProductCacheByID got an error at %v", err)
+		}
+	}
+}
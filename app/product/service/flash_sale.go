@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/repository"
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const flashSaleInterval = 5 * time.Second
+
+var (
+	// errFlashSaleNotEnoughStock cancels a sale whose allocation is no longer in stock when it should start.
+	errFlashSaleNotEnoughStock = errors.New("not enough stock to start the flash sale")
+	// errFlashSaleNotScheduled skips a sale that was cancelled while it was being started.
+	errFlashSaleNotScheduled = errors.New("flash sale is no longer scheduled")
+)
+
+func (s *ProductService) CreateFlashSale(ctx context.Context, flashSale *models.FlashSale) (int64, error) {
+	flashSaleID, err := s.ProductRepo.InsertFlashSale(ctx, flashSale)
+	if err != nil {
+		return 0, err
+	}
+	return flashSaleID, nil
+}
+
+func (s *ProductService) GetFlashSaleByID(ctx context.Context, flashSaleID int64) (*models.FlashSale, error) {
+	flashSale, err := s.ProductRepo.FindFlashSaleByID(ctx, flashSaleID)
+	if err != nil {
+		return nil, err
+	}
+	return flashSale, nil
+}
+
+func (s *ProductService) CountOverlappingFlashSales(ctx context.Context, flashSale *models.FlashSale) (int64, error) {
+	total, err := s.ProductRepo.CountOverlappingFlashSales(ctx, flashSale)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *ProductService) UpdateFlashSale(ctx context.Context, flashSaleID int64, updates map[string]interface{}) error {
+	if err := s.ProductRepo.UpdateFlashSale(ctx, flashSaleID, updates); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetFlashSales fills in the live remaining stock of running sales from Redis.
+func (s *ProductService) GetFlashSales(ctx context.Context, statuses []string) ([]models.FlashSale, error) {
+	flashSales, err := s.ProductRepo.FindFlashSalesByStatuses(ctx, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range flashSales {
+		if flashSales[i].Status != models.FlashSaleStatusActive {
+			continue
+		}
+
+		remaining, ok, err := s.ProductRepo.GetFlashSaleStock(ctx, flashSales[i].ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			flashSales[i].RemainingStock = &remaining
+		}
+	}
+	return flashSales, nil
+}
+
+// ReserveFlashSaleStock reserves the items that are on a running flash sale. It returns the reservations,
+// or the failure code of the repository and the index of the failing item in items.
+func (s *ProductService) ReserveFlashSaleStock(ctx context.Context, userID int64, items []models.ProductAvailabilityItem) ([]models.FlashSaleReservation, int, int, error) {
+	activeFlashSales, err := s.ProductRepo.GetActiveFlashSales(ctx, items)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if len(activeFlashSales) == 0 {
+		return []models.FlashSaleReservation{}, repository.FlashSaleReserved, 0, nil
+	}
+
+	var reservations []models.FlashSaleReservation
+	var itemIndexes []int
+	flashSales := map[int64]models.FlashSale{}
+	for i, item := range items {
+		flashSale, ok := activeFlashSales[i]
+		if !ok {
+			continue
+		}
+
+		flashSales[flashSale.ID] = flashSale
+		itemIndexes = append(itemIndexes, i)
+		reservations = append(reservations, models.FlashSaleReservation{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity: item.Quantity,
+			FlashSaleID: flashSale.ID,
+			Price: flashSale.Price,
+		})
+	}
+
+	code, failedIndex, err := s.ProductRepo.ReserveFlashSaleStock(ctx, userID, reservations, flashSales)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if code != repository.FlashSaleReserved {
+		return nil, code, itemIndexes[failedIndex], nil
+	}
+	return reservations, repository.FlashSaleReserved, 0, nil
+}
+
+// ReleaseFlashSaleStock hands reserved units back to their sale. Units of a sale that already ended go to
+// product.stock instead, the end of sale reconciliation has counted them as sold.
+func (s *ProductService) ReleaseFlashSaleStock(ctx context.Context, userID int64, reservations []models.FlashSaleReservation) error {
+	for _, reservation := range reservations {
+		released, err := s.ProductRepo.ReleaseFlashSaleStock(ctx, userID, reservation.FlashSaleID, reservation.Quantity)
+		if err != nil {
+			return err
+		}
+
+		if released {
+			continue
+		}
+
+		err = s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+			return s.ProductRepo.AddStockTx(ctx, tx, reservation.ProductID, reservation.VariantID, reservation.Quantity)
+		})
+		if err != nil {
+			return err
+		}
+		s.invalidateProductCache(ctx, reservation.ProductID)
+	}
+	return nil
+}
+
+// StartRunFlashSales starts due sales and reconciles ended ones. It is safe to run on every instance,
+// the Redis counter and the row lock on the sale decide which instance does the work.
+func (s *ProductService) StartRunFlashSales() {
+	ticker := time.NewTicker(flashSaleInterval)
+
+	go func() {
+		for range ticker.C {
+			ctx := context.Background()
+			now := time.Now()
+			s.startDueFlashSales(ctx, now)
+			s.syncActiveFlashSales(ctx, now)
+		}
+	}()
+}
+
+func (s *ProductService) startDueFlashSales(ctx context.Context, now time.Time) {
+	flashSales, err := s.ProductRepo.FindDueFlashSales(ctx, now)
+	if err != nil {
+		log.Logger.Errorf("s.ProductRepo.FindDueFlashSales got an error at %v", err)
+		return
+	}
+
+	for i := range flashSales {
+		if err := s.startFlashSale(ctx, &flashSales[i], now); err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"flashSaleID": flashSales[i].ID,
+			}).Errorf("s.startFlashSale got an error at %v", err)
+		}
+	}
+}
+
+// startFlashSale moves the allocation from product.stock into the Redis counter.
+func (s *ProductService) startFlashSale(ctx context.Context, flashSale *models.FlashSale, now time.Time) error {
+	if !now.Before(flashSale.EndTime) {
+		return s.ProductRepo.UpdateFlashSale(ctx, flashSale.ID, map[string]interface{}{"status": models.FlashSaleStatusCancelled})
+	}
+
+	loaded, err := s.ProductRepo.LoadFlashSaleStock(ctx, flashSale.ID, flashSale.Stock)
+	if err != nil || !loaded {
+		return err
+	}
+
+	err = s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		lockedFlashSale, err := s.ProductRepo.FindFlashSaleForUpdateTx(ctx, tx, flashSale.ID)
+		if err != nil {
+			return err
+		}
+
+		if lockedFlashSale.Status != models.FlashSaleStatusScheduled {
+			return errFlashSaleNotScheduled
+		}
+
+		stock, err := s.lockedStockTx(ctx, tx, flashSale.ProductID, flashSale.VariantID)
+		if err != nil {
+			return err
+		}
+
+		if stock < flashSale.Stock {
+			return errFlashSaleNotEnoughStock
+		}
+
+		if err = s.ProductRepo.AddStockTx(ctx, tx, flashSale.ProductID, flashSale.VariantID, -flashSale.Stock); err != nil {
+			return err
+		}
+		return s.ProductRepo.UpdateFlashSaleTx(ctx, tx, flashSale.ID, map[string]interface{}{"status": models.FlashSaleStatusActive})
+	})
+	if err != nil {
+		if errDelete := s.ProductRepo.DeleteFlashSaleKeys(ctx, flashSale.ID); errDelete != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"flashSaleID": flashSale.ID,
+			}).Errorf("s.ProductRepo.DeleteFlashSaleKeys got an error at %v", errDelete)
+		}
+
+		if errors.Is(err, errFlashSaleNotEnoughStock) {
+			log.Logger.WithFields(logrus.Fields{
+				"flashSaleID": flashSale.ID,
+				"productID": flashSale.ProductID,
+			}).Warn("⚠️ Cancelling flash sale, the product no longer has the allocated stock")
+			return s.ProductRepo.UpdateFlashSale(ctx, flashSale.ID, map[string]interface{}{"status": models.FlashSaleStatusCancelled})
+		}
+
+		if errors.Is(err, errFlashSaleNotScheduled) {
+			return nil
+		}
+		return err
+	}
+
+	s.invalidateProductCache(ctx, flashSale.ProductID)
+	return s.ProductRepo.SetActiveFlashSale(ctx, flashSale)
+}
+
+// syncActiveFlashSales writes the sold quantity of running sales back to Postgres and ends the ones that are over.
+func (s *ProductService) syncActiveFlashSales(ctx context.Context, now time.Time) {
+	flashSales, err := s.ProductRepo.FindFlashSalesByStatuses(ctx, []string{models.FlashSaleStatusActive})
+	if err != nil {
+		log.Logger.Errorf("s.ProductRepo.FindFlashSalesByStatuses got an error at %v", err)
+		return
+	}
+
+	for i := range flashSales {
+		flashSale := &flashSales[i]
+		if !now.Before(flashSale.EndTime) {
+			err = s.endFlashSale(ctx, flashSale)
+		} else {
+			err = s.syncFlashSale(ctx, flashSale)
+		}
+
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"flashSaleID": flashSale.ID,
+			}).Errorf("s.syncActiveFlashSales got an error at %v", err)
+		}
+	}
+}
+
+func (s *ProductService) syncFlashSale(ctx context.Context, flashSale *models.FlashSale) error {
+	remaining, ok, err := s.ProductRepo.GetFlashSaleStock(ctx, flashSale.ID)
+	if err != nil || !ok {
+		return err
+	}
+
+	if soldQty := flashSale.Stock - remaining; soldQty != flashSale.SoldQty {
+		if err = s.ProductRepo.UpdateFlashSale(ctx, flashSale.ID, map[string]interface{}{"sold_qty": soldQty}); err != nil {
+			return err
+		}
+	}
+
+	// rewriting the entry heals a start that committed but failed to publish it
+	return s.ProductRepo.SetActiveFlashSale(ctx, flashSale)
+}
+
+// endFlashSale puts the unsold units back into product.stock and records the final sold quantity.
+func (s *ProductService) endFlashSale(ctx context.Context, flashSale *models.FlashSale) error {
+	if err := s.ProductRepo.DeleteActiveFlashSale(ctx, flashSale); err != nil {
+		return err
+	}
+
+	remaining, ok, err := s.ProductRepo.EndFlashSaleStock(ctx, flashSale.ID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		// the counter is gone, fall back to the last synced sold quantity
+		remaining = flashSale.Stock - flashSale.SoldQty
+		log.Logger.WithFields(logrus.Fields{
+			"flashSaleID": flashSale.ID,
+		}).Warnf("⚠️ Flash sale counter lost, reconciling %d remaining units from the last sync", remaining)
+	}
+
+	err = s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		lockedFlashSale, err := s.ProductRepo.FindFlashSaleForUpdateTx(ctx, tx, flashSale.ID)
+		if err != nil {
+			return err
+		}
+
+		if lockedFlashSale.Status != models.FlashSaleStatusActive {
+			return nil
+		}
+
+		if remaining > 0 {
+			if err = s.ProductRepo.AddStockTx(ctx, tx, flashSale.ProductID, flashSale.VariantID, remaining); err != nil {
+				return err
+			}
+		}
+
+		return s.ProductRepo.UpdateFlashSaleTx(ctx, tx, flashSale.ID, map[string]interface{}{
+			"status": models.FlashSaleStatusEnded,
+			"sold_qty": flashSale.Stock - remaining,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, flashSale.ProductID)
+	return s.ProductRepo.DeleteFlashSaleKeys(ctx, flashSale.ID)
+}
+
+func (s *ProductService) lockedStockTx(ctx context.Context, tx *gorm.DB, productID int64, productVariantID int64) (int, error) {
+	if productVariantID != 0 {
+		productVariants, err := s.ProductRepo.FindProductVariantsForUpdateTx(ctx, tx, []int64{productVariantID})
+		if err != nil || len(productVariants) == 0 {
+			return 0, err
+		}
+		return productVariants[0].Stock, nil
+	}
+
+	products, err := s.ProductRepo.FindProductsForUpdateTx(ctx, tx, []int64{productID})
+	if err != nil || len(products) == 0 {
+		return 0, err
+	}
+	return products[0].Stock, nil
+}
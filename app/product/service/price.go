@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const scheduledPriceInterval = time.Minute
+
+func (s *ProductService) ScheduleProductPrice(ctx context.Context, productPrice *models.ProductPrice) (int64, error) {
+	var productPriceID int64
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		if productPrice.Kind == models.ProductPriceKindSale {
+			productPriceID, err = s.ProductRepo.InsertProductPriceTx(ctx, tx, productPrice)
+			return err
+		}
+
+		// the product row is locked before its price rows, in the same order as UpdateProduct
+		product, err := s.productSnapshotTx(ctx, tx, productPrice.ProductID)
+		if err != nil {
+			return err
+		}
+
+		productPriceID, err = s.recordListPriceTx(ctx, tx, productPrice.ProductID, productPrice.Price, productPrice.EffectiveFrom)
+		if err != nil {
+			return err
+		}
+
+		if productPrice.EffectiveFrom.After(time.Now()) {
+			return nil
+		}
+		return s.applyListPriceTx(ctx, tx, product, productPrice.Price)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidateProductCache(ctx, productPrice.ProductID)
+	return productPriceID, nil
+}
+
+func (s *ProductService) GetProductPriceHistory(ctx context.Context, productID int64) ([]models.ProductPrice, error) {
+	productPrices, err := s.ProductRepo.FindProductPrices(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+func (s *ProductService) GetProductPricesAt(ctx context.Context, productID int64, at time.Time) ([]models.ProductPrice, error) {
+	productPrices, err := s.ProductRepo.FindProductPricesAt(ctx, productID, at)
+	if err != nil {
+		return nil, err
+	}
+	return productPrices, nil
+}
+
+func (s *ProductService) CountProductPricesByKind(ctx context.Context, productID int64, kind string) (int64, error) {
+	total, err := s.ProductRepo.CountProductPricesByKind(ctx, productID, kind)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// StartApplyScheduledPrices copies list prices whose time has come onto product.price.
+func (s *ProductService) StartApplyScheduledPrices() {
+	ticker := time.NewTicker(scheduledPriceInterval)
+
+	go func() {
+		for range ticker.C {
+			ctx := context.Background()
+			dueProductPrices, err := s.ProductRepo.FindDueListPrices(ctx, time.Now())
+			if err != nil {
+				log.Logger.Errorf("s.ProductRepo.FindDueListPrices got an error at %v", err)
+				continue
+			}
+
+			for _, productPrice := range dueProductPrices {
+				err = s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+					product, err := s.productSnapshotTx(ctx, tx, productPrice.ProductID)
+					if err != nil {
+						return err
+					}
+					return s.applyListPriceTx(ctx, tx, product, productPrice.Price)
+				})
+				if err != nil {
+					log.Logger.WithFields(logrus.Fields{
+						"productID": productPrice.ProductID,
+						"productPriceID": productPrice.ID,
+					}).Errorf("s.applyListPriceTx got an error at %v", err)
+					continue
+				}
+
+				s.invalidateProductCache(ctx, productPrice.ProductID)
+			}
+		}
+	}()
+}
+
+// applyListPriceTx copies price onto the locked product and publishes the repricing as product.updated.
+func (s *ProductService) applyListPriceTx(ctx context.Context, tx *gorm.DB, product *models.Product, price float64) error {
+	if product == nil || product.Price == price {
+		return nil
+	}
+
+	if err := s.ProductRepo.UpdateProductPriceTx(ctx, tx, product.ID, price); err != nil {
+		return err
+	}
+	return s.recordProductChangeTx(ctx, tx, models.TopicProductUpdated, product.ID, product)
+}
+
+// recordListPriceTx inserts a list price starting at from into the product's timeline. The entry running
+// at from is closed there and the new one runs until the next scheduled change, if any.
+func (s *ProductService) recordListPriceTx(ctx context.Context, tx *gorm.DB, productID int64, price float64, from time.Time) (int64, error) {
+	productPrices, err := s.ProductRepo.FindProductPricesForUpdateTx(ctx, tx, productID, models.ProductPriceKindList)
+	if err != nil {
+		return 0, err
+	}
+
+	productPrice := &models.ProductPrice{
+		ProductID: productID,
+		Kind: models.ProductPriceKindList,
+		Price: price,
+		EffectiveFrom: from,
+	}
+
+	for _, existing := range productPrices {
+		if existing.EffectiveFrom.Before(from) && (existing.EffectiveTo == nil || existing.EffectiveTo.After(from)) {
+			if err = s.ProductRepo.UpdateProductPriceEffectiveToTx(ctx, tx, existing.ID, from); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if existing.EffectiveFrom.After(from) && productPrice.EffectiveTo == nil {
+			effectiveTo := existing.EffectiveFrom
+			productPrice.EffectiveTo = &effectiveTo
+		}
+	}
+
+	return s.ProductRepo.InsertProductPriceTx(ctx, tx, productPrice)
+}
+
+func (s *ProductService) attachProductSales(ctx context.Context, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	productSales, err := s.ProductRepo.FindUnfinishedProductSalesByProductIDs(ctx, productIDs, time.Now())
+	if err != nil {
+		return err
+	}
+
+	salesByProductID := map[int64][]models.ProductPrice{}
+	for _, productSale := range productSales {
+		salesByProductID[productSale.ProductID] = append(salesByProductID[productSale.ProductID], productSale)
+	}
+
+	for _, product := range products {
+		product.Sales = salesByProductID[product.ID]
+	}
+	return nil
+}
+
+// applyEffectivePrice runs on every read, cached products included, so a sale starts and ends on time
+// without waiting for the cache to expire.
+func applyEffectivePrice(product *models.Product, now time.Time) {
+	product.EffectivePrice = product.Price
+
+	var sales []models.ProductPrice
+	for _, productSale := range product.Sales {
+		if productSale.EffectiveTo != nil && !productSale.EffectiveTo.After(now) {
+			continue
+		}
+
+		if !productSale.EffectiveFrom.After(now) {
+			product.EffectivePrice = productSale.Price
+		}
+		sales = append(sales, productSale)
+	}
+	product.Sales = sales
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	productOutboxInterval = time.Second
+	productOutboxBatchSize = 100
+	// productOutboxRetention keeps published events around for a while to debug consumers
+	productOutboxRetention = 7 * 24 * time.Hour
+)
+
+// changeProductTx runs fn and records the change of productID as topic in the same transaction.
+func (s *ProductService) changeProductTx(ctx context.Context, productID int64, topic string, fn func(tx *gorm.DB) error) error {
+	return s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := s.productSnapshotTx(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(tx); err != nil {
+			return err
+		}
+		return s.recordProductChangeTx(ctx, tx, topic, productID, before)
+	})
+}
+
+// productSnapshotTx locks and reads the stored row of productID, so concurrent changes of one product
+// write their events in commit order. It returns nil when the product does not exist.
+func (s *ProductService) productSnapshotTx(ctx context.Context, tx *gorm.DB, productID int64) (*models.Product, error) {
+	products, err := s.ProductRepo.FindProductsForUpdateTx(ctx, tx, []int64{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return &products[0], nil
+}
+
+// recordProductChangeTx writes the event into the outbox, it commits or rolls back together with the change.
+// The after snapshot is read here, once the change is written.
+func (s *ProductService) recordProductChangeTx(ctx context.Context, tx *gorm.DB, topic string, productID int64, before *models.Product) error {
+	var after *models.Product
+	if topic != models.TopicProductDeleted {
+		var err error
+		after, err = s.productSnapshotTx(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if topic == models.TopicProductCreated {
+		before = nil
+	}
+	return s.writeProductChangeTx(ctx, tx, topic, productID, before, after)
+}
+
+// recordProductVariantChangeTx records a variant edit as product.updated of its product. The snapshots carry
+// the variants of the product, so a consumer sees the price_override that changed.
+func (s *ProductService) recordProductVariantChangeTx(ctx context.Context, tx *gorm.DB, product *models.Product, beforeVariants []models.ProductVariant) error {
+	afterVariants, err := s.ProductRepo.FindProductVariantsByProductIDTx(ctx, tx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	before := *product
+	before.Variants = beforeVariants
+	after := *product
+	after.Variants = afterVariants
+	return s.writeProductChangeTx(ctx, tx, models.TopicProductUpdated, product.ID, &before, &after)
+}
+
+func (s *ProductService) writeProductChangeTx(ctx context.Context, tx *gorm.DB, topic string, productID int64, before *models.Product, after *models.Product) error {
+	payload, err := json.Marshal(&models.ProductChangeEvent{
+		EventID: uuid.NewString(),
+		Version: models.ProductChangeEventVersion,
+		Type: topic,
+		ProductID: productID,
+		Before: before,
+		After: after,
+		EventTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.ProductRepo.InsertProductOutboxTx(ctx, tx, &models.ProductOutbox{
+		Topic: topic,
+		MessageKey: fmt.Sprintf("product-%d", productID),
+		Payload: string(payload),
+	})
+}
+
+// StartRelayProductOutbox publishes committed events to Kafka. An event is only marked published once
+// Kafka acknowledged it, so a crash in between sends it again and never loses it.
+func (s *ProductService) StartRelayProductOutbox() {
+	ticker := time.NewTicker(productOutboxInterval)
+
+	go func() {
+		var lastCleanup time.Time
+		for range ticker.C {
+			ctx := context.Background()
+			for {
+				published, err := s.relayProductOutbox(ctx)
+				if err != nil {
+					log.Logger.Errorf("s.relayProductOutbox got an error at %v", err)
+					break
+				}
+
+				if published < productOutboxBatchSize {
+					break
+				}
+			}
+
+			if time.Since(lastCleanup) < time.Hour {
+				continue
+			}
+
+			if err := s.ProductRepo.DeletePublishedProductOutbox(ctx, time.Now().Add(-productOutboxRetention)); err != nil {
+				log.Logger.Errorf("s.ProductRepo.DeletePublishedProductOutbox got an error at %v", err)
+				continue
+			}
+			lastCleanup = time.Now()
+		}
+	}()
+}
+
+// relayProductOutbox publishes one batch in id order. A failed batch stays pending and is retried on the
+// next tick from the same event, so later events of a product never overtake it.
+func (s *ProductService) relayProductOutbox(ctx context.Context) (int, error) {
+	published := 0
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		locked, err := s.ProductRepo.TryLockProductOutboxTx(ctx, tx)
+		if err != nil || !locked {
+			return err
+		}
+
+		productOutboxes, err := s.ProductRepo.FindPendingProductOutboxTx(ctx, tx, productOutboxBatchSize)
+		if err != nil || len(productOutboxes) == 0 {
+			return err
+		}
+
+		productOutboxIDs := make([]int64, len(productOutboxes))
+		for i, productOutbox := range productOutboxes {
+			productOutboxIDs[i] = productOutbox.ID
+		}
+
+		if err = s.ProductRepo.PublishProductOutbox(ctx, productOutboxes); err != nil {
+			log.Logger.Errorf("s.ProductRepo.PublishProductOutbox got an error at %v", err)
+			return s.ProductRepo.MarkProductOutboxFailedTx(ctx, tx, productOutboxIDs, err.Error())
+		}
+
+		published = len(productOutboxes)
+		return s.ProductRepo.MarkProductOutboxPublishedTx(ctx, tx, productOutboxIDs, time.Now())
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
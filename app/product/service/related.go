@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	relatedProductInterval = 24 * time.Hour
+	// relatedProductTopN is how many related products are kept per product
+	relatedProductTopN = 20
+	orderBasketPageSize = 500
+	// maxOrderBasketSize leaves wholesale orders out of the pairs, they grow quadratically and say little
+	maxOrderBasketSize = 50
+)
+
+// StartComputeRelatedProducts rebuilds the related products from completed orders once at start and then daily.
+func (s *ProductService) StartComputeRelatedProducts() {
+	ticker := time.NewTicker(relatedProductInterval)
+
+	go func() {
+		for {
+			if err := s.ComputeRelatedProducts(context.Background()); err != nil {
+				log.Logger.Errorf("s.ComputeRelatedProducts got an error at %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ComputeRelatedProducts counts how often every two products were bought in the same completed order and
+// keeps the relatedProductTopN most frequent partners of each product, along with its order count.
+func (s *ProductService) ComputeRelatedProducts(ctx context.Context) error {
+	pairCounts := map[int64]map[int64]int{}
+	orderCounts := map[int64]int{}
+	var afterOrderID int64
+	for {
+		baskets, err := s.OrderClient.GetCompletedOrderBaskets(ctx, afterOrderID, orderBasketPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, basket := range baskets {
+			afterOrderID = basket.OrderID
+			for _, productID := range basket.ProductIDs {
+				orderCounts[productID]++
+			}
+
+			if len(basket.ProductIDs) > maxOrderBasketSize {
+				continue
+			}
+
+			for _, productID := range basket.ProductIDs {
+				for _, relatedProductID := range basket.ProductIDs {
+					if productID == relatedProductID {
+						continue
+					}
+
+					if pairCounts[productID] == nil {
+						pairCounts[productID] = map[int64]int{}
+					}
+					pairCounts[productID][relatedProductID]++
+				}
+			}
+		}
+
+		if len(baskets) < orderBasketPageSize {
+			break
+		}
+	}
+
+	var relatedProducts []models.RelatedProduct
+	for productID, counts := range pairCounts {
+		partners := make([]models.RelatedProduct, 0, len(counts))
+		for relatedProductID, score := range counts {
+			partners = append(partners, models.RelatedProduct{
+				ProductID: productID,
+				RelatedProductID: relatedProductID,
+				Score: score,
+			})
+		}
+
+		sort.Slice(partners, func(i, j int) bool {
+			if partners[i].Score != partners[j].Score {
+				return partners[i].Score > partners[j].Score
+			}
+			return partners[i].RelatedProductID < partners[j].RelatedProductID
+		})
+
+		if len(partners) > relatedProductTopN {
+			partners = partners[:relatedProductTopN]
+		}
+		relatedProducts = append(relatedProducts, partners...)
+	}
+
+	productSales := make([]models.ProductSales, 0, len(orderCounts))
+	for productID, orderCount := range orderCounts {
+		productSales = append(productSales, models.ProductSales{
+			ProductID: productID,
+			OrderCount: orderCount,
+		})
+	}
+
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := s.ProductRepo.ReplaceRelatedProductsTx(ctx, tx, relatedProducts); err != nil {
+			return err
+		}
+		return s.ProductRepo.ReplaceProductSalesTx(ctx, tx, productSales)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Logger.WithFields(logrus.Fields{
+		"lastOrderID": afterOrderID,
+		"products": len(productSales),
+		"relatedProducts": len(relatedProducts),
+	}).Info("Related products computed")
+	return nil
+}
+
+func (s *ProductService) GetRelatedProductIDs(ctx context.Context, productID int64, limit int) ([]int64, error) {
+	relatedProductIDs, err := s.ProductRepo.FindRelatedProductIDs(ctx, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return relatedProductIDs, nil
+}
+
+func (s *ProductService) GetCategoryBestsellerIDs(ctx context.Context, categoryID int64, excludedProductIDs []int64, limit int) ([]int64, error) {
+	productIDs, err := s.ProductRepo.FindCategoryBestsellerIDs(ctx, categoryID, excludedProductIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	return productIDs, nil
+}
+
+// GetLocalizedProductsByIDs is GetProductsByIDs with the text of locale.
+func (s *ProductService) GetLocalizedProductsByIDs(ctx context.Context, productIDs []int64, locale string) ([]models.Product, error) {
+	products, err := s.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productRefs := make([]*models.Product, len(products))
+	for i := range products {
+		productRefs[i] = &products[i]
+	}
+
+	if err = s.translateProducts(ctx, productRefs, locale); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"gorm.io/gorm"
+)
+
+func (s *ProductService) HasCompletedOrderWithProduct(ctx context.Context, userID int64, productID int64) (bool, error) {
+	hasCompletedOrder, err := s.OrderClient.HasCompletedOrderWithProduct(ctx, userID, productID)
+	if err != nil {
+		return false, err
+	}
+	return hasCompletedOrder, nil
+}
+
+func (s *ProductService) GetProductReviewByID(ctx context.Context, productReviewID int64) (*models.ProductReview, error) {
+	productReview, err := s.ProductRepo.FindProductReviewByID(ctx, productReviewID)
+	if err != nil {
+		return nil, err
+	}
+	return productReview, nil
+}
+
+func (s *ProductService) GetProductReviewByProductIDAndUserID(ctx context.Context, productID int64, userID int64) (*models.ProductReview, error) {
+	productReview, err := s.ProductRepo.FindProductReviewByProductIDAndUserID(ctx, productID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return productReview, nil
+}
+
+func (s *ProductService) CreateProductReview(ctx context.Context, productReview *models.ProductReview) (int64, error) {
+	productReviewID, err := s.ProductRepo.InsertProductReview(ctx, productReview)
+	if err != nil {
+		return 0, err
+	}
+	return productReviewID, nil
+}
+
+func (s *ProductService) GetProductReviews(ctx context.Context, param *models.ProductReviewListParameter) (*models.ProductReviewListResponse, error) {
+	productReviews, total, err := s.ProductRepo.FindProductReviews(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	if productReviews == nil {
+		productReviews = []models.ProductReview{}
+	}
+
+	return &models.ProductReviewListResponse{
+		Reviews: productReviews,
+		Page: param.Page,
+		Limit: param.Limit,
+		Total: total,
+	}, nil
+}
+
+func (s *ProductService) UpdateProductReviewStatus(ctx context.Context, productReview *models.ProductReview, status string) error {
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := s.ProductRepo.UpdateProductReviewStatusTx(ctx, tx, productReview.ID, status); err != nil {
+			return err
+		}
+		return s.ProductRepo.RefreshProductRatingTx(ctx, tx, productReview.ProductID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, productReview.ProductID)
+	return nil
+}
+
+// SetProductReviewVote adds or removes the helpful vote of a user, keeping helpful_count in step.
+// Repeating the same call is a no-op.
+func (s *ProductService) SetProductReviewVote(ctx context.Context, productReviewID int64, userID int64, helpful bool) error {
+	return s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var (
+			changed bool
+			delta int
+			err error
+		)
+
+		if helpful {
+			changed, err = s.ProductRepo.InsertProductReviewVoteTx(ctx, tx, &models.ProductReviewVote{
+				ReviewID: productReviewID,
+				UserID: userID,
+			})
+			delta = 1
+		} else {
+			changed, err = s.ProductRepo.DeleteProductReviewVoteTx(ctx, tx, productReviewID, userID)
+			delta = -1
+		}
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			return nil
+		}
+		return s.ProductRepo.UpdateProductReviewHelpfulCountTx(ctx, tx, productReviewID, delta)
+	})
+}
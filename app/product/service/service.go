@@ -2,30 +2,49 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
+	"time"
 
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/grpc"
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/repository"
 	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
 	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type ProductService struct {
 	ProductRepo repository.ProductRepository
+	OrderClient grpc.OrderClient
+	// SourcingRule is models.WarehouseSourcingNearest or models.WarehouseSourcingMostStock
+	SourcingRule string
 }
 
-func NewProductService(productRepo *repository.ProductRepository) *ProductService {
+func NewProductService(productRepo *repository.ProductRepository, orderClient grpc.OrderClient, inventory config.InventoryConfig) *ProductService {
 	return &ProductService{
 		ProductRepo: *productRepo,
+		OrderClient: orderClient,
+		SourcingRule: inventory.SourcingRule,
 	}
 }
 
+// GetProductByID returns the product with its text in DefaultLocale.
 func (s *ProductService) GetProductByID(ctx context.Context, productID int64) (*models.Product, error) {
-	product, err := s.ProductRepo.GetProductByIDFromRedis(ctx, productID)
+	return s.GetLocalizedProductByID(ctx, productID, models.DefaultLocale)
+}
+
+// GetLocalizedProductByID returns the product with its text in locale where it is translated.
+func (s *ProductService) GetLocalizedProductByID(ctx context.Context, productID int64, locale string) (*models.Product, error) {
+	product, err := s.ProductRepo.GetProductByIDFromRedis(ctx, productID, locale)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
 			"ProductID": productID,
+			"locale": locale,
 		}).Errorf("s.ProductRepo.GetProductByIDFromRedis got an error at %v", err)
 	} else if product.ID != 0 {
+		applyEffectivePrice(product, time.Now())
 		return product, nil
 	}
 
@@ -34,9 +53,42 @@ func (s *ProductService) GetProductByID(ctx context.Context, productID int64) (*
 		return nil, err
 	}
 
+	if product.ID == 0 {
+		return product, nil
+	}
+
+	if err = s.attachProductVariants(ctx, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductImages(ctx, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductAttributes(ctx, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductSales(ctx, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductBundles(ctx, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachWarehouseAvailabilities(ctx, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+
+	if err = s.translateProducts(ctx, []*models.Product{product}, locale); err != nil {
+		return nil, err
+	}
+	applyEffectivePrice(product, time.Now())
+
 	ctxConcurrent := context.WithValue(ctx, context.Background(), ctx.Value("request_id"))
 	go func(ctx context.Context, product *models.Product, productID int64) {
-		errConcurrent := s.ProductRepo.SetProductByID(ctx, product, productID)
+		errConcurrent := s.ProductRepo.SetProductByID(ctx, product, productID, locale)
 
 		if errConcurrent != nil {
 			log.Logger.Info("Kena di sini")
@@ -58,7 +110,31 @@ func (s *ProductService) GetProductCategoryByID(ctx context.Context, productCate
 }
 
 func (s *ProductService) CreateNewProduct(ctx context.Context, product *models.Product) (int64, error) {
-	productID, err := s.ProductRepo.InsertProduct(ctx, product)
+	var productID int64
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		productID, err = s.ProductRepo.InsertProductTx(ctx, tx, product)
+		if err != nil {
+			return err
+		}
+
+		if _, err = s.recordListPriceTx(ctx, tx, productID, product.Price, time.Now()); err != nil {
+			return err
+		}
+
+		if err = s.ProductRepo.ReplaceProductAttributeValuesTx(ctx, tx, productID, product.AttributeValues); err != nil {
+			return err
+		}
+
+		if err = s.ProductRepo.ReplaceProductOptionsTx(ctx, tx, productID, product.Options); err != nil {
+			return err
+		}
+
+		if err = s.ProductRepo.ReplaceProductBundleItemsTx(ctx, tx, productID, product.BundleItems); err != nil {
+			return err
+		}
+		return s.recordProductChangeTx(ctx, tx, models.TopicProductCreated, productID, nil)
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -70,35 +146,853 @@ func (s *ProductService) CreateNewProductCategory(ctx context.Context, productCa
 	if err != nil {
 		return 0, err
 	}
+
+	s.invalidateProductCategoryCache(ctx, productCategoryID)
 	return productCategoryID, nil
 }
 
-func (s *ProductService) UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
-	updatedProduct, err := s.ProductRepo.UpdateProduct(ctx, product)
+// UpdateProduct locks the stored row and hands a copy of it to merge, which applies the edit on top.
+// Only the columns that end up different are written, so a field the edit left alone keeps whatever
+// was committed meanwhile, the stock in particular.
+func (s *ProductService) UpdateProduct(ctx context.Context, productID int64, merge func(product *models.Product) error) (*models.Product, error) {
+	existingProduct := &models.Product{}
+	var updatedProduct *models.Product
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := s.productSnapshotTx(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			return gorm.ErrRecordNotFound
+		}
+		existingProduct = before
+
+		product := *before
+		if err = merge(&product); err != nil {
+			return err
+		}
+		product.ID = productID
+
+		// warehouses own the total once they hold the product, it only moves through their levels
+		managed, err := s.ProductRepo.HasWarehouseStock(ctx, product.ID, 0)
+		if err != nil {
+			return err
+		}
+
+		if managed {
+			product.Stock = before.Stock
+		}
+
+		if columns := changedProductColumns(before, &product); len(columns) != 0 {
+			if err = s.ProductRepo.UpdateProductColumnsTx(ctx, tx, product.ID, columns); err != nil {
+				return err
+			}
+		}
+		updatedProduct = &product
+
+		if existingProduct.Price != product.Price {
+			if _, err = s.recordListPriceTx(ctx, tx, product.ID, product.Price, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		if before.Slug != "" && before.Slug != product.Slug {
+			err = s.ProductRepo.UpsertSlugRedirectTx(ctx, tx, &models.SlugRedirect{
+				EntityType: models.SlugEntityProduct,
+				EntityID: product.ID,
+				Slug: before.Slug,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// nil attributes leave the stored values untouched, an empty map removes them
+		if product.Attributes != nil {
+			if err = s.ProductRepo.ReplaceProductAttributeValuesTx(ctx, tx, product.ID, product.AttributeValues); err != nil {
+				return err
+			}
+		}
+
+		// nil options leave the existing axes untouched, an empty list removes them
+		if product.Options != nil {
+			if err = s.ProductRepo.ReplaceProductOptionsTx(ctx, tx, product.ID, product.Options); err != nil {
+				return err
+			}
+		}
+
+		// nil components leave a bundle as it is, a product that stops being a bundle loses them
+		unbundled := before.Type == models.ProductTypeBundle && product.Type != models.ProductTypeBundle
+		if product.BundleItems != nil || unbundled {
+			if err = s.ProductRepo.ReplaceProductBundleItemsTx(ctx, tx, product.ID, product.BundleItems); err != nil {
+				return err
+			}
+		}
+		return s.recordProductChangeTx(ctx, tx, models.TopicProductUpdated, product.ID, before)
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	s.invalidateProductCache(ctx, productID)
+	if existingProduct.Stock != updatedProduct.Stock {
+		s.publishStockChanges(ctx, []stockChange{{
+			Product: *updatedProduct,
+			Before: existingProduct.Stock,
+			After: updatedProduct.Stock,
+		}})
+	}
 	return updatedProduct, nil
 }
 
+// changedProductColumns lists the editable columns of after that differ from before. Status, deletion and
+// the rating columns have their own flows and are never written from an edit.
+func changedProductColumns(before *models.Product, after *models.Product) map[string]interface{} {
+	columns := map[string]interface{}{}
+	if after.SKU != before.SKU {
+		columns["sku"] = after.SKU
+	}
+	if after.Type != before.Type {
+		columns["type"] = after.Type
+	}
+	if after.Name != before.Name {
+		columns["name"] = after.Name
+	}
+	if after.Slug != before.Slug {
+		columns["slug"] = after.Slug
+	}
+	if after.Description != before.Description {
+		columns["description"] = after.Description
+	}
+	if after.Price != before.Price {
+		columns["price"] = after.Price
+	}
+	if after.Stock != before.Stock {
+		columns["stock"] = after.Stock
+	}
+	if after.LowStockThreshold != before.LowStockThreshold {
+		columns["low_stock_threshold"] = after.LowStockThreshold
+	}
+	if after.Category_ID != before.Category_ID {
+		columns["category_id"] = after.Category_ID
+	}
+	return columns
+}
+
+// UpdateProductCategory keeps the slug the category gives up as a redirect to the new one.
 func (s *ProductService) UpdateProductCategory(ctx context.Context, productCategory *models.ProductCategory) (*models.ProductCategory, error) {
-	updatedProductCategory, err := s.ProductRepo.UpdateProductCategory(ctx, productCategory)
+	existingProductCategory, err := s.ProductRepo.FindProductCategoryByID(ctx, productCategory.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedProductCategory *models.ProductCategory
+	err = s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		updatedProductCategory, err = s.ProductRepo.UpdateProductCategoryTx(ctx, tx, productCategory)
+		if err != nil {
+			return err
+		}
+
+		if existingProductCategory.Slug == "" || existingProductCategory.Slug == productCategory.Slug {
+			return nil
+		}
+		return s.ProductRepo.UpsertSlugRedirectTx(ctx, tx, &models.SlugRedirect{
+			EntityType: models.SlugEntityCategory,
+			EntityID: int64(productCategory.ID),
+			Slug: existingProductCategory.Slug,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	s.invalidateProductCategoryCache(ctx, productCategory.ID)
 	return updatedProductCategory, nil
 }
 
+func (s *ProductService) RenameProductCategory(ctx context.Context, productCategoryID int, name string) error {
+	if err := s.ProductRepo.UpdateProductCategoryName(ctx, productCategoryID, name); err != nil {
+		return err
+	}
+
+	s.invalidateProductCategoryCache(ctx, productCategoryID)
+	return nil
+}
+
 func (s *ProductService) DeleteProduct(ctx context.Context, productID int64) error {
-	if err := s.ProductRepo.DeleteProduct(ctx, productID); err != nil {
+	err := s.changeProductTx(ctx, productID, models.TopicProductDeleted, func(tx *gorm.DB) error {
+		return s.ProductRepo.DeleteProductTx(ctx, tx, productID)
+	})
+	if err != nil {
 		return err
 	}
+
+	s.invalidateProductCache(ctx, productID)
+	return nil
+}
+
+func (s *ProductService) UpdateProductStatus(ctx context.Context, productID int64, status string) error {
+	err := s.changeProductTx(ctx, productID, models.TopicProductUpdated, func(tx *gorm.DB) error {
+		return s.ProductRepo.UpdateProductStatusTx(ctx, tx, productID, status)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, productID)
 	return nil
 }
 
-func (s *ProductService) DeleteProductCategory(ctx context.Context, productCategoryID int) error {
-	if err := s.ProductRepo.DeleteProductCategory(ctx, productCategoryID); err != nil {
+// RestoreProduct is published as product.created, consumers that dropped the product on product.deleted get it back.
+func (s *ProductService) RestoreProduct(ctx context.Context, productID int64) error {
+	err := s.changeProductTx(ctx, productID, models.TopicProductCreated, func(tx *gorm.DB) error {
+		return s.ProductRepo.RestoreProductTx(ctx, tx, productID)
+	})
+	if err != nil {
 		return err
 	}
+
+	s.invalidateProductCache(ctx, productID)
 	return nil
-}
\ No newline at end of file
+}
+
+// DeleteProductCategory moves the products and child categories to reassignTo first when it is set,
+// all in one transaction so nothing is left pointing at the deleted category.
+func (s *ProductService) DeleteProductCategory(ctx context.Context, productCategoryID int, reassignTo int) error {
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if reassignTo != 0 {
+			products, err := s.ProductRepo.FindProductsByCategoryIDForUpdateTx(ctx, tx, productCategoryID)
+			if err != nil {
+				return err
+			}
+
+			if err = s.ProductRepo.ReassignProductsCategoryTx(ctx, tx, productCategoryID, reassignTo); err != nil {
+				return err
+			}
+
+			for i := range products {
+				if err = s.recordProductChangeTx(ctx, tx, models.TopicProductUpdated, products[i].ID, &products[i]); err != nil {
+					return err
+				}
+			}
+
+			if err = s.ProductRepo.ReassignChildProductCategoriesTx(ctx, tx, productCategoryID, reassignTo); err != nil {
+				return err
+			}
+		}
+
+		// attribute definitions belong to the category and go with it
+		if err := s.ProductRepo.DeleteProductAttributesByCategoryIDTx(ctx, tx, productCategoryID); err != nil {
+			return err
+		}
+		return s.ProductRepo.DeleteProductCategoryTx(ctx, tx, productCategoryID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCategoryCache(ctx, productCategoryID)
+	return nil
+}
+
+func (s *ProductService) GetProductCategoriesByNames(ctx context.Context, names []string) ([]models.ProductCategory, error) {
+	productCategories, err := s.ProductRepo.FindProductCategoriesByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	return productCategories, nil
+}
+
+// GetAllProductCategories returns every category with its name in DefaultLocale.
+func (s *ProductService) GetAllProductCategories(ctx context.Context) ([]models.ProductCategory, error) {
+	return s.GetLocalizedProductCategories(ctx, models.DefaultLocale)
+}
+
+// GetLocalizedProductCategories returns every category with its name in locale where it is translated.
+func (s *ProductService) GetLocalizedProductCategories(ctx context.Context, locale string) ([]models.ProductCategory, error) {
+	productCategories, err := s.ProductRepo.GetAllProductCategoriesFromRedis(ctx, locale)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"locale": locale,
+		}).Errorf("s.ProductRepo.GetAllProductCategoriesFromRedis got an error at %v", err)
+	} else if productCategories != nil {
+		return productCategories, nil
+	}
+
+	productCategories, err = s.ProductRepo.FindAllProductCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.translateProductCategories(ctx, productCategories, locale); err != nil {
+		return nil, err
+	}
+
+	if err = s.ProductRepo.SetAllProductCategories(ctx, productCategories, locale); err != nil {
+		log.Logger.Errorf("s.ProductRepo.SetAllProductCategories got an error at %v", err)
+	}
+	return productCategories, nil
+}
+
+func (s *ProductService) GetProductBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	product, err := s.ProductRepo.FindProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *ProductService) GetSlugRedirect(ctx context.Context, entityType string, slug string) (*models.SlugRedirect, error) {
+	slugRedirect, err := s.ProductRepo.FindSlugRedirect(ctx, entityType, slug)
+	if err != nil {
+		return nil, err
+	}
+	return slugRedirect, nil
+}
+
+func (s *ProductService) CountPublishedProductSlugs(ctx context.Context) (int64, error) {
+	total, err := s.ProductRepo.CountPublishedProductSlugs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *ProductService) GetPublishedProductSlugs(ctx context.Context, offset int, limit int) ([]models.Product, error) {
+	products, err := s.ProductRepo.FindPublishedProductSlugs(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (s *ProductService) GetProductCategoryBySlug(ctx context.Context, slug string) (*models.ProductCategory, error) {
+	productCategory, err := s.ProductRepo.FindProductCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return productCategory, nil
+}
+
+func (s *ProductService) CountProductsByCategoryID(ctx context.Context, productCategoryID int) (int64, error) {
+	total, err := s.ProductRepo.CountProductsByCategoryID(ctx, productCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *ProductService) CountChildProductCategories(ctx context.Context, productCategoryID int) (int64, error) {
+	total, err := s.ProductRepo.CountChildProductCategories(ctx, productCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *ProductService) GetProductsBySKUs(ctx context.Context, skus []string) ([]models.Product, error) {
+	products, err := s.ProductRepo.FindProductsBySKUs(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// UpsertProductsBySKU saves products and records a list price entry for every new product
+// and every product whose price differs from previousPrices, keyed by SKU. Products whose stock
+// warehouses hold keep their stock, the import only moves the stock of the others.
+func (s *ProductService) UpsertProductsBySKU(ctx context.Context, products []models.Product, previousPrices map[string]float64) error {
+	var changes []stockChange
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		skus := make([]string, len(products))
+		for i, product := range products {
+			skus[i] = product.SKU
+		}
+
+		existingProducts, err := s.ProductRepo.FindProductsBySKUsForUpdateTx(ctx, tx, skus)
+		if err != nil {
+			return err
+		}
+
+		existingBySKU := make(map[string]*models.Product, len(existingProducts))
+		existingProductIDs := make([]int64, len(existingProducts))
+		for i := range existingProducts {
+			existingBySKU[existingProducts[i].SKU] = &existingProducts[i]
+			existingProductIDs[i] = existingProducts[i].ID
+		}
+
+		managed := map[int64]bool{}
+		if len(existingProductIDs) != 0 {
+			managed, err = s.ProductRepo.FindWarehouseStockProductIDs(ctx, existingProductIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		changes = nil
+		for i := range products {
+			existingProduct, ok := existingBySKU[products[i].SKU]
+			if !ok {
+				continue
+			}
+
+			if managed[existingProduct.ID] {
+				products[i].Stock = existingProduct.Stock
+				continue
+			}
+
+			if products[i].Stock != existingProduct.Stock {
+				changes = append(changes, stockChange{
+					Product: *existingProduct,
+					Before: existingProduct.Stock,
+					After: products[i].Stock,
+				})
+			}
+		}
+
+		if err = s.ProductRepo.UpsertProductsBySKUTx(ctx, tx, products); err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			existingProduct, ok := existingBySKU[product.SKU]
+			if !ok {
+				err = s.recordProductChangeTx(ctx, tx, models.TopicProductCreated, product.ID, nil)
+			} else {
+				err = s.recordProductChangeTx(ctx, tx, models.TopicProductUpdated, product.ID, existingProduct)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, product := range products {
+			previousPrice, ok := previousPrices[product.SKU]
+			if ok && previousPrice == product.Price {
+				continue
+			}
+
+			if _, err := s.recordListPriceTx(ctx, tx, product.ID, product.Price, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if _, ok := previousPrices[product.SKU]; ok {
+			s.invalidateProductCache(ctx, product.ID)
+		}
+	}
+
+	s.publishStockChanges(ctx, changes)
+	return nil
+}
+
+func (s *ProductService) StreamProducts(ctx context.Context, batchSize int, fn func(products []models.Product) error) error {
+	if err := s.ProductRepo.FindProductsInBatches(ctx, batchSize, fn); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ProductService) GetProducts(ctx context.Context, param *models.ProductListParameter) (*models.ProductListResponse, error) {
+	products, total, err := s.ProductRepo.FindProducts(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	productRefs := make([]*models.Product, len(products))
+	for i := range products {
+		productRefs[i] = &products[i]
+	}
+
+	if err = s.attachProductVariants(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductImages(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductAttributes(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductSales(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if err = s.attachProductBundles(ctx, productRefs); err != nil {
+		return nil, err
+	}
+
+	if param.Locale != "" {
+		if err = s.translateProducts(ctx, productRefs, param.Locale); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for _, product := range productRefs {
+		applyEffectivePrice(product, now)
+	}
+
+	return &models.ProductListResponse{
+		Products: products,
+		Page: param.Page,
+		Limit: param.Limit,
+		Total: total,
+	}, nil
+}
+
+func (s *ProductService) GetProductVariantByID(ctx context.Context, productVariantID int64) (*models.ProductVariant, error) {
+	productVariant, err := s.ProductRepo.FindProductVariantByID(ctx, productVariantID)
+	if err != nil {
+		return nil, err
+	}
+	return productVariant, nil
+}
+
+func (s *ProductService) CreateNewProductVariant(ctx context.Context, productVariant *models.ProductVariant) (int64, error) {
+	productVariantID, err := s.ProductRepo.InsertProductVariant(ctx, productVariant)
+	if err != nil {
+		return 0, err
+	}
+
+	s.invalidateProductCache(ctx, productVariant.ProductID)
+	return productVariantID, nil
+}
+
+// UpdateProductVariant locks the product and the variant row and hands a copy of the variant to merge, which
+// applies the edit on top. Like UpdateProduct only the columns that end up different are written, so an edit
+// never writes back a stock that orders moved meanwhile.
+func (s *ProductService) UpdateProductVariant(ctx context.Context, productID int64, productVariantID int64, merge func(productVariant *models.ProductVariant) error) (*models.ProductVariant, error) {
+	existingProductVariant := &models.ProductVariant{}
+	var updatedProductVariant *models.ProductVariant
+	var product *models.Product
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// the product is locked first, the way stock events lock it, so the two never wait on each other
+		var err error
+		product, err = s.productSnapshotTx(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+
+		if product == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		productVariants, err := s.ProductRepo.FindProductVariantsForUpdateTx(ctx, tx, []int64{productVariantID})
+		if err != nil {
+			return err
+		}
+
+		if len(productVariants) == 0 || productVariants[0].ProductID != productID {
+			return gorm.ErrRecordNotFound
+		}
+		existingProductVariant = &productVariants[0]
+
+		productVariant := *existingProductVariant
+		if err = merge(&productVariant); err != nil {
+			return err
+		}
+		productVariant.ID = productVariantID
+		productVariant.ProductID = productID
+
+		managed, err := s.ProductRepo.HasWarehouseStock(ctx, productID, productVariantID)
+		if err != nil {
+			return err
+		}
+
+		if managed {
+			productVariant.Stock = existingProductVariant.Stock
+		}
+
+		columns, err := changedProductVariantColumns(existingProductVariant, &productVariant)
+		if err != nil {
+			return err
+		}
+
+		if len(columns) == 0 {
+			updatedProductVariant = &productVariant
+			return nil
+		}
+
+		var beforeVariants []models.ProductVariant
+		priceChanged := !samePriceOverride(existingProductVariant.PriceOverride, productVariant.PriceOverride)
+		if priceChanged {
+			beforeVariants, err = s.ProductRepo.FindProductVariantsByProductIDTx(ctx, tx, productID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = s.ProductRepo.UpdateProductVariantColumnsTx(ctx, tx, productVariantID, columns); err != nil {
+			return err
+		}
+		updatedProductVariant = &productVariant
+
+		if !priceChanged {
+			return nil
+		}
+		return s.recordProductVariantChangeTx(ctx, tx, product, beforeVariants)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateProductCache(ctx, productID)
+	if existingProductVariant.Stock != updatedProductVariant.Stock {
+		s.publishStockChanges(ctx, []stockChange{{
+			Product: *product,
+			VariantID: updatedProductVariant.ID,
+			Before: existingProductVariant.Stock,
+			After: updatedProductVariant.Stock,
+		}})
+	}
+	return updatedProductVariant, nil
+}
+
+// changedProductVariantColumns lists the columns of after that differ from before.
+func changedProductVariantColumns(before *models.ProductVariant, after *models.ProductVariant) (map[string]interface{}, error) {
+	columns := map[string]interface{}{}
+	if after.SKU != before.SKU {
+		columns["sku"] = after.SKU
+	}
+	if !reflect.DeepEqual(after.Options, before.Options) {
+		// a map update skips the serializer of the model, so the options are written as the json it stores
+		options, err := json.Marshal(after.Options)
+		if err != nil {
+			return nil, err
+		}
+		columns["options"] = string(options)
+	}
+	if !samePriceOverride(after.PriceOverride, before.PriceOverride) {
+		columns["price_override"] = after.PriceOverride
+	}
+	if after.Stock != before.Stock {
+		columns["stock"] = after.Stock
+	}
+	return columns, nil
+}
+
+func samePriceOverride(a *float64, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *ProductService) DeleteProductVariant(ctx context.Context, productVariant *models.ProductVariant) error {
+	if err := s.ProductRepo.DeleteProductVariant(ctx, productVariant.ID); err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, productVariant.ProductID)
+	return nil
+}
+
+func (s *ProductService) attachProductVariants(ctx context.Context, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	productOptions, err := s.ProductRepo.FindProductOptionsByProductIDs(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	productVariants, err := s.ProductRepo.FindProductVariantsByProductIDs(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	optionsByProductID := map[int64][]models.ProductOption{}
+	for _, productOption := range productOptions {
+		optionsByProductID[productOption.ProductID] = append(optionsByProductID[productOption.ProductID], productOption)
+	}
+
+	variantsByProductID := map[int64][]models.ProductVariant{}
+	for _, productVariant := range productVariants {
+		variantsByProductID[productVariant.ProductID] = append(variantsByProductID[productVariant.ProductID], productVariant)
+	}
+
+	for _, product := range products {
+		product.Options = optionsByProductID[product.ID]
+		product.Variants = variantsByProductID[product.ID]
+	}
+	return nil
+}
+
+func (s *ProductService) GetProductImages(ctx context.Context, productID int64) ([]models.ProductImage, error) {
+	productImages, err := s.ProductRepo.FindProductImagesByProductIDs(ctx, []int64{productID})
+	if err != nil {
+		return nil, err
+	}
+	return productImages, nil
+}
+
+func (s *ProductService) GetProductImageByID(ctx context.Context, productImageID int64) (*models.ProductImage, error) {
+	productImage, err := s.ProductRepo.FindProductImageByID(ctx, productImageID)
+	if err != nil {
+		return nil, err
+	}
+	return productImage, nil
+}
+
+// SaveProductImage uploads the original and its thumbnails before inserting the row,
+// and removes whatever was already uploaded when any step fails.
+func (s *ProductService) SaveProductImage(ctx context.Context, productImage *models.ProductImage, original []byte, thumbnails map[string][]byte) (*models.ProductImage, error) {
+	var uploadedKeys []string
+	cleanup := func() {
+		for _, key := range uploadedKeys {
+			if err := s.ProductRepo.DeleteObject(ctx, key); err != nil {
+				log.Logger.WithFields(logrus.Fields{
+					"key": key,
+				}).Errorf("s.ProductRepo.DeleteObject got an error at %v", err)
+			}
+		}
+	}
+
+	if err := s.ProductRepo.PutObject(ctx, productImage.ObjectKey, original, productImage.ContentType); err != nil {
+		return nil, err
+	}
+	uploadedKeys = append(uploadedKeys, productImage.ObjectKey)
+
+	for label, thumbnail := range thumbnails {
+		key := productImage.ThumbnailKeys[label]
+		if err := s.ProductRepo.PutObject(ctx, key, thumbnail, "image/jpeg"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		uploadedKeys = append(uploadedKeys, key)
+	}
+
+	// the product row lock makes concurrent uploads of one product take the next position in turn
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if _, err := s.ProductRepo.FindProductsForUpdateTx(ctx, tx, []int64{productImage.ProductID}); err != nil {
+			return err
+		}
+
+		position, err := s.ProductRepo.NextProductImagePositionTx(ctx, tx, productImage.ProductID)
+		if err != nil {
+			return err
+		}
+		productImage.Position = position
+
+		_, err = s.ProductRepo.InsertProductImageTx(ctx, tx, productImage)
+		return err
+	})
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	s.fillProductImageURLs(productImage)
+	s.invalidateProductCache(ctx, productImage.ProductID)
+	return productImage, nil
+}
+
+func (s *ProductService) ReorderProductImages(ctx context.Context, productID int64, productImageIDs []int64) error {
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		for position, productImageID := range productImageIDs {
+			if err := s.ProductRepo.UpdateProductImagePositionTx(ctx, tx, productImageID, position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, productID)
+	return nil
+}
+
+func (s *ProductService) DeleteProductImage(ctx context.Context, productImage *models.ProductImage) error {
+	if err := s.ProductRepo.DeleteProductImage(ctx, productImage.ID); err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, productImage.ProductID)
+
+	keys := []string{productImage.ObjectKey}
+	for _, key := range productImage.ThumbnailKeys {
+		keys = append(keys, key)
+	}
+
+	// the row is already gone, so a leftover object is only wasted space
+	for _, key := range keys {
+		if err := s.ProductRepo.DeleteObject(ctx, key); err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"key": key,
+			}).Errorf("s.ProductRepo.DeleteObject got an error at %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *ProductService) attachProductImages(ctx context.Context, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	productImages, err := s.ProductRepo.FindProductImagesByProductIDs(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	imagesByProductID := map[int64][]models.ProductImage{}
+	for _, productImage := range productImages {
+		s.fillProductImageURLs(&productImage)
+		imagesByProductID[productImage.ProductID] = append(imagesByProductID[productImage.ProductID], productImage)
+	}
+
+	for _, product := range products {
+		product.Images = imagesByProductID[product.ID]
+	}
+	return nil
+}
+
+func (s *ProductService) fillProductImageURLs(productImage *models.ProductImage) {
+	productImage.URL = s.ProductRepo.ObjectURL(productImage.ObjectKey)
+	productImage.Thumbnails = make(map[string]string, len(productImage.ThumbnailKeys))
+	for label, key := range productImage.ThumbnailKeys {
+		productImage.Thumbnails[label] = s.ProductRepo.ObjectURL(key)
+	}
+}
+
+func (s *ProductService) invalidateProductCache(ctx context.Context, productID int64) {
+	if err := s.ProductRepo.DeleteProductCacheByID(ctx, productID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+		}).Errorf("s.ProductRepo.DeleteProductCacheByID got an error at %v", err)
+	}
+	s.invalidateBundleCaches(ctx, []int64{productID})
+}
+
+func (s *ProductService) invalidateProductCategoryCache(ctx context.Context, productCategoryID int) {
+	if err := s.ProductRepo.DeleteProductCategoryCache(ctx, productCategoryID); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productCategoryID": productCategoryID,
+		}).Errorf("s.ProductRepo.DeleteProductCategoryCache got an error at %v", err)
+	}
+}
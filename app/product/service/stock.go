@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// stockChange is one product or variant whose stock moved from Before to After.
+type stockChange struct {
+	Product models.Product
+	VariantID int64
+	Before int
+	After int
+}
+
+// stockItemKey identifies the stock of a product, or of one of its variants when VariantID is set.
+type stockItemKey struct {
+	ProductID int64
+	VariantID int64
+}
+
+// ApplyStockEvent adds delta times the quantity of every item, a negative delta takes stock out. Flash sale
+// items were taken out of the sale counter at checkout, a rollback hands them back to it. It reports false when the order event was applied before and was skipped.
+//
+// A rollback only hands back stock the update of the order took. When the update never ran, parked on the
+// dead letter topic for instance, the rollback is recorded without touching the stock, and an update that
+// arrives after the rollback of its order is recorded the same way.
+func (s *ProductService) ApplyStockEvent(ctx context.Context, topic string, event *models.ProductStockUpdateEvent, delta int) (bool, error) {
+	var changes []stockChange
+	applied := false
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// the update and the rollback of an order come from different topics, the lock puts them in order
+		err := s.ProductRepo.LockProductStockOrderTx(ctx, tx, event.OrderID)
+		if err != nil {
+			return err
+		}
+
+		applied, err = s.ProductRepo.InsertProductStockEventTx(ctx, tx, &models.ProductStockEvent{
+			OrderID: event.OrderID,
+			Topic: topic,
+		})
+		if err != nil || !applied {
+			return err
+		}
+
+		if delta > 0 {
+			taken, err := s.ProductRepo.HasProductStockEventTx(ctx, tx, event.OrderID, models.TopicStockUpdate)
+			if err != nil {
+				return err
+			}
+
+			if !taken {
+				log.Logger.WithFields(logrus.Fields{
+					"topic": topic,
+					"orderID": event.OrderID,
+				}).Warn("⚠️ Stock of the order was never taken, recording rollback without changing stock")
+				return nil
+			}
+		} else {
+			rolledBack, err := s.ProductRepo.HasProductStockEventTx(ctx, tx, event.OrderID, models.TopicStockRollback)
+			if err != nil {
+				return err
+			}
+
+			if rolledBack {
+				log.Logger.WithFields(logrus.Fields{
+					"topic": topic,
+					"orderID": event.OrderID,
+				}).Warn("⚠️ Order was rolled back already, recording update without changing stock")
+				return nil
+			}
+		}
+
+		changes, err = s.applyStockItemsTx(ctx, tx, event, delta)
+		if err != nil {
+			return err
+		}
+		return s.allocateWarehouseStockTx(ctx, tx, event, delta)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, change := range changes {
+		s.invalidateProductCache(ctx, change.Product.ID)
+	}
+
+	s.publishStockChanges(ctx, changes)
+
+	if applied && delta > 0 {
+		s.releaseFlashSaleItems(ctx, event)
+	}
+	return applied, nil
+}
+
+func (s *ProductService) applyStockItemsTx(ctx context.Context, tx *gorm.DB, event *models.ProductStockUpdateEvent, delta int) ([]stockChange, error) {
+	var productIDs, productVariantIDs []int64
+	for _, item := range event.Products {
+		if item.FlashSaleID != 0 {
+			continue
+		}
+
+		productIDs = append(productIDs, item.ProductID)
+		if item.VariantID != 0 {
+			productVariantIDs = append(productVariantIDs, item.VariantID)
+		}
+	}
+
+	products, err := s.ProductRepo.FindProductsForUpdateTx(ctx, tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productsByID := make(map[int64]*models.Product, len(products))
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+
+	variantsByID := map[int64]*models.ProductVariant{}
+	if len(productVariantIDs) != 0 {
+		productVariants, err := s.ProductRepo.FindProductVariantsForUpdateTx(ctx, tx, productVariantIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range productVariants {
+			variantsByID[productVariants[i].ID] = &productVariants[i]
+		}
+	}
+
+	// units the order never got back when it was taken, a rollback must not hand them out a second time
+	shortfalls := map[stockItemKey]int{}
+	if delta > 0 {
+		productStockShortfalls, err := s.ProductRepo.FindProductStockShortfallsTx(ctx, tx, event.OrderID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, productStockShortfall := range productStockShortfalls {
+			shortfalls[stockItemKey{productStockShortfall.ProductID, productStockShortfall.VariantID}] += productStockShortfall.Shortfall
+		}
+	}
+
+	var changes []stockChange
+	var productStockShortfalls []models.ProductStockShortfall
+	for _, item := range event.Products {
+		if item.FlashSaleID != 0 {
+			continue
+		}
+
+		product, ok := productsByID[item.ProductID]
+		if !ok {
+			log.Logger.WithFields(logrus.Fields{
+				"orderID": event.OrderID,
+				"productID": item.ProductID,
+			}).Warn("⚠️ Skipping stock change of unknown product")
+			continue
+		}
+
+		if product.Type == models.ProductTypeBundle {
+			// the order service sends the components of a bundle, the bundle itself holds no stock
+			log.Logger.WithFields(logrus.Fields{
+				"orderID": event.OrderID,
+				"productID": item.ProductID,
+			}).Warn("⚠️ Skipping stock change of bundle")
+			continue
+		}
+
+		stock := &product.Stock
+		if item.VariantID != 0 {
+			productVariant, ok := variantsByID[item.VariantID]
+			if !ok || productVariant.ProductID != item.ProductID {
+				log.Logger.WithFields(logrus.Fields{
+					"orderID": event.OrderID,
+					"productID": item.ProductID,
+					"variantID": item.VariantID,
+				}).Warn("⚠️ Skipping stock change of unknown product variant")
+				continue
+			}
+			stock = &productVariant.Stock
+		}
+
+		key := stockItemKey{item.ProductID, item.VariantID}
+		qty := item.Qty
+		if skipped := min(qty, shortfalls[key]); skipped > 0 {
+			shortfalls[key] -= skipped
+			qty -= skipped
+		}
+
+		before := *stock
+		after := before + delta*qty
+		if after < 0 {
+			// the order service checks availability first, so this only happens after a manual stock edit raced an order
+			log.Logger.WithFields(logrus.Fields{
+				"orderID": event.OrderID,
+				"productID": item.ProductID,
+				"variantID": item.VariantID,
+			}).Errorf("❌ Order is oversold by %d, only %d of %d were in stock", -after, before, qty)
+			productStockShortfalls = append(productStockShortfalls, models.ProductStockShortfall{
+				OrderID: event.OrderID,
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Qty: qty,
+				Shortfall: -after,
+			})
+			after = 0
+		}
+		*stock = after
+
+		if item.VariantID != 0 {
+			err = s.ProductRepo.UpdateProductVariantStockTx(ctx, tx, item.VariantID, after)
+		} else {
+			err = s.ProductRepo.UpdateProductStockTx(ctx, tx, item.ProductID, after)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, stockChange{
+			Product: *product,
+			VariantID: item.VariantID,
+			Before: before,
+			After: after,
+		})
+	}
+
+	if err = s.ProductRepo.InsertProductStockShortfallsTx(ctx, tx, productStockShortfalls); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (s *ProductService) releaseFlashSaleItems(ctx context.Context, event *models.ProductStockUpdateEvent) {
+	var reservations []models.FlashSaleReservation
+	for _, item := range event.Products {
+		if item.FlashSaleID == 0 {
+			continue
+		}
+
+		reservations = append(reservations, models.FlashSaleReservation{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity: item.Qty,
+			FlashSaleID: item.FlashSaleID,
+		})
+	}
+
+	if len(reservations) == 0 {
+		return
+	}
+
+	if err := s.ReleaseFlashSaleStock(ctx, event.UserID, reservations); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"orderID": event.OrderID,
+		}).Errorf("s.ReleaseFlashSaleStock got an error at %v", err)
+	}
+}
+
+// publishStockChanges runs after the stock is committed, so a failed alert is logged instead of undoing the change.
+func (s *ProductService) publishStockChanges(ctx context.Context, changes []stockChange) {
+	now := time.Now()
+	notified := map[int64]bool{}
+	for _, change := range changes {
+		event := &models.ProductStockAlertEvent{
+			ProductID: change.Product.ID,
+			VariantID: change.VariantID,
+			SKU: change.Product.SKU,
+			Name: change.Product.Name,
+			Stock: change.After,
+			EventTime: now,
+		}
+
+		threshold := change.Product.LowStockThreshold
+		if threshold > 0 && change.Before >= threshold && change.After < threshold {
+			event.Threshold = threshold
+			s.publishStockAlert(ctx, models.TopicProductLowStock, event)
+		}
+
+		if change.Before <= 0 && change.After > 0 {
+			event.Threshold = 0
+			s.publishStockAlert(ctx, models.TopicProductBackInStock, event)
+
+			if !notified[change.Product.ID] {
+				notified[change.Product.ID] = true
+				s.notifyStockSubscribers(ctx, &change.Product)
+			}
+		}
+	}
+}
+
+func (s *ProductService) publishStockAlert(ctx context.Context, topic string, event *models.ProductStockAlertEvent) {
+	if err := s.ProductRepo.PublishProductStockAlert(ctx, topic, event); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"topic": topic,
+			"productID": event.ProductID,
+			"variantID": event.VariantID,
+		}).Errorf("s.ProductRepo.PublishProductStockAlert got an error at %v", err)
+	}
+}
+
+// notifyStockSubscribers marks only the delivered subscriptions, the rest are retried on the next restock.
+func (s *ProductService) notifyStockSubscribers(ctx context.Context, product *models.Product) {
+	productStockSubscriptions, err := s.ProductRepo.FindPendingProductStockSubscriptions(ctx, product.ID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": product.ID,
+		}).Errorf("s.ProductRepo.FindPendingProductStockSubscriptions got an error at %v", err)
+		return
+	}
+
+	var notifiedIDs []int64
+	for i := range productStockSubscriptions {
+		if err := s.ProductRepo.NotifyBackInStock(ctx, &productStockSubscriptions[i], product); err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"productID": product.ID,
+				"userID": productStockSubscriptions[i].UserID,
+			}).Errorf("s.ProductRepo.NotifyBackInStock got an error at %v", err)
+			continue
+		}
+		notifiedIDs = append(notifiedIDs, productStockSubscriptions[i].ID)
+	}
+
+	if len(notifiedIDs) == 0 {
+		return
+	}
+
+	if err := s.ProductRepo.MarkProductStockSubscriptionsNotified(ctx, notifiedIDs, time.Now()); err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": product.ID,
+		}).Errorf("s.ProductRepo.MarkProductStockSubscriptionsNotified got an error at %v", err)
+	}
+}
+
+func (s *ProductService) SubscribeProductStock(ctx context.Context, productID int64, userID int64) error {
+	err := s.ProductRepo.UpsertProductStockSubscription(ctx, &models.ProductStockSubscription{
+		ProductID: productID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ProductService) UnsubscribeProductStock(ctx context.Context, productID int64, userID int64) (bool, error) {
+	deleted, err := s.ProductRepo.DeleteProductStockSubscription(ctx, productID, userID)
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
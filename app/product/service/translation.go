@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+// GetLocalizedProductCategoryByID returns the category with its name in locale where it is translated.
+func (s *ProductService) GetLocalizedProductCategoryByID(ctx context.Context, productCategoryID int, locale string) (*models.ProductCategory, error) {
+	productCategory, err := s.ProductRepo.FindProductCategoryByID(ctx, productCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if productCategory.ID == 0 {
+		return productCategory, nil
+	}
+
+	productCategories := []models.ProductCategory{*productCategory}
+	if err = s.translateProductCategories(ctx, productCategories, locale); err != nil {
+		return nil, err
+	}
+	return &productCategories[0], nil
+}
+
+func (s *ProductService) SaveProductTranslation(ctx context.Context, productTranslation *models.ProductTranslation) error {
+	if err := s.ProductRepo.UpsertProductTranslation(ctx, productTranslation); err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, productTranslation.ProductID)
+	return nil
+}
+
+func (s *ProductService) DeleteProductTranslation(ctx context.Context, productID int64, locale string) (bool, error) {
+	deleted, err := s.ProductRepo.DeleteProductTranslation(ctx, productID, locale)
+	if err != nil {
+		return false, err
+	}
+
+	s.invalidateProductCache(ctx, productID)
+	return deleted, nil
+}
+
+func (s *ProductService) SaveProductCategoryTranslation(ctx context.Context, productCategoryTranslation *models.ProductCategoryTranslation) error {
+	if err := s.ProductRepo.UpsertProductCategoryTranslation(ctx, productCategoryTranslation); err != nil {
+		return err
+	}
+
+	s.invalidateProductCategoryCache(ctx, productCategoryTranslation.CategoryID)
+	return nil
+}
+
+func (s *ProductService) DeleteProductCategoryTranslation(ctx context.Context, productCategoryID int, locale string) (bool, error) {
+	deleted, err := s.ProductRepo.DeleteProductCategoryTranslation(ctx, productCategoryID, locale)
+	if err != nil {
+		return false, err
+	}
+
+	s.invalidateProductCategoryCache(ctx, productCategoryID)
+	return deleted, nil
+}
+
+// translateProducts swaps in the name and description of locale. A product without a translation keeps
+// its DefaultLocale text, and Locale tells which of the two it got.
+func (s *ProductService) translateProducts(ctx context.Context, products []*models.Product, locale string) error {
+	for _, product := range products {
+		product.Locale = models.DefaultLocale
+	}
+
+	if locale == models.DefaultLocale || len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	productTranslations, err := s.ProductRepo.FindProductTranslations(ctx, productIDs, locale)
+	if err != nil {
+		return err
+	}
+
+	translationByProductID := make(map[int64]models.ProductTranslation, len(productTranslations))
+	for _, productTranslation := range productTranslations {
+		translationByProductID[productTranslation.ProductID] = productTranslation
+	}
+
+	for _, product := range products {
+		productTranslation, ok := translationByProductID[product.ID]
+		if !ok {
+			continue
+		}
+
+		product.Name = productTranslation.Name
+		if productTranslation.Description != "" {
+			product.Description = productTranslation.Description
+		}
+		product.Locale = locale
+	}
+	return nil
+}
+
+// translateProductCategories works like translateProducts for category names.
+func (s *ProductService) translateProductCategories(ctx context.Context, productCategories []models.ProductCategory, locale string) error {
+	for i := range productCategories {
+		productCategories[i].Locale = models.DefaultLocale
+	}
+
+	if locale == models.DefaultLocale || len(productCategories) == 0 {
+		return nil
+	}
+
+	productCategoryTranslations, err := s.ProductRepo.FindProductCategoryTranslations(ctx, locale)
+	if err != nil {
+		return err
+	}
+
+	nameByCategoryID := make(map[int]string, len(productCategoryTranslations))
+	for _, productCategoryTranslation := range productCategoryTranslations {
+		nameByCategoryID[productCategoryTranslation.CategoryID] = productCategoryTranslation.Name
+	}
+
+	for i := range productCategories {
+		name, ok := nameByCategoryID[productCategories[i].ID]
+		if !ok {
+			continue
+		}
+
+		productCategories[i].Name = name
+		productCategories[i].Locale = locale
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func (s *ProductService) GetWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	warehouses, err := s.ProductRepo.FindWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (s *ProductService) GetWarehouseByID(ctx context.Context, warehouseID int64) (*models.Warehouse, error) {
+	warehouse, err := s.ProductRepo.FindWarehouseByID(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (s *ProductService) GetWarehouseByCode(ctx context.Context, code string) (*models.Warehouse, error) {
+	warehouse, err := s.ProductRepo.FindWarehouseByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (s *ProductService) CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) (int64, error) {
+	warehouseID, err := s.ProductRepo.InsertWarehouse(ctx, warehouse)
+	if err != nil {
+		return 0, err
+	}
+	return warehouseID, nil
+}
+
+func (s *ProductService) UpdateWarehouse(ctx context.Context, warehouse *models.Warehouse) (*models.Warehouse, error) {
+	updatedWarehouse, err := s.ProductRepo.UpdateWarehouse(ctx, warehouse)
+	if err != nil {
+		return nil, err
+	}
+	return updatedWarehouse, nil
+}
+
+func (s *ProductService) HasWarehouseStock(ctx context.Context, productID int64, productVariantID int64) (bool, error) {
+	managed, err := s.ProductRepo.HasWarehouseStock(ctx, productID, productVariantID)
+	if err != nil {
+		return false, err
+	}
+	return managed, nil
+}
+
+// SetWarehouseStock sets what a warehouse holds of a product or variant and moves the total by the difference.
+// The first warehouse level of an item replaces its total, which was kept without warehouses until then.
+func (s *ProductService) SetWarehouseStock(ctx context.Context, warehouseID int64, product *models.Product, productVariantID int64, stock int) error {
+	var change stockChange
+	err := s.ProductRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+		lockedProduct, err := s.productSnapshotTx(ctx, tx, product.ID)
+		if err != nil {
+			return err
+		}
+
+		if lockedProduct == nil {
+			return nil
+		}
+
+		before := lockedProduct.Stock
+		if productVariantID != 0 {
+			productVariants, err := s.ProductRepo.FindProductVariantsForUpdateTx(ctx, tx, []int64{productVariantID})
+			if err != nil {
+				return err
+			}
+
+			if len(productVariants) == 0 {
+				return nil
+			}
+			before = productVariants[0].Stock
+		}
+
+		warehouseStocks, err := s.ProductRepo.FindWarehouseStocksForUpdateTx(ctx, tx, product.ID, productVariantID)
+		if err != nil {
+			return err
+		}
+
+		after := stock
+		if len(warehouseStocks) != 0 {
+			after = before + stock
+			for _, warehouseStock := range warehouseStocks {
+				if warehouseStock.WarehouseID == warehouseID {
+					after -= warehouseStock.Stock
+				}
+			}
+		}
+
+		if after < 0 {
+			after = 0
+		}
+
+		err = s.ProductRepo.UpsertWarehouseStockTx(ctx, tx, &models.WarehouseStock{
+			WarehouseID: warehouseID,
+			ProductID: product.ID,
+			VariantID: productVariantID,
+			Stock: stock,
+		})
+		if err != nil {
+			return err
+		}
+
+		if productVariantID != 0 {
+			err = s.ProductRepo.UpdateProductVariantStockTx(ctx, tx, productVariantID, after)
+		} else {
+			err = s.ProductRepo.UpdateProductStockTx(ctx, tx, product.ID, after)
+		}
+		if err != nil {
+			return err
+		}
+
+		change = stockChange{
+			Product: *lockedProduct,
+			VariantID: productVariantID,
+			Before: before,
+			After: after,
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx, product.ID)
+	if change.Before != change.After {
+		s.publishStockChanges(ctx, []stockChange{change})
+	}
+	return nil
+}
+
+// attachWarehouseAvailabilities breaks the stock of products down by warehouse.
+func (s *ProductService) attachWarehouseAvailabilities(ctx context.Context, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	warehouseAvailabilities, err := s.ProductRepo.FindWarehouseAvailabilities(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		product.Warehouses = warehouseAvailabilities[product.ID]
+	}
+	return nil
+}
+
+// allocateWarehouseStockTx takes the items of a stock.update out of warehouses picked by the sourcing rule
+// and records where each came from, a stock.rollback returns them there. Items without warehouse stock are
+// left to the total alone. Flash sale items are allocated too: the sale took them out of the total when it
+// started, not out of a warehouse.
+func (s *ProductService) allocateWarehouseStockTx(ctx context.Context, tx *gorm.DB, event *models.ProductStockUpdateEvent, delta int) error {
+	if delta > 0 {
+		return s.returnWarehouseStockTx(ctx, tx, event)
+	}
+
+	var warehousesByID map[int64]models.Warehouse
+	var productStockAllocations []models.ProductStockAllocation
+	for _, item := range event.Products {
+		warehouseStocks, err := s.ProductRepo.FindWarehouseStocksForUpdateTx(ctx, tx, item.ProductID, item.VariantID)
+		if err != nil {
+			return err
+		}
+
+		if len(warehouseStocks) == 0 {
+			continue
+		}
+
+		if warehousesByID == nil {
+			warehouses, err := s.ProductRepo.FindWarehouses(ctx)
+			if err != nil {
+				return err
+			}
+
+			warehousesByID = make(map[int64]models.Warehouse, len(warehouses))
+			for _, warehouse := range warehouses {
+				warehousesByID[warehouse.ID] = warehouse
+			}
+		}
+
+		s.rankWarehouseStocks(warehouseStocks, warehousesByID, event.ShippingAddress)
+		for _, allocation := range sourceWarehouseStock(warehouseStocks, item.Qty) {
+			if err = s.ProductRepo.AddWarehouseStockTx(ctx, tx, allocation.WarehouseID, item.ProductID, item.VariantID, -allocation.Qty); err != nil {
+				return err
+			}
+
+			allocation.OrderID = event.OrderID
+			allocation.ProductID = item.ProductID
+			allocation.VariantID = item.VariantID
+			productStockAllocations = append(productStockAllocations, allocation)
+			item.Qty -= allocation.Qty
+		}
+
+		if item.Qty > 0 {
+			log.Logger.WithFields(logrus.Fields{
+				"orderID": event.OrderID,
+				"productID": item.ProductID,
+				"variantID": item.VariantID,
+			}).Warnf("⚠️ Warehouses are %d short, the rest comes out of the total only", item.Qty)
+		}
+	}
+	return s.ProductRepo.InsertProductStockAllocationsTx(ctx, tx, productStockAllocations)
+}
+
+// returnWarehouseStockTx puts every item back into the warehouses its order took it from.
+func (s *ProductService) returnWarehouseStockTx(ctx context.Context, tx *gorm.DB, event *models.ProductStockUpdateEvent) error {
+	productStockAllocations, err := s.ProductRepo.FindProductStockAllocationsTx(ctx, tx, event.OrderID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range event.Products {
+		for i := range productStockAllocations {
+			allocation := &productStockAllocations[i]
+			if item.Qty == 0 {
+				break
+			}
+
+			if allocation.Qty == 0 || allocation.ProductID != item.ProductID || allocation.VariantID != item.VariantID {
+				continue
+			}
+
+			qty := allocation.Qty
+			if qty > item.Qty {
+				qty = item.Qty
+			}
+
+			if err = s.ProductRepo.AddWarehouseStockTx(ctx, tx, allocation.WarehouseID, item.ProductID, item.VariantID, qty); err != nil {
+				return err
+			}
+			allocation.Qty -= qty
+			item.Qty -= qty
+		}
+	}
+	return nil
+}
+
+// rankWarehouseStocks orders the candidates by the sourcing rule. Nearest puts the warehouses serving a region
+// named in the shipping address first, most stock puts the fullest first, and priority breaks ties.
+func (s *ProductService) rankWarehouseStocks(warehouseStocks []models.WarehouseStock, warehousesByID map[int64]models.Warehouse, shippingAddress string) {
+	shippingAddress = strings.ToLower(shippingAddress)
+	servesAddress := func(warehouseStock models.WarehouseStock) bool {
+		for _, region := range warehousesByID[warehouseStock.WarehouseID].Regions {
+			if region = strings.ToLower(strings.TrimSpace(region)); region != "" && strings.Contains(shippingAddress, region) {
+				return true
+			}
+		}
+		return false
+	}
+
+	sort.SliceStable(warehouseStocks, func(i, j int) bool {
+		a, b := warehouseStocks[i], warehouseStocks[j]
+		if s.SourcingRule == models.WarehouseSourcingMostStock {
+			if a.Stock != b.Stock {
+				return a.Stock > b.Stock
+			}
+		} else if servesA, servesB := servesAddress(a), servesAddress(b); servesA != servesB {
+			return servesA
+		}
+
+		priorityA, priorityB := warehousesByID[a.WarehouseID].Priority, warehousesByID[b.WarehouseID].Priority
+		if priorityA != priorityB {
+			return priorityA < priorityB
+		}
+		return a.WarehouseID < b.WarehouseID
+	})
+}
+
+// sourceWarehouseStock ships the whole qty from the best ranked warehouse that holds it, and only splits it
+// over the ranked warehouses when none does.
+func sourceWarehouseStock(rankedWarehouseStocks []models.WarehouseStock, qty int) []models.ProductStockAllocation {
+	for _, warehouseStock := range rankedWarehouseStocks {
+		if warehouseStock.Stock >= qty {
+			return []models.ProductStockAllocation{{
+				WarehouseID: warehouseStock.WarehouseID,
+				Qty: qty,
+			}}
+		}
+	}
+
+	var productStockAllocations []models.ProductStockAllocation
+	for _, warehouseStock := range rankedWarehouseStocks {
+		if qty == 0 {
+			break
+		}
+
+		if warehouseStock.Stock <= 0 {
+			continue
+		}
+
+		taken := warehouseStock.Stock
+		if taken > qty {
+			taken = qty
+		}
+
+		productStockAllocations = append(productStockAllocations, models.ProductStockAllocation{
+			WarehouseID: warehouseStock.WarehouseID,
+			Qty: taken,
+		})
+		qty -= taken
+	}
+	return productStockAllocations
+}
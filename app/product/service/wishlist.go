@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+// GetWishlistItems fills every item with its product from the cache and leaves out products that can no
+// longer be bought, they come back once the product is published again.
+func (s *ProductService) GetWishlistItems(ctx context.Context, userID int64, locale string) ([]models.WishlistItem, error) {
+	wishlistItems, err := s.ProductRepo.FindWishlistItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wishlistItems) == 0 {
+		return []models.WishlistItem{}, nil
+	}
+
+	var productIDs []int64
+	seen := map[int64]bool{}
+	for _, wishlistItem := range wishlistItems {
+		if !seen[wishlistItem.ProductID] {
+			seen[wishlistItem.ProductID] = true
+			productIDs = append(productIDs, wishlistItem.ProductID)
+		}
+	}
+
+	products, err := s.GetLocalizedProductsByIDs(ctx, productIDs, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	productByID := make(map[int64]*models.Product, len(products))
+	for i := range products {
+		productByID[products[i].ID] = &products[i]
+	}
+
+	visibleWishlistItems := make([]models.WishlistItem, 0, len(wishlistItems))
+	for _, wishlistItem := range wishlistItems {
+		product, ok := productByID[wishlistItem.ProductID]
+		if !ok || !product.IsPurchasable() {
+			continue
+		}
+
+		wishlistItem.Product = product
+		visibleWishlistItems = append(visibleWishlistItems, wishlistItem)
+	}
+	return visibleWishlistItems, nil
+}
+
+func (s *ProductService) CountWishlistItems(ctx context.Context, userID int64) (int64, error) {
+	total, err := s.ProductRepo.CountWishlistItems(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *ProductService) GetWishlistItemByID(ctx context.Context, wishlistItemID int64, userID int64) (*models.WishlistItem, error) {
+	wishlistItem, err := s.ProductRepo.FindWishlistItemByID(ctx, wishlistItemID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return wishlistItem, nil
+}
+
+func (s *ProductService) CreateWishlistItem(ctx context.Context, wishlistItem *models.WishlistItem) (int64, error) {
+	wishlistItemID, err := s.ProductRepo.InsertWishlistItem(ctx, wishlistItem)
+	if err != nil {
+		return 0, err
+	}
+	return wishlistItemID, nil
+}
+
+func (s *ProductService) DeleteWishlistItem(ctx context.Context, wishlistItemID int64, userID int64) (bool, error) {
+	deleted, err := s.ProductRepo.DeleteWishlistItem(ctx, wishlistItemID, userID)
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+func (s *ProductService) RecordProductView(ctx context.Context, userID int64, productID int64) error {
+	return s.ProductRepo.AddRecentlyViewedProduct(ctx, userID, productID, time.Now())
+}
+
+// GetRecentlyViewedProducts returns the latest view first. Products that can no longer be bought are left
+// out, IDs that match no product at all are dropped from the list.
+func (s *ProductService) GetRecentlyViewedProducts(ctx context.Context, userID int64, locale string) ([]models.Product, error) {
+	productIDs, err := s.ProductRepo.GetRecentlyViewedProductIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(productIDs) == 0 {
+		return []models.Product{}, nil
+	}
+
+	products, err := s.GetLocalizedProductsByIDs(ctx, productIDs, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int64]bool, len(products))
+	visibleProducts := make([]models.Product, 0, len(products))
+	for _, product := range products {
+		found[product.ID] = true
+		if product.IsPurchasable() {
+			visibleProducts = append(visibleProducts, product)
+		}
+	}
+
+	var goneProductIDs []int64
+	for _, productID := range productIDs {
+		if !found[productID] {
+			goneProductIDs = append(goneProductIDs, productID)
+		}
+	}
+
+	if len(goneProductIDs) != 0 {
+		if err = s.ProductRepo.RemoveRecentlyViewedProducts(ctx, userID, goneProductIDs); err != nil {
+			return nil, err
+		}
+	}
+	return visibleProducts, nil
+}
@@ -0,0 +1,366 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+var (
+	ErrProductAttributeNotFound = errors.New("product attribute not found")
+	ErrInvalidProductAttribute = errors.New("invalid product attribute")
+	ErrInvalidProductFilter = errors.New("invalid product filter")
+)
+
+var (
+	productAttributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+	productAttributeTypes = map[string]bool{
+		models.AttributeTypeText: true,
+		models.AttributeTypeNumber: true,
+		models.AttributeTypeBoolean: true,
+	}
+)
+
+// GetProductCategoryAttributes returns the attributes a product of the category can have,
+// which are the ones of the category and of all its parents.
+func (uc *ProductUsecase) GetProductCategoryAttributes(ctx context.Context, productCategoryID int) ([]models.ProductAttribute, error) {
+	productCategoryIDs, err := uc.productCategoryWithAncestorIDs(ctx, productCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	productAttributes, err := uc.ProductService.GetProductAttributes(ctx, productCategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if productAttributes == nil {
+		productAttributes = []models.ProductAttribute{}
+	}
+	return productAttributes, nil
+}
+
+func (uc *ProductUsecase) CreateProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) (int64, error) {
+	productAttribute.ID = 0
+	if err := uc.prepareProductAttribute(ctx, productAttribute); err != nil {
+		return 0, err
+	}
+
+	productAttributeID, err := uc.ProductService.CreateProductAttribute(ctx, productAttribute)
+	if err != nil {
+		return 0, err
+	}
+	return productAttributeID, nil
+}
+
+// UpdateProductAttribute keeps the category and code, the type can only change while no product uses it.
+func (uc *ProductUsecase) UpdateProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) (*models.ProductAttribute, error) {
+	existingProductAttribute, err := uc.ProductService.GetProductAttributeByID(ctx, productAttribute.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingProductAttribute.ID == 0 {
+		return nil, ErrProductAttributeNotFound
+	}
+
+	productAttribute.CategoryID = existingProductAttribute.CategoryID
+	productAttribute.Code = existingProductAttribute.Code
+	if err = uc.prepareProductAttribute(ctx, productAttribute); err != nil {
+		return nil, err
+	}
+
+	if productAttribute.Type != existingProductAttribute.Type {
+		total, err := uc.ProductService.CountProductAttributeValues(ctx, productAttribute.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if total > 0 {
+			return nil, fmt.Errorf("%w: type cannot change while %d products have a value", ErrInvalidProductAttribute, total)
+		}
+	}
+
+	updatedProductAttribute, err := uc.ProductService.UpdateProductAttribute(ctx, productAttribute)
+	if err != nil {
+		return nil, err
+	}
+	return updatedProductAttribute, nil
+}
+
+func (uc *ProductUsecase) DeleteProductAttribute(ctx context.Context, productAttributeID int64) error {
+	productAttribute, err := uc.ProductService.GetProductAttributeByID(ctx, productAttributeID)
+	if err != nil {
+		return err
+	}
+
+	if productAttribute.ID == 0 {
+		return ErrProductAttributeNotFound
+	}
+
+	if err = uc.ProductService.DeleteProductAttribute(ctx, productAttributeID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *ProductUsecase) prepareProductAttribute(ctx context.Context, productAttribute *models.ProductAttribute) error {
+	productAttribute.Code = strings.ToLower(strings.TrimSpace(productAttribute.Code))
+	productAttribute.Name = strings.TrimSpace(productAttribute.Name)
+
+	switch {
+	case !productAttributeCodePattern.MatchString(productAttribute.Code):
+		return fmt.Errorf("%w: code must start with a letter and use only a-z, 0-9 and _", ErrInvalidProductAttribute)
+	case productAttribute.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidProductAttribute)
+	case !productAttributeTypes[productAttribute.Type]:
+		return fmt.Errorf("%w: type %q, use text, number or boolean", ErrInvalidProductAttribute, productAttribute.Type)
+	case len(productAttribute.Options) != 0 && productAttribute.Type != models.AttributeTypeText:
+		return fmt.Errorf("%w: only text attributes can have options", ErrInvalidProductAttribute)
+	}
+
+	options := make([]string, 0, len(productAttribute.Options))
+	seen := map[string]bool{}
+	for _, option := range productAttribute.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			continue
+		}
+		seen[option] = true
+		options = append(options, option)
+	}
+	productAttribute.Options = options
+
+	ancestorIDs, err := uc.productCategoryWithAncestorIDs(ctx, productAttribute.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	descendantIDs, err := uc.productCategoryWithDescendantIDs(ctx, productAttribute.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	lineage := map[int64]bool{}
+	for _, productCategoryID := range append(ancestorIDs, descendantIDs...) {
+		lineage[productCategoryID] = true
+	}
+
+	productAttributes, err := uc.ProductService.GetProductAttributes(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range productAttributes {
+		if existing.ID == productAttribute.ID || existing.Code != productAttribute.Code {
+			continue
+		}
+
+		// a product would get two definitions for the same code
+		if lineage[int64(existing.CategoryID)] {
+			return fmt.Errorf("%w: code %s is already defined on category %d", ErrInvalidProductAttribute, existing.Code, existing.CategoryID)
+		}
+
+		// filters and facets work per code across categories, so one code must mean one type
+		if existing.Type != productAttribute.Type {
+			return fmt.Errorf("%w: code %s is a %s attribute on category %d", ErrInvalidProductAttribute, existing.Code, existing.Type, existing.CategoryID)
+		}
+	}
+	return nil
+}
+
+// prepareProductAttributes checks the attribute values of a product against the definitions of its category
+// and fills in AttributeValues. Empty values are dropped.
+func (uc *ProductUsecase) prepareProductAttributes(ctx context.Context, product *models.Product) error {
+	if product.Attributes == nil {
+		return nil
+	}
+
+	var productAttributes []models.ProductAttribute
+	if product.Category_ID != 0 {
+		var err error
+		productAttributes, err = uc.GetProductCategoryAttributes(ctx, int(product.Category_ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	byCode := make(map[string]models.ProductAttribute, len(productAttributes))
+	for _, productAttribute := range productAttributes {
+		byCode[productAttribute.Code] = productAttribute
+	}
+
+	attributes := make(map[string]string, len(product.Attributes))
+	productAttributeValues := make([]models.ProductAttributeValue, 0, len(product.Attributes))
+	for code, value := range product.Attributes {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		productAttribute, ok := byCode[code]
+		if !ok {
+			return fmt.Errorf("%w: attribute %s is not defined for category %d", ErrInvalidProduct, code, product.Category_ID)
+		}
+
+		canonical, err := canonicalProductAttributeValue(&productAttribute, value)
+		if err != nil {
+			return fmt.Errorf("%w: attribute %s: %v", ErrInvalidProduct, code, err)
+		}
+
+		attributes[code] = canonical
+		productAttributeValues = append(productAttributeValues, models.ProductAttributeValue{
+			AttributeID: productAttribute.ID,
+			Value: canonical,
+		})
+	}
+
+	product.Attributes = attributes
+	product.AttributeValues = productAttributeValues
+	return nil
+}
+
+// prepareProductFilters turns the filter[code] query parameters into typed filters and returns the
+// filterable attributes of the listed categories, which are the facets of the response.
+func (uc *ProductUsecase) prepareProductFilters(ctx context.Context, param *models.ProductListParameter) ([]models.ProductAttribute, error) {
+	var productCategoryIDs []int64
+	if param.CategoryID != 0 {
+		// an unknown category simply lists nothing, as before facets existed
+		ancestorIDs, err := uc.productCategoryWithAncestorIDs(ctx, int(param.CategoryID))
+		if err != nil && !errors.Is(err, ErrProductCategoryNotFound) {
+			return nil, err
+		}
+		productCategoryIDs = append(ancestorIDs, param.CategoryIDs...)
+	}
+
+	productAttributes, err := uc.ProductService.GetProductAttributes(ctx, productCategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the same code may be defined on several categories, the first definition names the facet
+	var facetAttributes []models.ProductAttribute
+	byCode := map[string]models.ProductAttribute{}
+	for _, productAttribute := range productAttributes {
+		if !productAttribute.Filterable {
+			continue
+		}
+
+		if _, ok := byCode[productAttribute.Code]; ok {
+			continue
+		}
+		byCode[productAttribute.Code] = productAttribute
+		facetAttributes = append(facetAttributes, productAttribute)
+	}
+
+	param.Filters = nil
+	for code, rawValue := range param.RawFilters {
+		productAttribute, ok := byCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not a filterable attribute here", ErrInvalidProductFilter, code)
+		}
+
+		attributeFilter, err := parseProductAttributeFilter(&productAttribute, rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidProductFilter, code, err)
+		}
+		param.Filters = append(param.Filters, *attributeFilter)
+	}
+	return facetAttributes, nil
+}
+
+// parseProductAttributeFilter reads a comma separated list of values, numbers also take a min..max range
+// where either end may be left out.
+func parseProductAttributeFilter(productAttribute *models.ProductAttribute, rawValue string) (*models.ProductAttributeFilter, error) {
+	attributeFilter := &models.ProductAttributeFilter{Code: productAttribute.Code}
+	if productAttribute.Type == models.AttributeTypeNumber && strings.Contains(rawValue, "..") {
+		bounds := strings.SplitN(rawValue, "..", 2)
+		for i, bound := range bounds {
+			bound = strings.TrimSpace(bound)
+			if bound == "" {
+				continue
+			}
+
+			number, err := strconv.ParseFloat(bound, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", bound)
+			}
+
+			if i == 0 {
+				attributeFilter.Min = &number
+			} else {
+				attributeFilter.Max = &number
+			}
+		}
+
+		if attributeFilter.Min == nil && attributeFilter.Max == nil {
+			return nil, fmt.Errorf("range needs a min or a max")
+		}
+		return attributeFilter, nil
+	}
+
+	for _, value := range strings.Split(rawValue, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		canonical, err := canonicalProductAttributeValue(productAttribute, value)
+		if err != nil {
+			return nil, err
+		}
+		attributeFilter.Values = append(attributeFilter.Values, canonical)
+	}
+
+	if len(attributeFilter.Values) == 0 {
+		return nil, fmt.Errorf("no value given")
+	}
+	return attributeFilter, nil
+}
+
+func canonicalProductAttributeValue(productAttribute *models.ProductAttribute, value string) (string, error) {
+	switch productAttribute.Type {
+	case models.AttributeTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case models.AttributeTypeBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not true or false", value)
+		}
+		return strconv.FormatBool(boolean), nil
+	}
+
+	if len(productAttribute.Options) == 0 {
+		return value, nil
+	}
+
+	for _, option := range productAttribute.Options {
+		if strings.EqualFold(option, value) {
+			return option, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of %s", value, strings.Join(productAttribute.Options, ", "))
+}
+
+func (uc *ProductUsecase) productCategoryWithAncestorIDs(ctx context.Context, productCategoryID int) ([]int64, error) {
+	breadcrumb, err := uc.GetProductCategoryBreadcrumb(ctx, productCategoryID, models.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	productCategoryIDs := make([]int64, len(breadcrumb))
+	for i, productCategory := range breadcrumb {
+		productCategoryIDs[i] = int64(productCategory.ID)
+	}
+	return productCategoryIDs, nil
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+var ErrInvalidProductBatch = errors.New("invalid product batch")
+
+const maxProductBatchSize = 100
+
+// GetProductsByIDs returns the found products in request order and the IDs that do not exist.
+func (uc *ProductUsecase) GetProductsByIDs(ctx context.Context, productIDs []int64) ([]models.Product, []int64, error) {
+	productIDs = uniqueProductIDs(productIDs)
+	if len(productIDs) == 0 || len(productIDs) > maxProductBatchSize {
+		return nil, nil, fmt.Errorf("%w: ask for 1 to %d products", ErrInvalidProductBatch, maxProductBatchSize)
+	}
+
+	products, err := uc.ProductService.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[int64]bool, len(products))
+	for _, product := range products {
+		found[product.ID] = true
+	}
+
+	var missingProductIDs []int64
+	for _, productID := range productIDs {
+		if !found[productID] {
+			missingProductIDs = append(missingProductIDs, productID)
+		}
+	}
+	return products, missingProductIDs, nil
+}
+
+// CheckAvailability reports per item whether it can be bought in the requested quantity right now.
+// It reads stock from the database, never from the cache.
+func (uc *ProductUsecase) CheckAvailability(ctx context.Context, items []models.ProductAvailabilityItem) ([]models.ProductAvailability, bool, error) {
+	if len(items) == 0 || len(items) > maxProductBatchSize {
+		return nil, false, fmt.Errorf("%w: check 1 to %d items", ErrInvalidProductBatch, maxProductBatchSize)
+	}
+
+	productIDs := make([]int64, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+
+	products, err := uc.ProductService.GetProductsByIDsFromDatabase(ctx, uniqueProductIDs(productIDs))
+	if err != nil {
+		return nil, false, err
+	}
+
+	productsByID := make(map[int64]*models.Product, len(products))
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+
+	allAvailable := true
+	availabilities := make([]models.ProductAvailability, len(items))
+	for i, item := range items {
+		availabilities[i] = checkProductAvailability(productsByID[item.ProductID], item)
+		if !availabilities[i].Available {
+			allAvailable = false
+		}
+	}
+	return availabilities, allAvailable, nil
+}
+
+func checkProductAvailability(product *models.Product, item models.ProductAvailabilityItem) models.ProductAvailability {
+	availability := models.ProductAvailability{
+		ProductAvailabilityItem: item,
+	}
+
+	if product == nil {
+		availability.Reason = "product not found"
+		return availability
+	}
+
+	availability.Name = product.Name
+	if !product.IsPurchasable() {
+		availability.Reason = fmt.Sprintf("product is %s", productUnavailableState(product))
+		return availability
+	}
+
+	if item.Quantity <= 0 {
+		availability.Reason = "quantity must be greater than 0"
+		return availability
+	}
+
+	availability.UnitPrice = product.EffectivePrice
+	availability.AvailableStock = product.Stock
+
+	if len(product.Variants) != 0 || item.VariantID != 0 {
+		productVariant := findProductVariant(product, item.VariantID)
+		if productVariant == nil {
+			availability.Reason = "variant not found for product, pick one of its variants"
+			return availability
+		}
+
+		availability.UnitPrice = productVariantPrice(product, productVariant)
+		availability.AvailableStock = productVariant.Stock
+	}
+
+	if availability.AvailableStock < item.Quantity {
+		availability.Reason = "not enough stock"
+		return availability
+	}
+
+	availability.Available = true
+	return availability
+}
+
+func productUnavailableState(product *models.Product) string {
+	if product.DeletedAt.Valid {
+		return "deleted"
+	}
+	return product.Status
+}
+
+func findProductVariant(product *models.Product, productVariantID int64) *models.ProductVariant {
+	for i := range product.Variants {
+		if product.Variants[i].ID == productVariantID {
+			return &product.Variants[i]
+		}
+	}
+	return nil
+}
+
+// productVariantPrice is the override of the variant when it has one, otherwise the effective price of the product.
+func productVariantPrice(product *models.Product, productVariant *models.ProductVariant) float64 {
+	if productVariant.PriceOverride != nil {
+		return *productVariant.PriceOverride
+	}
+	return product.EffectivePrice
+}
+
+func uniqueProductIDs(productIDs []int64) []int64 {
+	seen := make(map[int64]bool, len(productIDs))
+	unique := make([]int64, 0, len(productIDs))
+	for _, productID := range productIDs {
+		if productID == 0 || seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		unique = append(unique, productID)
+	}
+	return unique
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+const maxProductBundleItems = 20
+
+// prepareProductType defaults the type to simple and checks what each type may carry. A bundle ships its
+// components, so it keeps no stock of its own and has no option axes.
+func (uc *ProductUsecase) prepareProductType(ctx context.Context, product *models.Product) error {
+	if product.Type == "" {
+		product.Type = models.ProductTypeSimple
+	}
+
+	switch product.Type {
+	case models.ProductTypeSimple:
+		if len(product.BundleItems) != 0 {
+			return fmt.Errorf("%w: bundle_items are only allowed on a bundle", ErrInvalidProduct)
+		}
+		product.BundleItems = nil
+		return nil
+	case models.ProductTypeBundle:
+		if len(product.Options) != 0 {
+			return fmt.Errorf("%w: a bundle can not have options", ErrInvalidProduct)
+		}
+		product.Stock = 0
+		product.LowStockThreshold = 0
+		return uc.validateProductBundleItems(ctx, product)
+	default:
+		return fmt.Errorf("%w: type %q, use simple or bundle", ErrInvalidProduct, product.Type)
+	}
+}
+
+// validateProductBundleItems checks that every component is an existing simple product, with a variant
+// picked when it has variants, and appears once.
+func (uc *ProductUsecase) validateProductBundleItems(ctx context.Context, product *models.Product) error {
+	if len(product.BundleItems) == 0 || len(product.BundleItems) > maxProductBundleItems {
+		return fmt.Errorf("%w: a bundle needs 1 to %d bundle_items", ErrInvalidProduct, maxProductBundleItems)
+	}
+
+	seen := map[[2]int64]bool{}
+	componentIDs := make([]int64, len(product.BundleItems))
+	for i, productBundleItem := range product.BundleItems {
+		key := [2]int64{productBundleItem.ProductID, productBundleItem.VariantID}
+		switch {
+		case productBundleItem.Quantity <= 0:
+			return fmt.Errorf("%w: quantity of component %d must be greater than 0", ErrInvalidProduct, productBundleItem.ProductID)
+		case productBundleItem.ProductID == 0 || productBundleItem.ProductID == product.ID:
+			return fmt.Errorf("%w: a bundle can not contain itself or product 0", ErrInvalidProduct)
+		case seen[key]:
+			return fmt.Errorf("%w: component %d variant %d is listed twice", ErrInvalidProduct, productBundleItem.ProductID, productBundleItem.VariantID)
+		}
+		seen[key] = true
+		componentIDs[i] = productBundleItem.ProductID
+	}
+
+	components, err := uc.ProductService.GetProductsByIDsFromDatabase(ctx, uniqueProductIDs(componentIDs))
+	if err != nil {
+		return err
+	}
+
+	componentsByID := make(map[int64]*models.Product, len(components))
+	for i := range components {
+		componentsByID[components[i].ID] = &components[i]
+	}
+
+	for _, productBundleItem := range product.BundleItems {
+		component, ok := componentsByID[productBundleItem.ProductID]
+		if !ok || component.DeletedAt.Valid {
+			return fmt.Errorf("%w: component %d does not exist", ErrInvalidProduct, productBundleItem.ProductID)
+		}
+
+		if component.Type == models.ProductTypeBundle {
+			return fmt.Errorf("%w: component %d is a bundle, bundles can not be nested", ErrInvalidProduct, component.ID)
+		}
+
+		if len(component.Variants) == 0 && productBundleItem.VariantID == 0 {
+			continue
+		}
+
+		if findProductVariant(component, productBundleItem.VariantID) == nil {
+			return fmt.Errorf("%w: pick one of the variants of component %d", ErrInvalidProduct, component.ID)
+		}
+	}
+
+	if product.ID == 0 {
+		return nil
+	}
+
+	// a product some bundle is made of can not turn into a bundle itself
+	bundleIDs, err := uc.ProductService.GetBundleIDsByComponentIDs(ctx, []int64{product.ID})
+	if err != nil {
+		return err
+	}
+
+	if len(bundleIDs) != 0 {
+		return fmt.Errorf("%w: product %d is a component of bundle %d", ErrInvalidProduct, product.ID, bundleIDs[0])
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	CatalogFormatCSV = "csv"
+	CatalogFormatNDJSON = "ndjson"
+
+	catalogChunkSize = 500
+)
+
+var catalogCSVHeader = []string{"sku", "name", "description", "price", "stock", "category"}
+
+type catalogRow struct {
+	Row int
+	CategoryID int64
+	models.ProductImportRow
+}
+
+func IsSupportedCatalogFormat(format string) bool {
+	return format == CatalogFormatCSV || format == CatalogFormatNDJSON
+}
+
+// ImportProducts validates every row first and only then upserts the valid ones by SKU,
+// chunk by chunk, so a broken row never blocks the rest of the file.
+func (uc *ProductUsecase) ImportProducts(ctx context.Context, format string, reader io.Reader, dryRun bool) (*models.ProductImportResult, error) {
+	var (
+		rows []catalogRow
+		rowErrors []models.ProductImportError
+		err error
+	)
+
+	switch format {
+	case CatalogFormatCSV:
+		rows, rowErrors, err = decodeCSVCatalogRows(reader)
+	case CatalogFormatNDJSON:
+		rows, rowErrors, err = decodeNDJSONCatalogRows(reader)
+	default:
+		return nil, fmt.Errorf("unsupported catalog format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductImportResult{
+		DryRun: dryRun,
+		TotalRows: len(rows) + len(rowErrors),
+	}
+
+	validRows, validationErrors := validateCatalogRows(rows)
+	rowErrors = append(rowErrors, validationErrors...)
+
+	validRows, categoryErrors, err := uc.resolveCatalogCategories(ctx, validRows)
+	if err != nil {
+		return nil, err
+	}
+	rowErrors = append(rowErrors, categoryErrors...)
+
+	for start := 0; start < len(validRows); start += catalogChunkSize {
+		end := min(start+catalogChunkSize, len(validRows))
+		chunk := validRows[start:end]
+
+		created, updated, chunkErrors, err := uc.importCatalogChunk(ctx, chunk, dryRun)
+		if err != nil {
+			log.Logger.WithFields(logrus.Fields{
+				"firstRow": chunk[0].Row,
+				"lastRow": chunk[len(chunk)-1].Row,
+			}).Errorf("uc.importCatalogChunk got an error at %v", err)
+			for _, row := range chunk {
+				rowErrors = append(rowErrors, models.ProductImportError{
+					Row: row.Row,
+					SKU: row.SKU,
+					Message: "failed to save chunk, no rows in it were imported",
+				})
+			}
+			continue
+		}
+
+		result.Created += created
+		result.Updated += updated
+		rowErrors = append(rowErrors, chunkErrors...)
+	}
+
+	sort.Slice(rowErrors, func(i, j int) bool {
+		return rowErrors[i].Row < rowErrors[j].Row
+	})
+	result.Failed = len(rowErrors)
+	result.Errors = rowErrors
+
+	return result, nil
+}
+
+func (uc *ProductUsecase) ExportProducts(ctx context.Context, format string, writer io.Writer) error {
+	if !IsSupportedCatalogFormat(format) {
+		return fmt.Errorf("unsupported catalog format %q", format)
+	}
+
+	productCategories, err := uc.ProductService.GetAllProductCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	categoryNames := make(map[int64]string, len(productCategories))
+	for _, productCategory := range productCategories {
+		categoryNames[int64(productCategory.ID)] = productCategory.Name
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	jsonEncoder := json.NewEncoder(writer)
+
+	if format == CatalogFormatCSV {
+		if err := csvWriter.Write(catalogCSVHeader); err != nil {
+			return err
+		}
+	}
+
+	return uc.ProductService.StreamProducts(ctx, catalogChunkSize, func(products []models.Product) error {
+		for _, product := range products {
+			row := models.ProductImportRow{
+				SKU: product.SKU,
+				Name: product.Name,
+				Description: product.Description,
+				Price: product.Price,
+				Stock: product.Stock,
+				Category: categoryNames[product.Category_ID],
+			}
+
+			if format == CatalogFormatNDJSON {
+				if err := jsonEncoder.Encode(row); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := csvWriter.Write([]string{
+				row.SKU,
+				row.Name,
+				row.Description,
+				strconv.FormatFloat(row.Price, 'f', -1, 64),
+				strconv.Itoa(row.Stock),
+				row.Category,
+			}); err != nil {
+				return err
+			}
+		}
+
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+
+		if flusher, ok := writer.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+func (uc *ProductUsecase) importCatalogChunk(ctx context.Context, chunk []catalogRow, dryRun bool) (int, int, []models.ProductImportError, error) {
+	skus := make([]string, len(chunk))
+	for i, row := range chunk {
+		skus[i] = row.SKU
+	}
+
+	existingProducts, err := uc.ProductService.GetProductsBySKUs(ctx, skus)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	deletedSKUs := map[string]bool{}
+	previousPrices := map[string]float64{}
+	for _, existingProduct := range existingProducts {
+		if existingProduct.DeletedAt.Valid {
+			deletedSKUs[existingProduct.SKU] = true
+			continue
+		}
+		previousPrices[existingProduct.SKU] = existingProduct.Price
+	}
+	updated := len(previousPrices)
+
+	existingSKUs := make(map[string]bool, len(existingProducts))
+	for _, existingProduct := range existingProducts {
+		existingSKUs[existingProduct.SKU] = true
+	}
+
+	var (
+		products []models.Product
+		rowErrors []models.ProductImportError
+	)
+	reservedSlugs := map[string]bool{}
+	for _, row := range chunk {
+		// the upsert would silently edit a row nobody can see, so deleted products must be restored first
+		if deletedSKUs[row.SKU] {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: row.Row,
+				SKU: row.SKU,
+				Message: "sku belongs to a deleted product, restore it first",
+			})
+			continue
+		}
+
+		// the upsert never touches the slug of an existing product, only new ones need one
+		var slug string
+		if !existingSKUs[row.SKU] && !dryRun {
+			slug, err = uc.uniqueProductSlug(ctx, utils.Slugify(row.Name), 0, true, reservedSlugs)
+			if err != nil {
+				if !errors.Is(err, ErrInvalidProduct) {
+					return 0, 0, nil, err
+				}
+
+				rowErrors = append(rowErrors, models.ProductImportError{
+					Row: row.Row,
+					SKU: row.SKU,
+					Message: err.Error(),
+				})
+				continue
+			}
+			reservedSlugs[slug] = true
+		}
+
+		products = append(products, models.Product{
+			SKU: row.SKU,
+			Name: row.Name,
+			Slug: slug,
+			Description: row.Description,
+			Price: row.Price,
+			Stock: row.Stock,
+			Category_ID: row.CategoryID,
+		})
+	}
+
+	created := len(products) - updated
+	if dryRun || len(products) == 0 {
+		return created, updated, rowErrors, nil
+	}
+
+	if err := uc.ProductService.UpsertProductsBySKU(ctx, products, previousPrices); err != nil {
+		return 0, 0, nil, err
+	}
+
+	return created, updated, rowErrors, nil
+}
+
+func (uc *ProductUsecase) resolveCatalogCategories(ctx context.Context, rows []catalogRow) ([]catalogRow, []models.ProductImportError, error) {
+	if len(rows) == 0 {
+		return rows, nil, nil
+	}
+
+	categoryIDs, err := uc.catalogCategoryIDs(ctx, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		resolvedRows []catalogRow
+		rowErrors []models.ProductImportError
+	)
+	for _, row := range rows {
+		categoryID, ok := categoryIDs[strings.ToLower(row.Category)]
+		if !ok {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: row.Row,
+				SKU: row.SKU,
+				Message: fmt.Sprintf("unknown category %q", row.Category),
+			})
+			continue
+		}
+
+		row.CategoryID = categoryID
+		resolvedRows = append(resolvedRows, row)
+	}
+
+	return resolvedRows, rowErrors, nil
+}
+
+func (uc *ProductUsecase) catalogCategoryIDs(ctx context.Context, rows []catalogRow) (map[string]int64, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, row := range rows {
+		name := strings.ToLower(row.Category)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	productCategories, err := uc.ProductService.GetProductCategoriesByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIDs := make(map[string]int64, len(productCategories))
+	for _, productCategory := range productCategories {
+		categoryIDs[strings.ToLower(productCategory.Name)] = int64(productCategory.ID)
+	}
+	return categoryIDs, nil
+}
+
+func validateCatalogRows(rows []catalogRow) ([]catalogRow, []models.ProductImportError) {
+	var (
+		validRows []catalogRow
+		rowErrors []models.ProductImportError
+	)
+
+	seenSKUs := map[string]int{}
+	for _, row := range rows {
+		var message string
+		switch {
+		case row.SKU == "":
+			message = "sku is required"
+		case seenSKUs[row.SKU] != 0:
+			message = fmt.Sprintf("duplicate sku, first seen at row %d", seenSKUs[row.SKU])
+		case row.Name == "":
+			message = "name is required"
+		case row.Price <= 0:
+			message = "price must be greater than 0"
+		case row.Stock < 0:
+			message = "stock must not be negative"
+		case row.Category == "":
+			message = "category is required"
+		}
+
+		if message != "" {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: row.Row,
+				SKU: row.SKU,
+				Message: message,
+			})
+			continue
+		}
+
+		seenSKUs[row.SKU] = row.Row
+		validRows = append(validRows, row)
+	}
+
+	return validRows, rowErrors
+}
+
+func decodeCSVCatalogRows(reader io.Reader) ([]catalogRow, []models.ProductImportError, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("catalog file is empty")
+		}
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range catalogCSVHeader {
+		if _, ok := columns[column]; !ok {
+			return nil, nil, fmt.Errorf("missing csv column %q", column)
+		}
+	}
+
+	var (
+		rows []catalogRow
+		rowErrors []models.ProductImportError
+	)
+
+	// the header is row 1, so data rows line up with what a spreadsheet shows
+	for rowNumber := 2; ; rowNumber++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: rowNumber,
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		field := func(column string) string {
+			index := columns[column]
+			if index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		row := catalogRow{
+			Row: rowNumber,
+			ProductImportRow: models.ProductImportRow{
+				SKU: field("sku"),
+				Name: field("name"),
+				Description: field("description"),
+				Category: field("category"),
+			},
+		}
+
+		price, err := strconv.ParseFloat(field("price"), 64)
+		if err != nil {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: rowNumber,
+				SKU: row.SKU,
+				Message: fmt.Sprintf("invalid price %q", field("price")),
+			})
+			continue
+		}
+		row.Price = price
+
+		stock, err := strconv.Atoi(field("stock"))
+		if err != nil {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: rowNumber,
+				SKU: row.SKU,
+				Message: fmt.Sprintf("invalid stock %q", field("stock")),
+			})
+			continue
+		}
+		row.Stock = stock
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+func decodeNDJSONCatalogRows(reader io.Reader) ([]catalogRow, []models.ProductImportError, error) {
+	var (
+		rows []catalogRow
+		rowErrors []models.ProductImportError
+	)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for rowNumber := 1; scanner.Scan(); rowNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var importRow models.ProductImportRow
+		if err := json.Unmarshal([]byte(line), &importRow); err != nil {
+			rowErrors = append(rowErrors, models.ProductImportError{
+				Row: rowNumber,
+				Message: fmt.Sprintf("invalid json: %v", err),
+			})
+			continue
+		}
+
+		importRow.SKU = strings.TrimSpace(importRow.SKU)
+		importRow.Name = strings.TrimSpace(importRow.Name)
+		importRow.Category = strings.TrimSpace(importRow.Category)
+		rows = append(rows, catalogRow{
+			Row: rowNumber,
+			ProductImportRow: importRow,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return rows, rowErrors, nil
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/utils"
+)
+
+var (
+	ErrProductCategoryNotFound = errors.New("product category not found")
+	ErrInvalidProductCategory = errors.New("invalid product category")
+	ErrProductCategoryInUse = errors.New("product category still in use")
+)
+
+func (uc *ProductUsecase) GetProductCategoryTree(ctx context.Context, includeInactive bool, locale string) ([]models.ProductCategory, error) {
+	productCategories, err := uc.ProductService.GetLocalizedProductCategories(ctx, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	childrenByParentID := map[int][]models.ProductCategory{}
+	exists := make(map[int]bool, len(productCategories))
+	for _, productCategory := range productCategories {
+		exists[productCategory.ID] = true
+	}
+
+	for _, productCategory := range productCategories {
+		parentID := 0
+		// a category whose parent is gone is shown as a root instead of disappearing
+		if productCategory.ParentID != nil && exists[*productCategory.ParentID] {
+			parentID = *productCategory.ParentID
+		}
+		childrenByParentID[parentID] = append(childrenByParentID[parentID], productCategory)
+	}
+
+	var build func(parentID int) []models.ProductCategory
+	build = func(parentID int) []models.ProductCategory {
+		var nodes []models.ProductCategory
+		for _, productCategory := range childrenByParentID[parentID] {
+			if !productCategory.IsActive && !includeInactive {
+				continue
+			}
+			productCategory.Children = build(productCategory.ID)
+			nodes = append(nodes, productCategory)
+		}
+		return nodes
+	}
+
+	tree := build(0)
+	if tree == nil {
+		tree = []models.ProductCategory{}
+	}
+	return tree, nil
+}
+
+// GetProductCategoryBreadcrumb returns the path from the root category down to productCategoryID.
+func (uc *ProductUsecase) GetProductCategoryBreadcrumb(ctx context.Context, productCategoryID int, locale string) ([]models.ProductCategory, error) {
+	productCategories, err := uc.ProductService.GetLocalizedProductCategories(ctx, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]models.ProductCategory, len(productCategories))
+	for _, productCategory := range productCategories {
+		byID[productCategory.ID] = productCategory
+	}
+
+	current, ok := byID[productCategoryID]
+	if !ok {
+		return nil, ErrProductCategoryNotFound
+	}
+
+	breadcrumb := []models.ProductCategory{current}
+	for current.ParentID != nil && len(breadcrumb) <= len(productCategories) {
+		parent, ok := byID[*current.ParentID]
+		if !ok {
+			break
+		}
+		breadcrumb = append([]models.ProductCategory{parent}, breadcrumb...)
+		current = parent
+	}
+
+	return breadcrumb, nil
+}
+
+func (uc *ProductUsecase) productCategoryWithDescendantIDs(ctx context.Context, productCategoryID int) ([]int64, error) {
+	productCategories, err := uc.ProductService.GetAllProductCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return descendantCategoryIDs(productCategories, productCategoryID), nil
+}
+
+func (uc *ProductUsecase) prepareProductCategory(ctx context.Context, productCategory *models.ProductCategory) error {
+	if productCategory.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProductCategory)
+	}
+
+	productCategories, err := uc.ProductService.GetAllProductCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]models.ProductCategory, len(productCategories))
+	for _, existing := range productCategories {
+		byID[existing.ID] = existing
+	}
+
+	if productCategory.ID != 0 {
+		existing, ok := byID[productCategory.ID]
+		if !ok {
+			return ErrProductCategoryNotFound
+		}
+
+		// keep the current slug when the edit does not send one so links stay stable
+		if productCategory.Slug == "" {
+			productCategory.Slug = existing.Slug
+		}
+	}
+
+	if productCategory.ParentID != nil {
+		if _, ok := byID[*productCategory.ParentID]; !ok {
+			return fmt.Errorf("%w: parent category %d does not exist", ErrInvalidProductCategory, *productCategory.ParentID)
+		}
+
+		for _, descendantID := range descendantCategoryIDs(productCategories, productCategory.ID) {
+			if productCategory.ID != 0 && int(descendantID) == *productCategory.ParentID {
+				return fmt.Errorf("%w: a category cannot be moved under itself or its descendants", ErrInvalidProductCategory)
+			}
+		}
+	}
+
+	generated := productCategory.Slug == ""
+	if generated {
+		productCategory.Slug = utils.Slugify(productCategory.Name)
+	} else {
+		productCategory.Slug = utils.Slugify(productCategory.Slug)
+	}
+
+	if productCategory.Slug == "" {
+		return fmt.Errorf("%w: slug must contain letters or digits", ErrInvalidProductCategory)
+	}
+
+	baseSlug := productCategory.Slug
+	for suffix := 2; ; suffix++ {
+		taken := false
+		for _, existing := range productCategories {
+			if existing.Slug == productCategory.Slug && existing.ID != productCategory.ID {
+				taken = true
+				break
+			}
+		}
+
+		if !taken {
+			return nil
+		}
+
+		if !generated {
+			return fmt.Errorf("%w: slug %s is already used", ErrInvalidProductCategory, productCategory.Slug)
+		}
+		productCategory.Slug = fmt.Sprintf("%s-%d", baseSlug, suffix)
+	}
+}
+
+func (uc *ProductUsecase) checkProductCategoryDeletable(ctx context.Context, productCategoryID int, reassignTo int) error {
+	productCategory, err := uc.ProductService.GetProductCategoryByID(ctx, productCategoryID)
+	if err != nil {
+		return err
+	}
+
+	if productCategory.ID == 0 {
+		return ErrProductCategoryNotFound
+	}
+
+	if reassignTo != 0 {
+		productCategories, err := uc.ProductService.GetAllProductCategories(ctx)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, existing := range productCategories {
+			if existing.ID == reassignTo {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: reassign target %d does not exist", ErrInvalidProductCategory, reassignTo)
+		}
+
+		for _, descendantID := range descendantCategoryIDs(productCategories, productCategoryID) {
+			if int(descendantID) == reassignTo {
+				return fmt.Errorf("%w: cannot reassign to the deleted category or its descendants", ErrInvalidProductCategory)
+			}
+		}
+		return nil
+	}
+
+	totalProducts, err := uc.ProductService.CountProductsByCategoryID(ctx, productCategoryID)
+	if err != nil {
+		return err
+	}
+
+	totalChildren, err := uc.ProductService.CountChildProductCategories(ctx, productCategoryID)
+	if err != nil {
+		return err
+	}
+
+	if totalProducts > 0 || totalChildren > 0 {
+		return fmt.Errorf("%w: %d products and %d child categories still reference it, send reassign_to to move them",
+			ErrProductCategoryInUse, totalProducts, totalChildren)
+	}
+	return nil
+}
+
+// descendantCategoryIDs returns rootID followed by every category below it.
+func descendantCategoryIDs(productCategories []models.ProductCategory, rootID int) []int64 {
+	childIDsByParentID := map[int][]int{}
+	for _, productCategory := range productCategories {
+		if productCategory.ParentID != nil {
+			childIDsByParentID[*productCategory.ParentID] = append(childIDsByParentID[*productCategory.ParentID], productCategory.ID)
+		}
+	}
+
+	ids := []int64{int64(rootID)}
+	visited := map[int]bool{rootID: true}
+	queue := []int{rootID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, childID := range childIDsByParentID[current] {
+			if visited[childID] {
+				continue
+			}
+			visited[childID] = true
+			ids = append(ids, int64(childID))
+			queue = append(queue, childID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/repository"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+var (
+	ErrFlashSaleNotFound = errors.New("flash sale not found")
+	ErrInvalidFlashSale = errors.New("invalid flash sale")
+	ErrFlashSaleNotRunning = errors.New("flash sale is not running")
+	ErrFlashSaleLimitExceeded = errors.New("flash sale purchase limit exceeded")
+	ErrFlashSaleSoldOut = errors.New("flash sale is sold out")
+)
+
+var flashSaleStatuses = map[string]bool{
+	models.FlashSaleStatusScheduled: true,
+	models.FlashSaleStatusActive: true,
+	models.FlashSaleStatusEnded: true,
+	models.FlashSaleStatusCancelled: true,
+}
+
+// CreateFlashSale schedules a sale, its stock is only taken out of the product when the sale starts.
+func (uc *ProductUsecase) CreateFlashSale(ctx context.Context, param *models.FlashSaleParameter) (*models.FlashSale, error) {
+	if param.Price <= 0 || param.Stock <= 0 || param.PerUserLimit < 0 {
+		return nil, fmt.Errorf("%w: price and stock must be positive and per_user_limit must not be negative", ErrInvalidFlashSale)
+	}
+
+	if !param.StartTime.Before(param.EndTime) || !param.EndTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: start_time must be before end_time and end_time must be in the future", ErrInvalidFlashSale)
+	}
+
+	product, err := uc.ProductService.GetProductByID(ctx, param.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 || !product.IsPurchasable() {
+		return nil, ErrProductNotFound
+	}
+
+	if product.Type == models.ProductTypeBundle {
+		return nil, fmt.Errorf("%w: product %d is a bundle, put its components on sale instead", ErrInvalidFlashSale, product.ID)
+	}
+
+	if len(product.Variants) != 0 && param.VariantID == 0 {
+		return nil, fmt.Errorf("%w: product %d has variants, pick one with variant_id", ErrInvalidFlashSale, product.ID)
+	}
+
+	if param.VariantID != 0 {
+		found := false
+		for _, productVariant := range product.Variants {
+			if productVariant.ID == param.VariantID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, ErrProductVariantNotFound
+		}
+	}
+
+	flashSale := &models.FlashSale{
+		ProductID: param.ProductID,
+		VariantID: param.VariantID,
+		Price: param.Price,
+		Stock: param.Stock,
+		PerUserLimit: param.PerUserLimit,
+		StartTime: param.StartTime,
+		EndTime: param.EndTime,
+		Status: models.FlashSaleStatusScheduled,
+	}
+
+	total, err := uc.ProductService.CountOverlappingFlashSales(ctx, flashSale)
+	if err != nil {
+		return nil, err
+	}
+
+	if total > 0 {
+		return nil, fmt.Errorf("%w: the product already has a flash sale in that time window", ErrInvalidFlashSale)
+	}
+
+	flashSaleID, err := uc.ProductService.CreateFlashSale(ctx, flashSale)
+	if err != nil {
+		return nil, err
+	}
+	flashSale.ID = flashSaleID
+	return flashSale, nil
+}
+
+func (uc *ProductUsecase) GetFlashSales(ctx context.Context, param *models.FlashSaleListParameter) ([]models.FlashSale, error) {
+	statuses := []string{models.FlashSaleStatusScheduled, models.FlashSaleStatusActive}
+	if param.Status != "" {
+		if !flashSaleStatuses[param.Status] {
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidFlashSale, param.Status)
+		}
+		statuses = []string{param.Status}
+	}
+
+	flashSales, err := uc.ProductService.GetFlashSales(ctx, statuses)
+	if err != nil {
+		return nil, err
+	}
+	return flashSales, nil
+}
+
+// EndFlashSale cancels a sale that has not started, or moves the end of a running sale to now
+// so the next reconciliation returns its unsold stock.
+func (uc *ProductUsecase) EndFlashSale(ctx context.Context, flashSaleID int64) error {
+	flashSale, err := uc.ProductService.GetFlashSaleByID(ctx, flashSaleID)
+	if err != nil {
+		return err
+	}
+
+	if flashSale.ID == 0 {
+		return ErrFlashSaleNotFound
+	}
+
+	var updates map[string]interface{}
+	switch flashSale.Status {
+	case models.FlashSaleStatusScheduled:
+		updates = map[string]interface{}{"status": models.FlashSaleStatusCancelled}
+	case models.FlashSaleStatusActive:
+		updates = map[string]interface{}{"end_time": time.Now()}
+	default:
+		return fmt.Errorf("%w: flash sale is already %s", ErrInvalidFlashSale, flashSale.Status)
+	}
+
+	if err = uc.ProductService.UpdateFlashSale(ctx, flashSaleID, updates); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReserveFlashSaleStock takes the items that are on a running flash sale out of its stock, all or nothing.
+// Items without a sale are left to the regular stock and are not in the result.
+func (uc *ProductUsecase) ReserveFlashSaleStock(ctx context.Context, userID int64, items []models.ProductAvailabilityItem) ([]models.FlashSaleReservation, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidProductBatch)
+	}
+
+	if len(items) == 0 || len(items) > maxProductBatchSize {
+		return nil, fmt.Errorf("%w: reserve 1 to %d items", ErrInvalidProductBatch, maxProductBatchSize)
+	}
+
+	for _, item := range items {
+		if item.ProductID == 0 || item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: every item needs a product_id and a positive quantity", ErrInvalidProductBatch)
+		}
+	}
+
+	reservations, code, index, err := uc.ProductService.ReserveFlashSaleStock(ctx, userID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	switch code {
+	case repository.FlashSaleReserved:
+		return reservations, nil
+	case repository.FlashSaleLimitExceeded:
+		return nil, fmt.Errorf("%w: product %d", ErrFlashSaleLimitExceeded, items[index].ProductID)
+	case repository.FlashSaleSoldOut:
+		return nil, fmt.Errorf("%w: product %d", ErrFlashSaleSoldOut, items[index].ProductID)
+	default:
+		return nil, fmt.Errorf("%w: product %d", ErrFlashSaleNotRunning, items[index].ProductID)
+	}
+}
+
+// ReleaseFlashSaleStock gives back reservations of a checkout that failed before the order was saved.
+func (uc *ProductUsecase) ReleaseFlashSaleStock(ctx context.Context, userID int64, reservations []models.FlashSaleReservation) error {
+	for _, reservation := range reservations {
+		if reservation.FlashSaleID == 0 || reservation.ProductID == 0 || reservation.Quantity <= 0 {
+			return fmt.Errorf("%w: every reservation needs a flash_sale_id, product_id and a positive quantity", ErrInvalidProductBatch)
+		}
+	}
+
+	if err := uc.ProductService.ReleaseFlashSaleStock(ctx, userID, reservations); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/image/draw"
+)
+
+const (
+	MaxProductImageSize = 5 << 20
+	// MaxProductImagePixels caps the decoded size, a small file can still declare huge dimensions
+	MaxProductImagePixels = 40_000_000
+)
+
+var (
+	ErrProductImageNotFound = errors.New("product image not found")
+	ErrInvalidProductImage = errors.New("invalid product image")
+	ErrProductImageTooLarge = errors.New("product image too large")
+)
+
+var (
+	// thumbnails are scaled to fit inside a square box of each size
+	productImageThumbnailSizes = []int{150, 300, 600}
+
+	productImageExtensions = map[string]string{
+		"image/jpeg": "jpg",
+		"image/png": "png",
+	}
+)
+
+func (uc *ProductUsecase) UploadProductImage(ctx context.Context, productID int64, content io.Reader) (*models.ProductImage, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	// read one byte past the limit so an oversized upload is caught without trusting the declared size
+	original, err := io.ReadAll(io.LimitReader(content, MaxProductImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(original) > MaxProductImageSize {
+		return nil, fmt.Errorf("%w: maximum size is %d MB", ErrProductImageTooLarge, MaxProductImageSize>>20)
+	}
+
+	contentType := http.DetectContentType(original)
+	extension, ok := productImageExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: content type %s is not allowed, use jpeg or png", ErrInvalidProductImage, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProductImage, err)
+	}
+
+	if config.Width*config.Height > MaxProductImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d megapixels", ErrProductImageTooLarge, config.Width, config.Height, MaxProductImagePixels/1_000_000)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProductImage, err)
+	}
+
+	baseKey := fmt.Sprintf("products/%d/%s", productID, uuid.New().String())
+	productImage := &models.ProductImage{
+		ProductID: productID,
+		ContentType: contentType,
+		Size: int64(len(original)),
+		ObjectKey: fmt.Sprintf("%s.%s", baseKey, extension),
+		ThumbnailKeys: map[string]string{},
+	}
+
+	thumbnails := map[string][]byte{}
+	for _, size := range productImageThumbnailSizes {
+		thumbnail, err := resizeProductImage(decoded, size)
+		if err != nil {
+			return nil, err
+		}
+
+		label := strconv.Itoa(size)
+		thumbnails[label] = thumbnail
+		productImage.ThumbnailKeys[label] = fmt.Sprintf("%s_%d.jpg", baseKey, size)
+	}
+
+	savedProductImage, err := uc.ProductService.SaveProductImage(ctx, productImage, original, thumbnails)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"key": productImage.ObjectKey,
+		}).Errorf("uc.ProductService.SaveProductImage got an error at %v", err)
+		return nil, err
+	}
+	return savedProductImage, nil
+}
+
+func (uc *ProductUsecase) ReorderProductImages(ctx context.Context, productID int64, productImageIDs []int64) error {
+	productImages, err := uc.ProductService.GetProductImages(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	// the new order must mention every image of the product exactly once
+	remaining := make(map[int64]bool, len(productImages))
+	for _, productImage := range productImages {
+		remaining[productImage.ID] = true
+	}
+
+	if len(productImageIDs) != len(productImages) {
+		return fmt.Errorf("%w: expected %d image ids, got %d", ErrInvalidProductImage, len(productImages), len(productImageIDs))
+	}
+
+	for _, productImageID := range productImageIDs {
+		if !remaining[productImageID] {
+			return fmt.Errorf("%w: image %d does not belong to product %d or is listed twice", ErrInvalidProductImage, productImageID, productID)
+		}
+		delete(remaining, productImageID)
+	}
+
+	if err = uc.ProductService.ReorderProductImages(ctx, productID, productImageIDs); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *ProductUsecase) DeleteProductImage(ctx context.Context, productID int64, productImageID int64) error {
+	productImage, err := uc.ProductService.GetProductImageByID(ctx, productImageID)
+	if err != nil {
+		return err
+	}
+
+	if productImage.ID == 0 || productImage.ProductID != productID {
+		return ErrProductImageNotFound
+	}
+
+	if err = uc.ProductService.DeleteProductImage(ctx, productImage); err != nil {
+		return err
+	}
+	return nil
+}
+
+func resizeProductImage(src image.Image, maxSize int) ([]byte, error) {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// never upscale, a small original is used as is
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = height * maxSize / width
+			width = maxSize
+		} else {
+			width = width * maxSize / height
+			height = maxSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	// jpeg has no alpha channel, so flatten transparent png areas onto white
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidProductPrice = errors.New("invalid product price")
+	ErrProductPriceNotFound = errors.New("product price not found")
+)
+
+// scheduling slightly in the past is treated as now, so clients with a skewed clock can still say "immediately"
+const productPriceClockSkew = time.Minute
+
+func (uc *ProductUsecase) ScheduleProductPrice(ctx context.Context, productID int64, param *models.ProductPriceScheduleParameter) (int64, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if product.ID == 0 || product.DeletedAt.Valid {
+		return 0, ErrProductNotFound
+	}
+
+	productPrice := &models.ProductPrice{
+		ProductID: productID,
+		Kind: param.Kind,
+		Price: param.Price,
+		EffectiveFrom: param.EffectiveFrom,
+		EffectiveTo: param.EffectiveTo,
+	}
+
+	productPrices, err := uc.ProductService.GetProductPriceHistory(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = validateProductPrice(productPrice, productPrices, time.Now()); err != nil {
+		return 0, err
+	}
+
+	productPriceID, err := uc.ProductService.ScheduleProductPrice(ctx, productPrice)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productID,
+			"kind": productPrice.Kind,
+			"effectiveFrom": productPrice.EffectiveFrom,
+		}).Errorf("uc.ProductService.ScheduleProductPrice got an error at %v", err)
+		return 0, err
+	}
+	return productPriceID, nil
+}
+
+func (uc *ProductUsecase) GetProductPriceHistory(ctx context.Context, productID int64) ([]models.ProductPrice, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	productPrices, err := uc.ProductService.GetProductPriceHistory(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if productPrices == nil {
+		productPrices = []models.ProductPrice{}
+	}
+	return productPrices, nil
+}
+
+// GetProductPriceAt answers what the product cost at a given moment, sale included.
+func (uc *ProductUsecase) GetProductPriceAt(ctx context.Context, productID int64, at time.Time) (*models.ProductPriceAtResponse, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	productPrices, err := uc.ProductService.GetProductPricesAt(ctx, productID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ProductPriceAtResponse{
+		ProductID: productID,
+		At: at,
+	}
+
+	foundListPrice := false
+	for _, productPrice := range productPrices {
+		switch productPrice.Kind {
+		case models.ProductPriceKindList:
+			response.ListPrice = productPrice.Price
+			foundListPrice = true
+		case models.ProductPriceKindSale:
+			salePrice := productPrice.Price
+			response.SalePrice = &salePrice
+		}
+	}
+
+	if !foundListPrice {
+		totalListPrices, err := uc.ProductService.CountProductPricesByKind(ctx, productID, models.ProductPriceKindList)
+		if err != nil {
+			return nil, err
+		}
+
+		// products created before price history existed only know their current price
+		if totalListPrices != 0 {
+			return nil, fmt.Errorf("%w: no price recorded for product %d at %s", ErrProductPriceNotFound, productID, at.Format(time.RFC3339))
+		}
+		response.ListPrice = product.Price
+	}
+
+	response.EffectivePrice = response.ListPrice
+	if response.SalePrice != nil {
+		response.EffectivePrice = *response.SalePrice
+	}
+	return response, nil
+}
+
+func validateProductPrice(productPrice *models.ProductPrice, productPrices []models.ProductPrice, now time.Time) error {
+	if productPrice.Price <= 0 {
+		return fmt.Errorf("%w: price must be greater than 0", ErrInvalidProductPrice)
+	}
+
+	if productPrice.EffectiveFrom.Before(now.Add(-productPriceClockSkew)) {
+		return fmt.Errorf("%w: effective_from must not be in the past, history cannot be rewritten", ErrInvalidProductPrice)
+	}
+
+	if productPrice.EffectiveFrom.Before(now) {
+		productPrice.EffectiveFrom = now
+	}
+
+	switch productPrice.Kind {
+	case models.ProductPriceKindList:
+		if productPrice.EffectiveTo != nil {
+			return fmt.Errorf("%w: a list price runs until the next one, effective_to must be empty", ErrInvalidProductPrice)
+		}
+
+		for _, existing := range productPrices {
+			if existing.Kind == models.ProductPriceKindList && existing.EffectiveFrom.Equal(productPrice.EffectiveFrom) {
+				return fmt.Errorf("%w: list price %d already starts at that time", ErrInvalidProductPrice, existing.ID)
+			}
+		}
+
+	case models.ProductPriceKindSale:
+		if productPrice.EffectiveTo == nil || !productPrice.EffectiveTo.After(productPrice.EffectiveFrom) {
+			return fmt.Errorf("%w: a sale needs an effective_to after effective_from", ErrInvalidProductPrice)
+		}
+
+		for _, existing := range productPrices {
+			if existing.Kind != models.ProductPriceKindSale || existing.EffectiveTo == nil {
+				continue
+			}
+
+			if productPrice.EffectiveFrom.Before(*existing.EffectiveTo) && existing.EffectiveFrom.Before(*productPrice.EffectiveTo) {
+				return fmt.Errorf("%w: overlaps sale %d", ErrInvalidProductPrice, existing.ID)
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: kind %q, use list or sale", ErrInvalidProductPrice, productPrice.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+const (
+	defaultRelatedProductLimit = 8
+	maxRelatedProductLimit = 20
+)
+
+// GetRelatedProducts serves the products most often bought together with productID. Products without
+// enough order history yet are topped up with the bestsellers of their category.
+func (uc *ProductUsecase) GetRelatedProducts(ctx context.Context, productID int64, param *models.RelatedProductParameter, locale string) ([]models.Product, error) {
+	if param.Limit < 1 {
+		param.Limit = defaultRelatedProductLimit
+	}
+	if param.Limit > maxRelatedProductLimit {
+		param.Limit = maxRelatedProductLimit
+	}
+
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 || !product.IsPurchasable() {
+		return nil, ErrProductNotFound
+	}
+
+	// ask for the whole stored list, archived products fall out below
+	relatedProductIDs, err := uc.ProductService.GetRelatedProductIDs(ctx, productID, maxRelatedProductLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	relatedProducts, err := uc.purchasableProducts(ctx, relatedProductIDs, param.Limit, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(relatedProducts) < param.Limit && product.Category_ID != 0 {
+		excludedProductIDs := []int64{productID}
+		for _, relatedProduct := range relatedProducts {
+			excludedProductIDs = append(excludedProductIDs, relatedProduct.ID)
+		}
+
+		bestsellerIDs, err := uc.ProductService.GetCategoryBestsellerIDs(ctx, product.Category_ID, excludedProductIDs, param.Limit-len(relatedProducts))
+		if err != nil {
+			return nil, err
+		}
+
+		bestsellers, err := uc.purchasableProducts(ctx, bestsellerIDs, param.Limit-len(relatedProducts), locale)
+		if err != nil {
+			return nil, err
+		}
+		relatedProducts = append(relatedProducts, bestsellers...)
+	}
+
+	if relatedProducts == nil {
+		relatedProducts = []models.Product{}
+	}
+	return relatedProducts, nil
+}
+
+// purchasableProducts loads productIDs in their order and keeps the first limit that can be bought.
+func (uc *ProductUsecase) purchasableProducts(ctx context.Context, productIDs []int64, limit int, locale string) ([]models.Product, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	products, err := uc.ProductService.GetLocalizedProductsByIDs(ctx, productIDs, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	var purchasableProducts []models.Product
+	for _, product := range products {
+		if len(purchasableProducts) == limit {
+			break
+		}
+
+		if product.IsPurchasable() {
+			purchasableProducts = append(purchasableProducts, product)
+		}
+	}
+	return purchasableProducts, nil
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrProductReviewNotFound = errors.New("product review not found")
+	ErrInvalidProductReview = errors.New("invalid product review")
+	ErrProductReviewExists = errors.New("product already reviewed")
+	ErrProductReviewNotAllowed = errors.New("product review not allowed")
+)
+
+const (
+	maxProductReviewTitleLength = 150
+	maxProductReviewBodyLength = 5000
+)
+
+var productReviewStatuses = map[string]bool{
+	models.ReviewStatusPending: true,
+	models.ReviewStatusApproved: true,
+	models.ReviewStatusRejected: true,
+}
+
+// CreateProductReview stores a pending review. Only users with a completed order containing the product
+// may review it, and only once.
+func (uc *ProductUsecase) CreateProductReview(ctx context.Context, productID int64, userID int64, param *models.ProductReviewParameter) (int64, error) {
+	productReview := &models.ProductReview{
+		ProductID: productID,
+		UserID: userID,
+		Rating: param.Rating,
+		Title: strings.TrimSpace(param.Title),
+		Body: strings.TrimSpace(param.Body),
+		Status: models.ReviewStatusPending,
+	}
+
+	switch {
+	case productReview.Rating < 1 || productReview.Rating > 5:
+		return 0, fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidProductReview)
+	case len(productReview.Title) > maxProductReviewTitleLength:
+		return 0, fmt.Errorf("%w: title must not exceed %d characters", ErrInvalidProductReview, maxProductReviewTitleLength)
+	case len(productReview.Body) > maxProductReviewBodyLength:
+		return 0, fmt.Errorf("%w: body must not exceed %d characters", ErrInvalidProductReview, maxProductReviewBodyLength)
+	}
+
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if product.ID == 0 || product.DeletedAt.Valid {
+		return 0, ErrProductNotFound
+	}
+
+	existingProductReview, err := uc.ProductService.GetProductReviewByProductIDAndUserID(ctx, productID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if existingProductReview.ID != 0 {
+		return 0, fmt.Errorf("%w: review %d", ErrProductReviewExists, existingProductReview.ID)
+	}
+
+	hasCompletedOrder, err := uc.ProductService.HasCompletedOrderWithProduct(ctx, userID, productID)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"productID": productID,
+		}).Errorf("uc.ProductService.HasCompletedOrderWithProduct got an error at %v", err)
+		return 0, err
+	}
+
+	if !hasCompletedOrder {
+		return 0, fmt.Errorf("%w: only customers with a completed order of this product can review it", ErrProductReviewNotAllowed)
+	}
+
+	productReviewID, err := uc.ProductService.CreateProductReview(ctx, productReview)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"userID": userID,
+			"productID": productID,
+		}).Errorf("uc.ProductService.CreateProductReview got an error at %v", err)
+		return 0, err
+	}
+	return productReviewID, nil
+}
+
+func (uc *ProductUsecase) GetProductReviews(ctx context.Context, param *models.ProductReviewListParameter) (*models.ProductReviewListResponse, error) {
+	if param.Status != "" && !productReviewStatuses[param.Status] {
+		return nil, fmt.Errorf("%w: status %q, use pending, approved or rejected", ErrInvalidProductReview, param.Status)
+	}
+
+	if param.Sort == "" {
+		param.Sort = models.ReviewSortNewest
+	}
+	if param.Sort != models.ReviewSortNewest && param.Sort != models.ReviewSortHelpful {
+		return nil, fmt.Errorf("%w: sort %q, use newest or helpful", ErrInvalidProductReview, param.Sort)
+	}
+
+	if param.Page < 1 {
+		param.Page = 1
+	}
+	if param.Limit < 1 {
+		param.Limit = defaultProductListLimit
+	}
+	if param.Limit > maxProductListLimit {
+		param.Limit = maxProductListLimit
+	}
+
+	productReviews, err := uc.ProductService.GetProductReviews(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	return productReviews, nil
+}
+
+func (uc *ProductUsecase) UpdateProductReviewStatus(ctx context.Context, productReviewID int64, status string) (*models.ProductReview, error) {
+	if !productReviewStatuses[status] {
+		return nil, fmt.Errorf("%w: status %q, use pending, approved or rejected", ErrInvalidProductReview, status)
+	}
+
+	productReview, err := uc.ProductService.GetProductReviewByID(ctx, productReviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if productReview.ID == 0 {
+		return nil, ErrProductReviewNotFound
+	}
+
+	if productReview.Status == status {
+		return productReview, nil
+	}
+
+	if err = uc.ProductService.UpdateProductReviewStatus(ctx, productReview, status); err != nil {
+		return nil, err
+	}
+
+	productReview.Status = status
+	return productReview, nil
+}
+
+func (uc *ProductUsecase) SetProductReviewVote(ctx context.Context, productReviewID int64, userID int64, helpful bool) error {
+	productReview, err := uc.ProductService.GetProductReviewByID(ctx, productReviewID)
+	if err != nil {
+		return err
+	}
+
+	// hidden reviews cannot be voted on, so they look the same as missing ones
+	if productReview.ID == 0 || productReview.Status != models.ReviewStatusApproved {
+		return ErrProductReviewNotFound
+	}
+
+	if productReview.UserID == userID {
+		return fmt.Errorf("%w: you cannot vote on your own review", ErrInvalidProductReview)
+	}
+
+	if err = uc.ProductService.SetProductReviewVote(ctx, productReviewID, userID, helpful); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/PorcoGalliard/eCommerce-Microservice/utils"
+)
+
+// productSitemapLimit is the most URLs the sitemap protocol allows in one file.
+const productSitemapLimit = 50000
+
+// GetProductBySlug returns the product using slug with its text in locale. For a slug the product used
+// before it returns the current slug instead, so the caller can redirect.
+func (uc *ProductUsecase) GetProductBySlug(ctx context.Context, slug string, locale string) (*models.Product, string, error) {
+	product, err := uc.ProductService.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if product.ID != 0 {
+		if product.DeletedAt.Valid {
+			return nil, "", ErrProductNotFound
+		}
+
+		product, err = uc.ProductService.GetLocalizedProductByID(ctx, product.ID, locale)
+		if err != nil {
+			return nil, "", err
+		}
+		return product, "", nil
+	}
+
+	slugRedirect, err := uc.ProductService.GetSlugRedirect(ctx, models.SlugEntityProduct, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if slugRedirect.ID == 0 {
+		return nil, "", ErrProductNotFound
+	}
+
+	product, err = uc.ProductService.GetProductByID(ctx, slugRedirect.EntityID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if product.ID == 0 || product.DeletedAt.Valid || product.Slug == "" {
+		return nil, "", ErrProductNotFound
+	}
+	return nil, product.Slug, nil
+}
+
+// GetProductCategoryBySlug works like GetProductBySlug for categories.
+func (uc *ProductUsecase) GetProductCategoryBySlug(ctx context.Context, slug string, locale string) (*models.ProductCategory, string, error) {
+	productCategory, err := uc.ProductService.GetProductCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if productCategory.ID != 0 {
+		productCategory, err = uc.ProductService.GetLocalizedProductCategoryByID(ctx, productCategory.ID, locale)
+		if err != nil {
+			return nil, "", err
+		}
+		return productCategory, "", nil
+	}
+
+	slugRedirect, err := uc.ProductService.GetSlugRedirect(ctx, models.SlugEntityCategory, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if slugRedirect.ID == 0 {
+		return nil, "", ErrProductCategoryNotFound
+	}
+
+	productCategory, err = uc.ProductService.GetProductCategoryByID(ctx, int(slugRedirect.EntityID))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if productCategory.ID == 0 {
+		return nil, "", ErrProductCategoryNotFound
+	}
+	return nil, productCategory.Slug, nil
+}
+
+// GetProductSitemap returns page, counting from 1, of the published product slugs and the number of pages.
+// Page 0 returns the only page, or no products when there is more than one page and an index is needed.
+func (uc *ProductUsecase) GetProductSitemap(ctx context.Context, page int) ([]models.Product, int, error) {
+	total, err := uc.ProductService.CountPublishedProductSlugs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pages := int((total + productSitemapLimit - 1) / productSitemapLimit)
+	if pages == 0 {
+		pages = 1
+	}
+
+	if page == 0 {
+		if pages > 1 {
+			return nil, pages, nil
+		}
+		page = 1
+	}
+
+	if page < 1 || page > pages {
+		return nil, pages, fmt.Errorf("%w: sitemap page %d does not exist", ErrProductNotFound, page)
+	}
+
+	products, err := uc.ProductService.GetPublishedProductSlugs(ctx, (page-1)*productSitemapLimit, productSitemapLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, pages, nil
+}
+
+// prepareProductSlug normalizes the slug sent with the product. Without one the product keeps currentSlug,
+// or gets one generated from its name when it has none yet.
+func (uc *ProductUsecase) prepareProductSlug(ctx context.Context, product *models.Product, currentSlug string) error {
+	if product.Slug == "" && currentSlug != "" {
+		product.Slug = currentSlug
+		return nil
+	}
+
+	generated := product.Slug == ""
+	baseSlug := utils.Slugify(product.Slug)
+	if generated {
+		baseSlug = utils.Slugify(product.Name)
+	}
+
+	slug, err := uc.uniqueProductSlug(ctx, baseSlug, product.ID, generated, nil)
+	if err != nil {
+		return err
+	}
+	product.Slug = slug
+	return nil
+}
+
+// uniqueProductSlug adds a -2, -3 ... suffix to a generated slug until no other product and nothing in
+// reserved uses it. A slug picked by the client must be free as it is.
+func (uc *ProductUsecase) uniqueProductSlug(ctx context.Context, baseSlug string, productID int64, generated bool, reserved map[string]bool) (string, error) {
+	if baseSlug == "" {
+		return "", fmt.Errorf("%w: slug must contain letters or digits", ErrInvalidProduct)
+	}
+
+	slug := baseSlug
+	for suffix := 2; ; suffix++ {
+		if !reserved[slug] {
+			existing, err := uc.ProductService.GetProductBySlug(ctx, slug)
+			if err != nil {
+				return "", err
+			}
+
+			if existing.ID == 0 || existing.ID == productID {
+				return slug, nil
+			}
+		}
+
+		if !generated {
+			return "", fmt.Errorf("%w: slug %s is already used", ErrInvalidProduct, slug)
+		}
+		slug = fmt.Sprintf("%s-%d", baseSlug, suffix)
+	}
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidProductStatus = errors.New("invalid product status")
+
+var productStatuses = map[string]bool{
+	models.ProductStatusDraft: true,
+	models.ProductStatusPublished: true,
+	models.ProductStatusArchived: true,
+}
+
+func (uc *ProductUsecase) UpdateProductStatus(ctx context.Context, productID int64, status string) (*models.Product, error) {
+	if !productStatuses[status] {
+		return nil, fmt.Errorf("%w: %q, use draft, published or archived", ErrInvalidProductStatus, status)
+	}
+
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	if product.DeletedAt.Valid {
+		return nil, fmt.Errorf("%w: product %d is deleted, restore it first", ErrInvalidProductStatus, productID)
+	}
+
+	if product.Status == status {
+		return product, nil
+	}
+
+	if err = uc.ProductService.UpdateProductStatus(ctx, productID, status); err != nil {
+		return nil, err
+	}
+
+	product.Status = status
+	return product, nil
+}
+
+// RestoreProduct brings a deleted product back as a draft so staff can review it before publishing again.
+func (uc *ProductUsecase) RestoreProduct(ctx context.Context, productID int64) (*models.Product, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	if !product.DeletedAt.Valid {
+		return nil, fmt.Errorf("%w: product %d is not deleted", ErrInvalidProductStatus, productID)
+	}
+
+	if err = uc.ProductService.RestoreProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	product.Status = models.ProductStatusDraft
+	product.DeletedAt = gorm.DeletedAt{}
+	return product, nil
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidProductStockEvent = models.ErrInvalidProductStockEvent
+	ErrProductInStock = errors.New("product is in stock")
+	ErrProductStockSubscriptionNotFound = errors.New("product stock subscription not found")
+)
+
+// ApplyStockUpdate takes the ordered quantities out of stock once per order.
+func (uc *ProductUsecase) ApplyStockUpdate(ctx context.Context, event *models.ProductStockUpdateEvent) error {
+	return uc.applyStockEvent(ctx, models.TopicStockUpdate, event, -1)
+}
+
+// RollbackStock puts the quantities of a cancelled order back, once per order.
+func (uc *ProductUsecase) RollbackStock(ctx context.Context, event *models.ProductStockUpdateEvent) error {
+	return uc.applyStockEvent(ctx, models.TopicStockRollback, event, 1)
+}
+
+func (uc *ProductUsecase) applyStockEvent(ctx context.Context, topic string, event *models.ProductStockUpdateEvent, delta int) error {
+	if event.OrderID == 0 {
+		return fmt.Errorf("%w: order_id is required", ErrInvalidProductStockEvent)
+	}
+
+	for _, item := range event.Products {
+		if item.ProductID == 0 || item.Qty <= 0 {
+			return fmt.Errorf("%w: order %d has an item without product_id or a positive qty", ErrInvalidProductStockEvent, event.OrderID)
+		}
+	}
+
+	applied, err := uc.ProductService.ApplyStockEvent(ctx, topic, event, delta)
+	if err != nil {
+		return err
+	}
+
+	if !applied {
+		log.Logger.WithFields(logrus.Fields{
+			"topic": topic,
+			"orderID": event.OrderID,
+		}).Info("Stock event already applied, skipping")
+	}
+	return nil
+}
+
+// SubscribeProductStock only accepts products that can be bought but are sold out right now.
+func (uc *ProductUsecase) SubscribeProductStock(ctx context.Context, productID int64, userID int64) error {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if product.ID == 0 || !product.IsPurchasable() {
+		return ErrProductNotFound
+	}
+
+	if isProductInStock(product) {
+		return ErrProductInStock
+	}
+
+	if err = uc.ProductService.SubscribeProductStock(ctx, productID, userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *ProductUsecase) UnsubscribeProductStock(ctx context.Context, productID int64, userID int64) error {
+	deleted, err := uc.ProductService.UnsubscribeProductStock(ctx, productID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrProductStockSubscriptionNotFound
+	}
+	return nil
+}
+
+// isProductInStock looks at the variants when the product has any, their stock is what gets sold.
+func isProductInStock(product *models.Product) bool {
+	if len(product.Variants) == 0 {
+		return product.Stock > 0
+	}
+
+	for _, productVariant := range product.Variants {
+		if productVariant.Stock > 0 {
+			return true
+		}
+	}
+	return false
+}
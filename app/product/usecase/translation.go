@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+var (
+	ErrTranslationNotFound = errors.New("translation not found")
+	ErrInvalidTranslation = errors.New("invalid translation")
+)
+
+// SaveProductTranslation adds or replaces the text of a product in one locale. The DefaultLocale text
+// lives on the product itself and is edited there.
+func (uc *ProductUsecase) SaveProductTranslation(ctx context.Context, productTranslation *models.ProductTranslation) error {
+	if err := validateTranslationLocale(productTranslation.Locale); err != nil {
+		return err
+	}
+
+	productTranslation.Name = strings.TrimSpace(productTranslation.Name)
+	if productTranslation.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTranslation)
+	}
+
+	product, err := uc.ProductService.GetProductByID(ctx, productTranslation.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if product.ID == 0 || product.DeletedAt.Valid {
+		return ErrProductNotFound
+	}
+	return uc.ProductService.SaveProductTranslation(ctx, productTranslation)
+}
+
+func (uc *ProductUsecase) DeleteProductTranslation(ctx context.Context, productID int64, locale string) error {
+	if err := validateTranslationLocale(locale); err != nil {
+		return err
+	}
+
+	deleted, err := uc.ProductService.DeleteProductTranslation(ctx, productID, locale)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrTranslationNotFound
+	}
+	return nil
+}
+
+func (uc *ProductUsecase) SaveProductCategoryTranslation(ctx context.Context, productCategoryTranslation *models.ProductCategoryTranslation) error {
+	if err := validateTranslationLocale(productCategoryTranslation.Locale); err != nil {
+		return err
+	}
+
+	productCategoryTranslation.Name = strings.TrimSpace(productCategoryTranslation.Name)
+	if productCategoryTranslation.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTranslation)
+	}
+
+	productCategory, err := uc.ProductService.GetProductCategoryByID(ctx, productCategoryTranslation.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	if productCategory.ID == 0 {
+		return ErrProductCategoryNotFound
+	}
+	return uc.ProductService.SaveProductCategoryTranslation(ctx, productCategoryTranslation)
+}
+
+func (uc *ProductUsecase) DeleteProductCategoryTranslation(ctx context.Context, productCategoryID int, locale string) error {
+	if err := validateTranslationLocale(locale); err != nil {
+		return err
+	}
+
+	deleted, err := uc.ProductService.DeleteProductCategoryTranslation(ctx, productCategoryID, locale)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrTranslationNotFound
+	}
+	return nil
+}
+
+func validateTranslationLocale(locale string) error {
+	if !models.IsSupportedLocale(locale) {
+		return fmt.Errorf("%w: locale %q is not supported", ErrInvalidTranslation, locale)
+	}
+
+	if locale == models.DefaultLocale {
+		return fmt.Errorf("%w: %s is the default locale, edit the text on the entity itself", ErrInvalidTranslation, locale)
+	}
+	return nil
+}
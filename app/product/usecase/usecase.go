@@ -2,11 +2,27 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/PorcoGalliard/eCommerce-Microservice/app/product/service"
 	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
 	"github.com/PorcoGalliard/eCommerce-Microservice/models"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrInvalidProduct = errors.New("invalid product")
+	ErrProductVariantNotFound = errors.New("product variant not found")
+	ErrInvalidProductVariant = errors.New("invalid product variant")
+)
+
+const (
+	defaultProductListLimit = 20
+	maxProductListLimit = 100
 )
 
 type ProductUsecase struct {
@@ -19,16 +35,16 @@ func NewProductUsecase(productService *service.ProductService) *ProductUsecase {
 	}
 }
 
-func (uc *ProductUsecase) GetProductByID(ctx context.Context, productID int64) (*models.Product, error) {
-	product, err := uc.ProductService.GetProductByID(ctx, productID)
+func (uc *ProductUsecase) GetProductByID(ctx context.Context, productID int64, locale string) (*models.Product, error) {
+	product, err := uc.ProductService.GetLocalizedProductByID(ctx, productID, locale)
 	if err != nil {
 		return nil, err
 	}
 	return product, nil
 }
 
-func (uc *ProductUsecase) GetProductCategoryByID(ctx context.Context, productCategoryID int) (*models.ProductCategory, error) {
-	productCategory, err := uc.ProductService.GetProductCategoryByID(ctx, productCategoryID)
+func (uc *ProductUsecase) GetProductCategoryByID(ctx context.Context, productCategoryID int, locale string) (*models.ProductCategory, error) {
+	productCategory, err := uc.ProductService.GetLocalizedProductCategoryByID(ctx, productCategoryID, locale)
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +52,25 @@ func (uc *ProductUsecase) GetProductCategoryByID(ctx context.Context, productCat
 }
 
 func (uc *ProductUsecase) CreateNewProduct(ctx context.Context, product *models.Product) (int64, error) {
+	if product.Status == "" {
+		product.Status = models.ProductStatusDraft
+	}
+
+	if !productStatuses[product.Status] {
+		return 0, fmt.Errorf("%w: %q, use draft, published or archived", ErrInvalidProductStatus, product.Status)
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	product.RatingAvg = 0
+	product.RatingCount = 0
+
+	if err := uc.validateProduct(ctx, product); err != nil {
+		return 0, err
+	}
+
+	if err := uc.prepareProductSlug(ctx, product, ""); err != nil {
+		return 0, err
+	}
+
 	productID, err := uc.ProductService.CreateNewProduct(ctx, product)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
@@ -48,6 +83,10 @@ func (uc *ProductUsecase) CreateNewProduct(ctx context.Context, product *models.
 }
 
 func (uc *ProductUsecase) CreateNewProductCategory(ctx context.Context, productCategory *models.ProductCategory) (int, error) {
+	if err := uc.prepareProductCategory(ctx, productCategory); err != nil {
+		return 0, err
+	}
+
 	productCategoryID, err := uc.ProductService.CreateNewProductCategory(ctx, productCategory)
 	if err != nil {
 		log.Logger.WithFields(logrus.Fields{
@@ -58,8 +97,75 @@ func (uc *ProductUsecase) CreateNewProductCategory(ctx context.Context, productC
 	return productCategoryID, nil
 }
 
-func (uc *ProductUsecase) UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
-	updatedProduct, err := uc.ProductService.UpdateProduct(ctx, product)
+// ReplaceProduct replaces the editable fields of a product with body. Stock is only taken from body when
+// body sends it, stock moves through orders and warehouses and a stale copy would overwrite their work.
+func (uc *ProductUsecase) ReplaceProduct(ctx context.Context, productID int64, body []byte) (*models.Product, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	_, stockSent := fields["stock"]
+
+	return uc.updateProduct(ctx, productID, func(product *models.Product) error {
+		var replacement models.Product
+		if err := json.Unmarshal(body, &replacement); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+
+		if !stockSent {
+			replacement.Stock = product.Stock
+		}
+		if replacement.Type == "" {
+			replacement.Type = product.Type
+		}
+		*product = replacement
+		return nil
+	})
+}
+
+// updateProduct runs apply on the stored row, locked until the edit is written, and checks the result.
+func (uc *ProductUsecase) updateProduct(ctx context.Context, productID int64, apply func(product *models.Product) error) (*models.Product, error) {
+	existingProduct, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingProduct.ID == 0 || existingProduct.DeletedAt.Valid {
+		return nil, ErrProductNotFound
+	}
+
+	updatedProduct, err := uc.ProductService.UpdateProduct(ctx, productID, func(product *models.Product) error {
+		stored := *product
+		if stored.DeletedAt.Valid {
+			return ErrProductNotFound
+		}
+
+		if err := apply(product); err != nil {
+			return err
+		}
+		product.ID = productID
+
+		// the lifecycle only moves through UpdateProductStatus, DeleteProduct and RestoreProduct
+		product.Status = stored.Status
+		product.DeletedAt = stored.DeletedAt
+		product.RatingAvg = stored.RatingAvg
+		product.RatingCount = stored.RatingCount
+
+		if product.Type == models.ProductTypeBundle {
+			if len(existingProduct.Variants) != 0 {
+				return fmt.Errorf("%w: product %d has variants and can not become a bundle", ErrInvalidProduct, productID)
+			}
+
+			if product.BundleItems == nil {
+				product.BundleItems = existingProduct.BundleItems
+			}
+		}
+
+		if err := uc.validateProduct(ctx, product); err != nil {
+			return err
+		}
+		return uc.prepareProductSlug(ctx, product, stored.Slug)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +173,10 @@ func (uc *ProductUsecase) UpdateProduct(ctx context.Context, product *models.Pro
 }
 
 func (uc *ProductUsecase) UpdateProductCategory(ctx context.Context, productCategory *models.ProductCategory) (*models.ProductCategory, error) {
+	if err := uc.prepareProductCategory(ctx, productCategory); err != nil {
+		return nil, err
+	}
+
 	updatedProductCategory, err := uc.ProductService.UpdateProductCategory(ctx, productCategory)
 	if err != nil {
 		return nil, err
@@ -74,16 +184,344 @@ func (uc *ProductUsecase) UpdateProductCategory(ctx context.Context, productCate
 	return updatedProductCategory, nil
 }
 
+// RenameProductCategory backs the legacy edit action, which only ever knew the name of a category,
+// so the columns added since are left as they are.
+func (uc *ProductUsecase) RenameProductCategory(ctx context.Context, productCategoryID int, name string) (*models.ProductCategory, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidProductCategory)
+	}
+
+	productCategory, err := uc.ProductService.GetProductCategoryByID(ctx, productCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if productCategory.ID == 0 {
+		return nil, ErrProductCategoryNotFound
+	}
+
+	if err = uc.ProductService.RenameProductCategory(ctx, productCategoryID, name); err != nil {
+		return nil, err
+	}
+	productCategory.Name = name
+	return productCategory, nil
+}
+
 func (uc *ProductUsecase) DeleteProduct(ctx context.Context, productID int64) error {
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if product.ID == 0 || product.DeletedAt.Valid {
+		return ErrProductNotFound
+	}
+
 	if err := uc.ProductService.DeleteProduct(ctx, productID); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (uc *ProductUsecase) DeleteProductCategory(ctx context.Context, productCategoryID int) error {
-	if err := uc.ProductService.DeleteProductCategory(ctx, productCategoryID); err != nil {
+func (uc *ProductUsecase) DeleteProductCategory(ctx context.Context, productCategoryID int, reassignTo int) error {
+	if err := uc.checkProductCategoryDeletable(ctx, productCategoryID, reassignTo); err != nil {
+		return err
+	}
+
+	if err := uc.ProductService.DeleteProductCategory(ctx, productCategoryID, reassignTo); err != nil {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (uc *ProductUsecase) GetProducts(ctx context.Context, param *models.ProductListParameter) (*models.ProductListResponse, error) {
+	if param.Page < 1 {
+		param.Page = 1
+	}
+	if param.Limit < 1 {
+		param.Limit = defaultProductListLimit
+	}
+	if param.Limit > maxProductListLimit {
+		param.Limit = maxProductListLimit
+	}
+
+	if param.CategoryID != 0 {
+		categoryIDs, err := uc.productCategoryWithDescendantIDs(ctx, int(param.CategoryID))
+		if err != nil {
+			return nil, err
+		}
+		param.CategoryIDs = categoryIDs
+	}
+
+	facetAttributes, err := uc.prepareProductFilters(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := uc.ProductService.GetProducts(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	products.Facets, err = uc.ProductService.GetProductFacets(ctx, param, facetAttributes)
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (uc *ProductUsecase) CreateNewProductVariant(ctx context.Context, productVariant *models.ProductVariant) (int64, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, productVariant.ProductID)
+	if err != nil {
+		return 0, err
+	}
+
+	if product.ID == 0 {
+		return 0, ErrProductNotFound
+	}
+
+	if err = validateProductVariant(product, productVariant); err != nil {
+		return 0, err
+	}
+
+	productVariantID, err := uc.ProductService.CreateNewProductVariant(ctx, productVariant)
+	if err != nil {
+		log.Logger.WithFields(logrus.Fields{
+			"productID": productVariant.ProductID,
+			"sku": productVariant.SKU,
+		}).Errorf("uc.ProductService.CreateNewProductVariant got an error at %v", err)
+		return 0, err
+	}
+	return productVariantID, nil
+}
+
+// ReplaceProductVariant replaces the variant with body. The stored stock is kept unless body sends "stock",
+// so a replacement that only meant to reprice or rename does not undo what orders took meanwhile.
+func (uc *ProductUsecase) ReplaceProductVariant(ctx context.Context, productVariantID int64, body []byte) (*models.ProductVariant, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProductVariant, err)
+	}
+	_, stockSent := fields["stock"]
+
+	return uc.updateProductVariant(ctx, productVariantID, func(productVariant *models.ProductVariant) error {
+		var replacement models.ProductVariant
+		if err := json.Unmarshal(body, &replacement); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProductVariant, err)
+		}
+
+		if !stockSent {
+			replacement.Stock = productVariant.Stock
+		}
+		*productVariant = replacement
+		return nil
+	})
+}
+
+// updateProductVariant runs apply on the stored row, locked until the edit is written, and checks the result.
+func (uc *ProductUsecase) updateProductVariant(ctx context.Context, productVariantID int64, apply func(productVariant *models.ProductVariant) error) (*models.ProductVariant, error) {
+	existingProductVariant, err := uc.ProductService.GetProductVariantByID(ctx, productVariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingProductVariant.ID == 0 {
+		return nil, ErrProductVariantNotFound
+	}
+
+	// a variant never moves to another product
+	productID := existingProductVariant.ProductID
+
+	product, err := uc.ProductService.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProductVariant, err := uc.ProductService.UpdateProductVariant(ctx, productID, productVariantID, func(productVariant *models.ProductVariant) error {
+		if err := apply(productVariant); err != nil {
+			return err
+		}
+		productVariant.ID = productVariantID
+		productVariant.ProductID = productID
+
+		return validateProductVariant(product, productVariant)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductVariantNotFound
+		}
+		return nil, err
+	}
+	return updatedProductVariant, nil
+}
+
+func (uc *ProductUsecase) DeleteProductVariant(ctx context.Context, productVariantID int64) error {
+	productVariant, err := uc.ProductService.GetProductVariantByID(ctx, productVariantID)
+	if err != nil {
+		return err
+	}
+
+	if productVariant.ID == 0 {
+		return ErrProductVariantNotFound
+	}
+
+	if err = uc.ProductService.DeleteProductVariant(ctx, productVariant); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PatchProduct applies a partial update: only the fields present in patch are changed, the way
+// json.Unmarshal leaves absent fields of an existing struct alone.
+func (uc *ProductUsecase) PatchProduct(ctx context.Context, productID int64, patch []byte) (*models.Product, error) {
+	return uc.updateProduct(ctx, productID, func(product *models.Product) error {
+		// nil options keep the current axes unless the patch sends its own
+		product.Options = nil
+		if err := json.Unmarshal(patch, product); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+		return nil
+	})
+}
+
+func (uc *ProductUsecase) PatchProductCategory(ctx context.Context, productCategoryID int, patch []byte) (*models.ProductCategory, error) {
+	productCategory, err := uc.ProductService.GetProductCategoryByID(ctx, productCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if productCategory.ID == 0 {
+		return nil, ErrProductCategoryNotFound
+	}
+
+	if err = json.Unmarshal(patch, productCategory); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProductCategory, err)
+	}
+	productCategory.ID = productCategoryID
+	productCategory.Children = nil
+
+	return uc.UpdateProductCategory(ctx, productCategory)
+}
+
+// GetProductVariantOfProduct reports a variant of another product as not found, so /v1/products/:id/variants/:variant_id
+// only reaches the variants of :id.
+func (uc *ProductUsecase) GetProductVariantOfProduct(ctx context.Context, productID int64, productVariantID int64) (*models.ProductVariant, error) {
+	productVariant, err := uc.ProductService.GetProductVariantByID(ctx, productVariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if productVariant.ID == 0 || productVariant.ProductID != productID {
+		return nil, ErrProductVariantNotFound
+	}
+	return productVariant, nil
+}
+
+// PatchProductVariant merges the options of patch into the current ones, the way json.Unmarshal fills an
+// existing map.
+func (uc *ProductUsecase) PatchProductVariant(ctx context.Context, productID int64, productVariantID int64, patch []byte) (*models.ProductVariant, error) {
+	if _, err := uc.GetProductVariantOfProduct(ctx, productID, productVariantID); err != nil {
+		return nil, err
+	}
+
+	return uc.updateProductVariant(ctx, productVariantID, func(productVariant *models.ProductVariant) error {
+		if err := json.Unmarshal(patch, productVariant); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProductVariant, err)
+		}
+		return nil
+	})
+}
+
+func (uc *ProductUsecase) validateProduct(ctx context.Context, product *models.Product) error {
+	switch {
+	case product.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidProduct)
+	case product.Price <= 0:
+		return fmt.Errorf("%w: price must be greater than 0", ErrInvalidProduct)
+	case product.Stock < 0:
+		return fmt.Errorf("%w: stock must not be negative", ErrInvalidProduct)
+	case product.LowStockThreshold < 0:
+		return fmt.Errorf("%w: low_stock_threshold must not be negative", ErrInvalidProduct)
+	}
+
+	if product.Category_ID != 0 {
+		productCategory, err := uc.ProductService.GetProductCategoryByID(ctx, int(product.Category_ID))
+		if err != nil {
+			return err
+		}
+
+		if productCategory.ID == 0 {
+			return fmt.Errorf("%w: category %d does not exist", ErrInvalidProduct, product.Category_ID)
+		}
+	}
+
+	if err := uc.prepareProductType(ctx, product); err != nil {
+		return err
+	}
+	return uc.prepareProductAttributes(ctx, product)
+}
+
+// validateProductVariant checks that the variant picks exactly one allowed value for every option axis
+// of its product and that no sibling variant already uses the same combination.
+func validateProductVariant(product *models.Product, productVariant *models.ProductVariant) error {
+	if productVariant.SKU == "" {
+		return fmt.Errorf("%w: sku is required", ErrInvalidProductVariant)
+	}
+
+	if productVariant.Stock < 0 {
+		return fmt.Errorf("%w: stock must not be negative", ErrInvalidProductVariant)
+	}
+
+	if productVariant.PriceOverride != nil && *productVariant.PriceOverride <= 0 {
+		return fmt.Errorf("%w: price_override must be greater than 0", ErrInvalidProductVariant)
+	}
+
+	if len(product.Options) == 0 {
+		return fmt.Errorf("%w: product %d has no option axes", ErrInvalidProductVariant, product.ID)
+	}
+
+	if len(productVariant.Options) != len(product.Options) {
+		return fmt.Errorf("%w: expected a value for each of the %d option axes", ErrInvalidProductVariant, len(product.Options))
+	}
+
+	for _, productOption := range product.Options {
+		value, ok := productVariant.Options[productOption.Name]
+		if !ok {
+			return fmt.Errorf("%w: missing value for option %q", ErrInvalidProductVariant, productOption.Name)
+		}
+
+		allowed := false
+		for _, allowedValue := range productOption.Values {
+			if value == allowedValue {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %q is not a valid %s", ErrInvalidProductVariant, value, productOption.Name)
+		}
+	}
+
+	for _, sibling := range product.Variants {
+		if sibling.ID == productVariant.ID {
+			continue
+		}
+
+		if sibling.SKU == productVariant.SKU {
+			return fmt.Errorf("%w: sku %s is already used by variant %d", ErrInvalidProductVariant, productVariant.SKU, sibling.ID)
+		}
+
+		sameOptions := true
+		for name, value := range productVariant.Options {
+			if sibling.Options[name] != value {
+				sameOptions = false
+				break
+			}
+		}
+		if sameOptions {
+			return fmt.Errorf("%w: variant %d already has these options", ErrInvalidProductVariant, sibling.ID)
+		}
+	}
+
+	return nil
+}
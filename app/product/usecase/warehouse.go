@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+var (
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInvalidWarehouse = errors.New("invalid warehouse")
+)
+
+func (uc *ProductUsecase) GetWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	warehouses, err := uc.ProductService.GetWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if warehouses == nil {
+		warehouses = []models.Warehouse{}
+	}
+	return warehouses, nil
+}
+
+func (uc *ProductUsecase) CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) (*models.Warehouse, error) {
+	warehouse.ID = 0
+	if err := uc.validateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	warehouseID, err := uc.ProductService.CreateWarehouse(ctx, warehouse)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse.ID = warehouseID
+	return warehouse, nil
+}
+
+func (uc *ProductUsecase) UpdateWarehouse(ctx context.Context, warehouse *models.Warehouse) (*models.Warehouse, error) {
+	existingWarehouse, err := uc.ProductService.GetWarehouseByID(ctx, warehouse.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingWarehouse.ID == 0 {
+		return nil, ErrWarehouseNotFound
+	}
+
+	if err = uc.validateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	warehouse.CreateTime = existingWarehouse.CreateTime
+	updatedWarehouse, err := uc.ProductService.UpdateWarehouse(ctx, warehouse)
+	if err != nil {
+		return nil, err
+	}
+	return updatedWarehouse, nil
+}
+
+// SetWarehouseStock sets the level of a product, or of one of its variants, in a warehouse.
+func (uc *ProductUsecase) SetWarehouseStock(ctx context.Context, warehouseID int64, param *models.WarehouseStockParameter) error {
+	if *param.Stock < 0 {
+		return fmt.Errorf("%w: stock must not be negative", ErrInvalidWarehouse)
+	}
+
+	warehouse, err := uc.ProductService.GetWarehouseByID(ctx, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	if warehouse.ID == 0 {
+		return ErrWarehouseNotFound
+	}
+
+	product, err := uc.ProductService.GetProductByID(ctx, param.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if product.ID == 0 || product.DeletedAt.Valid {
+		return ErrProductNotFound
+	}
+
+	if product.Type == models.ProductTypeBundle {
+		return fmt.Errorf("%w: product %d is a bundle, stock its components instead", ErrInvalidWarehouse, product.ID)
+	}
+
+	if len(product.Variants) != 0 && param.VariantID == 0 {
+		return fmt.Errorf("%w: product %d has variants, pick one with variant_id", ErrInvalidWarehouse, product.ID)
+	}
+
+	if param.VariantID != 0 {
+		found := false
+		for _, productVariant := range product.Variants {
+			if productVariant.ID == param.VariantID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return ErrProductVariantNotFound
+		}
+	}
+	return uc.ProductService.SetWarehouseStock(ctx, warehouseID, product, param.VariantID, *param.Stock)
+}
+
+func (uc *ProductUsecase) validateWarehouse(ctx context.Context, warehouse *models.Warehouse) error {
+	warehouse.Code = strings.ToUpper(strings.TrimSpace(warehouse.Code))
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+	if warehouse.Code == "" || warehouse.Name == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalidWarehouse)
+	}
+
+	if warehouse.Priority < 0 {
+		return fmt.Errorf("%w: priority must not be negative", ErrInvalidWarehouse)
+	}
+
+	regions := []string{}
+	for _, region := range warehouse.Regions {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	warehouse.Regions = regions
+
+	existingWarehouse, err := uc.ProductService.GetWarehouseByCode(ctx, warehouse.Code)
+	if err != nil {
+		return err
+	}
+
+	if existingWarehouse.ID != 0 && existingWarehouse.ID != warehouse.ID {
+		return fmt.Errorf("%w: code %s is already used", ErrInvalidWarehouse, warehouse.Code)
+	}
+	return nil
+}
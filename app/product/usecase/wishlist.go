@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/models"
+)
+
+// maxWishlistItems caps the wishlist of one user.
+const maxWishlistItems = 100
+
+var (
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+	ErrInvalidWishlistItem = errors.New("invalid wishlist item")
+)
+
+func (uc *ProductUsecase) GetWishlist(ctx context.Context, userID int64, locale string) ([]models.WishlistItem, error) {
+	wishlistItems, err := uc.ProductService.GetWishlistItems(ctx, userID, locale)
+	if err != nil {
+		return nil, err
+	}
+	return wishlistItems, nil
+}
+
+// AddWishlistItem saves a product that can be bought, saving it again returns the existing item.
+func (uc *ProductUsecase) AddWishlistItem(ctx context.Context, userID int64, param *models.WishlistParameter) (*models.WishlistItem, error) {
+	product, err := uc.ProductService.GetProductByID(ctx, param.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID == 0 || !product.IsPurchasable() {
+		return nil, ErrProductNotFound
+	}
+
+	if param.VariantID != 0 {
+		found := false
+		for _, productVariant := range product.Variants {
+			if productVariant.ID == param.VariantID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, ErrProductVariantNotFound
+		}
+	}
+
+	total, err := uc.ProductService.CountWishlistItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if total >= maxWishlistItems {
+		return nil, fmt.Errorf("%w: a wishlist holds at most %d items", ErrInvalidWishlistItem, maxWishlistItems)
+	}
+
+	wishlistItem := &models.WishlistItem{
+		UserID: userID,
+		ProductID: param.ProductID,
+		VariantID: param.VariantID,
+	}
+	if _, err = uc.ProductService.CreateWishlistItem(ctx, wishlistItem); err != nil {
+		return nil, err
+	}
+
+	wishlistItem.Product = product
+	return wishlistItem, nil
+}
+
+// GetWishlistItem only finds items of userID, the order service moves them into the cart.
+func (uc *ProductUsecase) GetWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) (*models.WishlistItem, error) {
+	wishlistItem, err := uc.ProductService.GetWishlistItemByID(ctx, wishlistItemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlistItem.ID == 0 {
+		return nil, ErrWishlistItemNotFound
+	}
+	return wishlistItem, nil
+}
+
+func (uc *ProductUsecase) RemoveWishlistItem(ctx context.Context, userID int64, wishlistItemID int64) error {
+	deleted, err := uc.ProductService.DeleteWishlistItem(ctx, wishlistItemID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrWishlistItemNotFound
+	}
+	return nil
+}
+
+// RecordProductView puts a product the user opened on their recently viewed list.
+func (uc *ProductUsecase) RecordProductView(ctx context.Context, userID int64, product *models.Product) error {
+	if product.ID == 0 || !product.IsPurchasable() {
+		return nil
+	}
+	return uc.ProductService.RecordProductView(ctx, userID, product.ID)
+}
+
+func (uc *ProductUsecase) GetRecentlyViewedProducts(ctx context.Context, userID int64, locale string) ([]models.Product, error) {
+	products, err := uc.ProductService.GetRecentlyViewedProducts(ctx, userID, locale)
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
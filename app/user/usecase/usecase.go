@@ -87,6 +87,7 @@ func (uc *UserUsecase) LoginUser (ctx context.Context, params *models.LoginParam
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
+		"role": user.Role,
 		"exp": time.Now().Add(time.Hour * 1).Unix(),
 	})
 
@@ -51,6 +51,9 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 		}
 
 		ctx.Set("user_id", claims["user_id"].(float64))
+		if role, ok := claims["role"].(string); ok {
+			ctx.Set("role", role)
+		}
 		ctx.Next()
 	}
 }
\ No newline at end of file
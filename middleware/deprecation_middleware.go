@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware marks a route as deprecated and points clients at its replacement.
+func DeprecationMiddleware(successor string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+		log.Logger.Warnf("⚠️ Deprecated route %s %s called, use %s", ctx.Request.Method, ctx.FullPath(), successor)
+		ctx.Next()
+	}
+}
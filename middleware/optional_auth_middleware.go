@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OptionalAuthMiddleware sets user_id and role like AuthMiddleware when the request carries a valid token,
+// and lets anonymous requests and invalid tokens through without them.
+func OptionalAuthMiddleware(secret string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenString := strings.Split(ctx.GetHeader("Authorization"), " ")
+		if len(tokenString) != 2 {
+			ctx.Next()
+			return
+		}
+
+		token, err := jwt.Parse(tokenString[1], func(token *jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		})
+		if err != nil || !token.Valid {
+			ctx.Next()
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		if userID, ok := claims["user_id"].(float64); ok {
+			ctx.Set("user_id", userID)
+		}
+		if role, ok := claims["role"].(string); ok {
+			ctx.Set("role", role)
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+const (
+	AttributeTypeText = "text"
+	AttributeTypeNumber = "number"
+	AttributeTypeBoolean = "boolean"
+)
+
+type (
+	// ProductAttribute is defined on a category and applies to its products and to every category below it.
+	ProductAttribute struct {
+		ID int64 `json:"id"`
+		CategoryID int `json:"category_id"`
+		Code string `json:"code"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+		// Options limits a text attribute to a fixed set of values, empty allows any value
+		Options []string `json:"options" gorm:"serializer:json"`
+		Filterable bool `json:"filterable"`
+		Position int `json:"position"`
+	}
+
+	// ProductAttributeValue keeps the value as text, numbers and booleans are stored in a canonical form
+	// so equal values always group into the same facet.
+	ProductAttributeValue struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		AttributeID int64 `json:"attribute_id"`
+		Value string `json:"value"`
+	}
+
+	// ProductAttributeFilter matches products having one of Values, or for numbers a value within Min and Max.
+	ProductAttributeFilter struct {
+		Code string
+		Values []string
+		Min *float64
+		Max *float64
+	}
+
+	ProductFacet struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Values []ProductFacetValue `json:"values"`
+	}
+
+	ProductFacetValue struct {
+		Value string `json:"value"`
+		Count int64 `json:"count"`
+	}
+
+	ProductFacetCount struct {
+		Code string
+		Value string
+		Count int64
+	}
+)
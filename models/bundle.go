@@ -0,0 +1,11 @@
+package models
+
+// ProductBundleItem is one component of a bundle, Quantity units of it ship with every unit of the bundle.
+type ProductBundleItem struct {
+	ID int64 `json:"id"`
+	BundleID int64 `json:"bundle_id"`
+	ProductID int64 `json:"product_id"`
+	// VariantID is 0 for components without variants
+	VariantID int64 `json:"variant_id"`
+	Quantity int `json:"quantity"`
+}
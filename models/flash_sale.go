@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+const (
+	FlashSaleStatusScheduled = "scheduled"
+	FlashSaleStatusActive = "active"
+	FlashSaleStatusEnded = "ended"
+	FlashSaleStatusCancelled = "cancelled"
+)
+
+type (
+	// FlashSale sells Stock units of a product or variant at Price between StartTime and EndTime.
+	// While it runs the units live in a Redis counter instead of product.stock, and whatever is left
+	// goes back to product.stock when it ends.
+	FlashSale struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		// VariantID is 0 for products without variants
+		VariantID int64 `json:"variant_id"`
+		Price float64 `json:"price"`
+		Stock int `json:"stock"`
+		// PerUserLimit caps the units one user may buy over the whole sale, 0 means no limit
+		PerUserLimit int `json:"per_user_limit"`
+		// SoldQty is synced from Redis while the sale runs and final once it ended
+		SoldQty int `json:"sold_qty"`
+		StartTime time.Time `json:"start_time"`
+		EndTime time.Time `json:"end_time"`
+		Status string `json:"status" gorm:"default:scheduled"`
+		RemainingStock *int `json:"remaining_stock,omitempty" gorm:"-"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+		UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+	}
+
+	FlashSaleParameter struct {
+		ProductID int64 `json:"product_id" binding:"required"`
+		VariantID int64 `json:"variant_id"`
+		Price float64 `json:"price" binding:"required"`
+		Stock int `json:"stock" binding:"required"`
+		PerUserLimit int `json:"per_user_limit"`
+		StartTime time.Time `json:"start_time" binding:"required"`
+		EndTime time.Time `json:"end_time" binding:"required"`
+	}
+
+	FlashSaleListParameter struct {
+		// Status filters by one status, empty lists scheduled and active sales
+		Status string `form:"status"`
+	}
+
+	// FlashSaleReservation is one checkout item taken out of a flash sale at the sale price.
+	FlashSaleReservation struct {
+		ProductID int64 `json:"product_id"`
+		VariantID int64 `json:"variant_id"`
+		Quantity int `json:"quantity"`
+		FlashSaleID int64 `json:"flash_sale_id"`
+		Price float64 `json:"price"`
+	}
+)
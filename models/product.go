@@ -1,22 +1,159 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ProductStatusDraft = "draft"
+	ProductStatusPublished = "published"
+	ProductStatusArchived = "archived"
+
+	ProductPriceKindList = "list"
+	ProductPriceKindSale = "sale"
+
+	ProductTypeSimple = "simple"
+	// ProductTypeBundle is sold at its own price but holds no stock, it ships as its BundleItems
+	ProductTypeBundle = "bundle"
+)
+
 type (
 	Product struct {
 		ID int64 `json:"id"`
+		SKU string `json:"sku"`
+		Type string `json:"type" gorm:"default:simple"`
 		Name string `json:"name"`
+		// Slug is unique across products, generated from the name when empty and kept when the name changes
+		Slug string `json:"slug"`
 		Description string `json:"description"`
 		Price float64 `json:"price"`
+		// EffectivePrice is Price, or the running sale price when a sale is active
+		EffectivePrice float64 `json:"effective_price" gorm:"-"`
+		// Stock of a bundle is never stored, reads derive it from the stock of the components
 		Stock int `json:"stock"`
+		// LowStockThreshold raises product.low_stock when the product or one of its variants drops below it, 0 turns it off
+		LowStockThreshold int `json:"low_stock_threshold"`
 		Category_ID int64 `json:"category_id"`
+		Status string `json:"status" gorm:"default:draft"`
+		// RatingAvg and RatingCount only cover approved reviews and are refreshed on every moderation change
+		RatingAvg float64 `json:"rating_avg"`
+		RatingCount int `json:"rating_count"`
+		DeletedAt gorm.DeletedAt `json:"deleted_at"`
+		Options []ProductOption `json:"options,omitempty" gorm:"-"`
+		Variants []ProductVariant `json:"variants,omitempty" gorm:"-"`
+		Images []ProductImage `json:"images,omitempty" gorm:"-"`
+		// Attributes maps attribute code to value, nil on an edit leaves the stored values untouched
+		Attributes map[string]string `json:"attributes,omitempty" gorm:"-"`
+		// AttributeValues is Attributes resolved against the attribute definitions, filled in before saving
+		AttributeValues []ProductAttributeValue `json:"-" gorm:"-"`
+		Sales []ProductPrice `json:"sales,omitempty" gorm:"-"`
+		// BundleItems are the components of a bundle, nil on an edit leaves the stored components untouched
+		BundleItems []ProductBundleItem `json:"bundle_items,omitempty" gorm:"-"`
+		// Warehouses breaks Stock and the stock of the variants down by warehouse, product detail reads fill it
+		Warehouses []WarehouseAvailability `json:"warehouses,omitempty" gorm:"-"`
+		// Locale is the locale Name and Description are in, set on reads
+		Locale string `json:"locale,omitempty" gorm:"-"`
+	}
+
+	// ProductPrice is one entry of the price history. List prices form a timeline without gaps,
+	// sale prices are time boxed and win over the list price while they run.
+	ProductPrice struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		Kind string `json:"kind"`
+		Price float64 `json:"price"`
+		EffectiveFrom time.Time `json:"effective_from"`
+		EffectiveTo *time.Time `json:"effective_to"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	ProductAvailabilityItem struct {
+		ProductID int64 `json:"product_id"`
+		// VariantID is 0 for products without variants
+		VariantID int64 `json:"variant_id"`
+		Quantity int `json:"quantity"`
+	}
+
+	ProductAvailability struct {
+		ProductAvailabilityItem
+		Name string `json:"name"`
+		UnitPrice float64 `json:"unit_price"`
+		AvailableStock int `json:"available_stock"`
+		Available bool `json:"available"`
+		Reason string `json:"reason,omitempty"`
+	}
+
+	ProductPriceScheduleParameter struct {
+		Kind string `json:"kind" binding:"required"`
+		Price float64 `json:"price" binding:"required"`
+		EffectiveFrom time.Time `json:"effective_from" binding:"required"`
+		EffectiveTo *time.Time `json:"effective_to"`
+	}
+
+	ProductPriceAtResponse struct {
+		ProductID int64 `json:"product_id"`
+		At time.Time `json:"at"`
+		ListPrice float64 `json:"list_price"`
+		SalePrice *float64 `json:"sale_price"`
+		EffectivePrice float64 `json:"effective_price"`
+	}
+
+	ProductImage struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		Position int `json:"position"`
+		ContentType string `json:"content_type"`
+		Size int64 `json:"size"`
+		ObjectKey string `json:"-"`
+		ThumbnailKeys map[string]string `json:"-" gorm:"serializer:json"`
+		URL string `json:"url" gorm:"-"`
+		Thumbnails map[string]string `json:"thumbnails" gorm:"-"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	ProductStatusParameter struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	ReorderProductImagesParameter struct {
+		ImageIDs []int64 `json:"image_ids" binding:"required"`
+	}
+
+	ProductOption struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		Name string `json:"name"`
+		Values []string `json:"values" gorm:"serializer:json"`
+		Position int `json:"position"`
+	}
+
+	ProductVariant struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		SKU string `json:"sku"`
+		Options map[string]string `json:"options" gorm:"serializer:json"`
+		PriceOverride *float64 `json:"price_override"`
+		Stock int `json:"stock"`
 	}
 
 	ProductCategory struct {
 		ID int `json:"id"`
+		ParentID *int `json:"parent_id"`
 		Name string `json:"name"`
+		Slug string `json:"slug"`
+		SortOrder int `json:"sort_order"`
+		IsActive bool `json:"is_active"`
+		// Locale is the locale Name is in, set on reads
+		Locale string `json:"locale,omitempty" gorm:"-"`
+		Children []ProductCategory `json:"children,omitempty" gorm:"-"`
 	}
 
 	ProductCategoryManagementParameter struct {
 		Action string `json:"action"`
+		// ReassignTo moves the products and child categories of a deleted category to another category
+		ReassignTo int `json:"reassign_to"`
 		ProductCategory
 	}
 
@@ -24,4 +161,62 @@ type (
 		Action string `json:"action"`
 		Product
 	}
-)
\ No newline at end of file
+
+	ProductVariantManagementParameter struct {
+		Action string `json:"action"`
+		ProductVariant
+	}
+
+	ProductListParameter struct {
+		CategoryID int64 `form:"category_id"`
+		// CategoryIDs holds CategoryID and all of its descendants
+		CategoryIDs []int64 `form:"-"`
+		// Q searches the name and description
+		Q string `form:"q"`
+		// RawFilters holds filter[code]=value query parameters, comma separated values match any of them
+		RawFilters map[string]string `form:"-"`
+		Filters []ProductAttributeFilter `form:"-"`
+		// Locale picks the text of the listed products, Q also searches their translations in it
+		Locale string `form:"-"`
+		Page int `form:"page"`
+		Limit int `form:"limit"`
+	}
+
+	ProductListResponse struct {
+		Products []Product `json:"products"`
+		Page int `json:"page"`
+		Limit int `json:"limit"`
+		Total int64 `json:"total"`
+		Facets []ProductFacet `json:"facets"`
+	}
+
+	ProductImportRow struct {
+		SKU string `json:"sku"`
+		Name string `json:"name"`
+		Description string `json:"description"`
+		Price float64 `json:"price"`
+		Stock int `json:"stock"`
+		Category string `json:"category"`
+	}
+
+	ProductImportError struct {
+		Row int `json:"row"`
+		SKU string `json:"sku"`
+		Message string `json:"message"`
+	}
+
+	ProductImportResult struct {
+		DryRun bool `json:"dry_run"`
+		TotalRows int `json:"total_rows"`
+		Created int `json:"created"`
+		Updated int `json:"updated"`
+		Failed int `json:"failed"`
+		Errors []ProductImportError `json:"errors"`
+	}
+)
+
+// IsPurchasable reports whether the product may be listed and checked out.
+// Archived, draft and deleted products stay readable by ID for order history only.
+func (p *Product) IsPurchasable() bool {
+	return p.Status == ProductStatusPublished && !p.DeletedAt.Valid
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	TopicProductCreated = "product.created"
+	TopicProductUpdated = "product.updated"
+	TopicProductDeleted = "product.deleted"
+
+	// ProductChangeEventVersion is bumped whenever the shape of ProductChangeEvent changes incompatibly
+	ProductChangeEventVersion = 1
+)
+
+type (
+	// ProductChangeEvent is published on product.created, product.updated and product.deleted, keyed by product ID.
+	// Delivery is at least once, consumers drop repeats by EventID.
+	ProductChangeEvent struct {
+		EventID string `json:"event_id"`
+		Version int `json:"version"`
+		Type string `json:"type"`
+		ProductID int64 `json:"product_id"`
+		// Before is nil on product.created and After is nil on product.deleted
+		Before *Product `json:"before"`
+		After *Product `json:"after"`
+		EventTime time.Time `json:"event_time"`
+	}
+
+	// ProductOutbox holds an event written in the transaction of the change it describes,
+	// the relay publishes it to Kafka after the commit.
+	ProductOutbox struct {
+		ID int64 `json:"id"`
+		Topic string `json:"topic"`
+		MessageKey string `json:"message_key"`
+		Payload string `json:"payload"`
+		Attempts int `json:"attempts"`
+		LastError string `json:"last_error"`
+		PublishTime *time.Time `json:"publish_time"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+)
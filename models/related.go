@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type (
+	// OrderBasket is the distinct products bought together in one completed order.
+	OrderBasket struct {
+		OrderID int64 `json:"order_id"`
+		ProductIDs []int64 `json:"product_ids"`
+	}
+
+	// RelatedProduct says RelatedProductID was bought together with ProductID in Score completed orders.
+	RelatedProduct struct {
+		ProductID int64 `json:"product_id"`
+		RelatedProductID int64 `json:"related_product_id"`
+		Score int `json:"score"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	// ProductSales counts the completed orders holding the product, it ranks the bestsellers.
+	ProductSales struct {
+		ProductID int64 `json:"product_id"`
+		OrderCount int `json:"order_count"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	RelatedProductParameter struct {
+		Limit int `form:"limit"`
+	}
+)
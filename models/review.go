@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+const (
+	ReviewStatusPending = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+
+	ReviewSortNewest = "newest"
+	ReviewSortHelpful = "helpful"
+)
+
+type (
+	ProductReview struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		UserID int64 `json:"user_id"`
+		Rating int `json:"rating"`
+		Title string `json:"title"`
+		Body string `json:"body"`
+		Status string `json:"status" gorm:"default:pending"`
+		HelpfulCount int `json:"helpful_count"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+		UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+	}
+
+	ProductReviewVote struct {
+		ID int64 `json:"id"`
+		ReviewID int64 `json:"review_id"`
+		UserID int64 `json:"user_id"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	ProductReviewParameter struct {
+		Rating int `json:"rating" binding:"required"`
+		Title string `json:"title"`
+		Body string `json:"body"`
+	}
+
+	ProductReviewStatusParameter struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	ProductReviewListParameter struct {
+		// ProductID is 0 when listing across all products, as the moderation queue does
+		ProductID int64 `form:"product_id"`
+		Status string `form:"status"`
+		Sort string `form:"sort"`
+		Page int `form:"page"`
+		Limit int `form:"limit"`
+	}
+
+	ProductReviewListResponse struct {
+		Reviews []ProductReview `json:"reviews"`
+		Page int `json:"page"`
+		Limit int `json:"limit"`
+		Total int64 `json:"total"`
+	}
+)
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	SlugEntityProduct = "product"
+	SlugEntityCategory = "category"
+)
+
+// SlugRedirect keeps a slug an entity used before, so links to it redirect to the current slug.
+// An entity type and slug pair points at one entity, the last one that gave the slug up.
+type SlugRedirect struct {
+	ID int64 `json:"id"`
+	EntityType string `json:"entity_type"`
+	EntityID int64 `json:"entity_id"`
+	Slug string `json:"slug"`
+	CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	TopicStockUpdate = "stock.update"
+	TopicStockRollback = "stock.rollback"
+	TopicProductLowStock = "product.low_stock"
+	TopicProductBackInStock = "product.back_in_stock"
+
+	// headers a dead letter carries next to the original key and value
+	HeaderDeadLetterError = "dead-letter-error"
+	HeaderDeadLetterTopic = "dead-letter-topic"
+	HeaderDeadLetterOffset = "dead-letter-offset"
+)
+
+// ErrInvalidProductStockEvent marks a stock event that can never be applied, the consumer parks it without retrying.
+var ErrInvalidProductStockEvent = errors.New("invalid product stock event")
+
+// DeadLetterTopic is where the messages of topic that could not be handled are parked.
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+type (
+	// ProductStockUpdateEvent is published by the order service on stock.update and stock.rollback
+	ProductStockUpdateEvent struct {
+		OrderID int64 `json:"order_id"`
+		UserID int64 `json:"user_id,omitempty"`
+		// ShippingAddress lets the sourcing rule pick the warehouse closest to the buyer, stock.update only
+		ShippingAddress string `json:"shipping_address,omitempty"`
+		Products []ProductStockItem `json:"products"`
+		EventTime time.Time `json:"event_time"`
+	}
+
+	ProductStockItem struct {
+		ProductID int64 `json:"product_id"`
+		VariantID int64 `json:"variant_id,omitempty"`
+		Qty int `json:"qty"`
+		// FlashSaleID is set when the units were reserved from a flash sale instead of product.stock
+		FlashSaleID int64 `json:"flash_sale_id,omitempty"`
+	}
+
+	// ProductStockEvent remembers which order events were applied so a redelivered message is skipped
+	ProductStockEvent struct {
+		ID int64 `json:"id"`
+		OrderID int64 `json:"order_id"`
+		Topic string `json:"topic"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	// ProductStockShortfall records an order line that took more than the stock left, the order is oversold by
+	// Shortfall units. A rollback of the order only hands back what was actually taken.
+	ProductStockShortfall struct {
+		ID int64 `json:"id"`
+		OrderID int64 `json:"order_id"`
+		ProductID int64 `json:"product_id"`
+		VariantID int64 `json:"variant_id"`
+		Qty int `json:"qty"`
+		Shortfall int `json:"shortfall"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	ProductStockAlertEvent struct {
+		ProductID int64 `json:"product_id"`
+		VariantID int64 `json:"variant_id,omitempty"`
+		SKU string `json:"sku"`
+		Name string `json:"name"`
+		Stock int `json:"stock"`
+		Threshold int `json:"threshold,omitempty"`
+		EventTime time.Time `json:"event_time"`
+	}
+
+	// ProductStockSubscription is pending until NotifyTime is set, subscribing again resets it
+	ProductStockSubscription struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		UserID int64 `json:"user_id"`
+		NotifyTime *time.Time `json:"notify_time"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+)
@@ -0,0 +1,43 @@
+package models
+
+const (
+	LocaleIndonesian = "id"
+	LocaleEnglish = "en"
+
+	// DefaultLocale is the locale of the Name and Description stored on the product and category rows,
+	// every other locale falls back to it where a translation is missing
+	DefaultLocale = LocaleIndonesian
+)
+
+// SupportedLocales lists every locale the catalog is served in.
+var SupportedLocales = []string{LocaleIndonesian, LocaleEnglish}
+
+type (
+	// ProductTranslation holds the text of a product in a locale other than DefaultLocale,
+	// a product has at most one translation per locale.
+	ProductTranslation struct {
+		ID int64 `json:"id"`
+		ProductID int64 `json:"product_id"`
+		Locale string `json:"locale"`
+		Name string `json:"name"`
+		// Description falls back to the description in DefaultLocale when empty
+		Description string `json:"description"`
+	}
+
+	ProductCategoryTranslation struct {
+		ID int64 `json:"id"`
+		CategoryID int `json:"category_id"`
+		Locale string `json:"locale"`
+		Name string `json:"name"`
+	}
+)
+
+// IsSupportedLocale reports whether locale is one of SupportedLocales.
+func IsSupportedLocale(locale string) bool {
+	for _, supportedLocale := range SupportedLocales {
+		if supportedLocale == locale {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,9 @@
 package models
 
+const (
+	RoleAdmin = "admin"
+	RoleStaff = "staff"
+)
 
 type (
 	RegisterParameter struct {
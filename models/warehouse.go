@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+const (
+	// WarehouseSourcingNearest takes an order line from a warehouse serving a region named in the shipping address
+	WarehouseSourcingNearest = "nearest"
+	// WarehouseSourcingMostStock takes an order line from the warehouse holding the most of it
+	WarehouseSourcingMostStock = "most_stock"
+)
+
+type (
+	Warehouse struct {
+		ID int64 `json:"id"`
+		Code string `json:"code"`
+		Name string `json:"name"`
+		Address string `json:"address"`
+		// Regions are the cities and provinces the warehouse ships to fastest, matched against the shipping address
+		Regions []string `json:"regions" gorm:"serializer:json"`
+		// Priority breaks ties between warehouses the sourcing rule ranks the same, lower goes first
+		Priority int `json:"priority"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	// WarehouseStock is what one warehouse holds of a product or one of its variants. Once a product has
+	// warehouse stock, product.stock and product_variant.stock are the sum over its warehouses, less the
+	// units a running flash sale holds.
+	WarehouseStock struct {
+		ID int64 `json:"id"`
+		WarehouseID int64 `json:"warehouse_id"`
+		ProductID int64 `json:"product_id"`
+		// VariantID is 0 for products without variants
+		VariantID int64 `json:"variant_id"`
+		Stock int `json:"stock"`
+		UpdateTime time.Time `json:"update_time" gorm:"autoUpdateTime"`
+	}
+
+	WarehouseStockParameter struct {
+		ProductID int64 `json:"product_id" binding:"required"`
+		VariantID int64 `json:"variant_id"`
+		Stock *int `json:"stock" binding:"required"`
+	}
+
+	// ProductStockAllocation records the warehouse an order line was taken from, a rollback puts it back there.
+	ProductStockAllocation struct {
+		ID int64 `json:"id"`
+		OrderID int64 `json:"order_id"`
+		WarehouseID int64 `json:"warehouse_id"`
+		ProductID int64 `json:"product_id"`
+		VariantID int64 `json:"variant_id"`
+		Qty int `json:"qty"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+	}
+
+	// WarehouseAvailability is the stock of a product or variant in one warehouse, shown on product reads.
+	WarehouseAvailability struct {
+		WarehouseID int64 `json:"warehouse_id"`
+		Code string `json:"code"`
+		Name string `json:"name"`
+		VariantID int64 `json:"variant_id,omitempty"`
+		Stock int `json:"stock"`
+	}
+)
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type (
+	// WishlistItem is a product, or one of its variants, a customer saved for later.
+	WishlistItem struct {
+		ID int64 `json:"id"`
+		UserID int64 `json:"user_id"`
+		ProductID int64 `json:"product_id"`
+		// VariantID is 0 when the customer saved the product rather than one of its variants
+		VariantID int64 `json:"variant_id"`
+		CreateTime time.Time `json:"create_time" gorm:"autoCreateTime"`
+		Product *Product `json:"product,omitempty" gorm:"-"`
+	}
+
+	WishlistParameter struct {
+		ProductID int64 `json:"product_id" binding:"required"`
+		VariantID int64 `json:"variant_id"`
+	}
+)
@@ -0,0 +1,7 @@
+package config
+
+type GRPCConfig struct {
+	// Port is where this service serves its own gRPC API, next to the HTTP port
+	Port string `yaml:"port" validate:"required"`
+	OrderAddress string `yaml:"order_address" validate:"required"`
+}
@@ -0,0 +1,6 @@
+package config
+
+type InventoryConfig struct {
+	// SourcingRule picks the warehouse of an order line, either "nearest" or "most_stock"
+	SourcingRule string `yaml:"sourcing_rule" validate:"required"`
+}
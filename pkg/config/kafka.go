@@ -0,0 +1,7 @@
+package config
+
+type KafkaConfig struct {
+	Brokers []string `yaml:"brokers" validate:"required"`
+	// GroupID is the consumer group shared by every instance of the service
+	GroupID string `yaml:"group_id" validate:"required"`
+}
@@ -0,0 +1,7 @@
+package config
+
+type NotifierConfig struct {
+	// Driver is either "log" or "webhook"
+	Driver string `yaml:"driver" validate:"required"`
+	WebhookURL string `yaml:"webhook_url"`
+}
@@ -0,0 +1,18 @@
+package config
+
+type StorageConfig struct {
+	// Driver is either "local" or "s3"
+	Driver string `yaml:"driver" validate:"required"`
+	PublicURL string `yaml:"public_url" validate:"required"`
+	LocalPath string `yaml:"local_path"`
+	S3 S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint string `yaml:"endpoint"`
+	Region string `yaml:"region"`
+	Bucket string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	UseSSL bool `yaml:"use_ssl"`
+}
@@ -0,0 +1,7 @@
+package config
+
+type StorefrontConfig struct {
+	// BaseURL is the public address of the storefront, product pages live at BaseURL/products/<slug>
+	// and the storefront serves BaseURL/sitemap.xml from the product service
+	BaseURL string `yaml:"base_url" validate:"required"`
+}
@@ -0,0 +1,20 @@
+package resource
+
+import (
+	"github.com/PorcoGalliard/eCommerce-Microservice/infrastructure/log"
+	"github.com/PorcoGalliard/eCommerce-Microservice/pkg/config"
+	"github.com/segmentio/kafka-go"
+)
+
+// InitKafkaWriter returns a writer without a fixed topic, every message names its own.
+// Writes wait for all in-sync replicas so a returned nil means the message is stored.
+func InitKafkaWriter(cfg config.KafkaConfig) *kafka.Writer {
+	writer := &kafka.Writer{
+		Addr: kafka.TCP(cfg.Brokers...),
+		Balancer: &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+
+	log.Logger.Printf("✅ Kafka writer ready for brokers %v", cfg.Brokers)
+	return writer
+}
@@ -1,6 +1,11 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func HashPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -18,4 +23,23 @@ func CheckPasswordHash(hash, password string) (bool, error) {
 	}
 
 	return true, nil
-}
\ No newline at end of file
+}
+
+// Slugify turns "Kaos Polos & Hoodie" into "kaos-polos-hoodie".
+func Slugify(text string) string {
+	var builder strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			pendingDash = false
+			continue
+		}
+		pendingDash = true
+	}
+
+	return builder.String()
+}